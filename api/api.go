@@ -0,0 +1,11 @@
+// Package api holds the contracts of the service: the OpenAPI document of the
+// REST routes and the protobuf definitions of the gRPC ones.
+package api
+
+import _ "embed"
+
+// OpenAPI is the document served at /api/v1/openapi.json, it is maintained by
+// hand next to the handlers and checked against them by the handler tests.
+//
+//go:embed openapi.json
+var OpenAPI []byte
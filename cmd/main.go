@@ -2,16 +2,27 @@ package main
 
 import (
 	"context"
+	"time"
+	"wallet-api/api"
 	"wallet-api/config"
+	"wallet-api/pkg/auth"
 	"wallet-api/pkg/database"
+	"wallet-api/pkg/grpcserver"
 	"wallet-api/pkg/httpserver"
 	"wallet-api/pkg/logger"
+	"wallet-api/pkg/openapi"
+	"wallet-api/pkg/outbox"
+	"wallet-api/pkg/tracing"
+	"wallet-api/pkg/webhook"
+	"wallet-api/src/database/entities"
 	"wallet-api/src/database/migrations"
 	"wallet-api/src/database/repositories"
 	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/rpc"
 	"wallet-api/src/services"
 
-	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 )
 
 type appKeyType struct{}
@@ -26,19 +37,151 @@ func main() {
 
 	connPool := database.NewConnectionPool(ctx, cfg.Database.WalletDB)
 
-	log := logger.NewLogger(zerolog.InfoLevel)
+	log := logger.New(cfg.Log)
 	migrator := migrations.NewMigrator(log, cfg.Database)
 
 	migrator.Migrate()
 
+	shutdown_tracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("tracing")
+	}
+	defer func() {
+		shutdown_ctx, shutdown_cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer shutdown_cancel()
+		if err := shutdown_tracing(shutdown_ctx); err != nil {
+			log.Error().Err(err).Msg("tracing shutdown")
+		}
+	}()
+
 	//---
 
-	wallet_repo := repositories.NewWalletRepo(connPool, log)
-	wallet_service := services.NewWalletService(wallet_repo)
-	wallet_handler := handlers.NewWalletHandler(wallet_service)
+	idempotency_repo := repositories.NewIdempotencyRepo(connPool, log)
+	idempotency := httpserver.NewIdempotency(log, idempotency_repo, cfg.Server.Idempotency, models.Max_body_bytes)
+	go idempotency.RunCleanup(ctx)
+
+	rate_limit_store := httpserver.NewMemoryRateLimitStore(time.Now)
+	if cfg.Server.RateLimit.Store == httpserver.RateLimitStorePostgres {
+		rate_limit_store = repositories.NewRateLimitRepo(connPool, log)
+	}
+	rate_limiter := httpserver.NewRateLimiter(log, rate_limit_store, cfg.Server.RateLimit)
+	wallet_rate_limit := rate_limiter.Wallet(handlers.RequestWallets)
+	go rate_limiter.RunCleanup(ctx)
+
+	wallet_repo := repositories.TraceWalletRepo(repositories.NewWalletRepo(connPool, log))
+	wallet_service := services.TraceWalletService(services.NewWalletService(wallet_repo))
+	wallet_handler := handlers.NewWalletHandler(wallet_service, wallet_rate_limit, idempotency.Middleware)
+	admin_handler := handlers.NewAdminHandler(wallet_service)
+
+	ledger_repo := repositories.NewLedgerRepo(connPool, log)
+	ledger_service := services.NewLedgerService(ledger_repo, wallet_repo)
+	ledger_handler := handlers.NewLedgerHandler(ledger_service, idempotency.Middleware)
+
+	fx_rates := services.NewStaticRateProvider(cfg.FX.Rates)
+	if cfg.FX.RatesFile != "" {
+		var err error
+		if fx_rates, err = services.NewFileRateProvider(cfg.FX.RatesFile); err != nil {
+			log.Fatal().Err(err).Msg("fx rates")
+		}
+	}
+	fx_quote_repo := repositories.NewFXQuoteRepo(connPool, log)
+	fx_service := services.NewFXService(wallet_repo, fx_quote_repo, fx_rates, cfg.FX.QuoteTTL)
+	fx_handler := handlers.NewFXHandler(fx_service)
+
+	hold_repo := repositories.NewHoldRepo(connPool, log)
+	hold_service := services.NewHoldService(hold_repo, wallet_repo, cfg.Holds.DefaultTTL, cfg.Holds.MaxTTL)
+	hold_handler := handlers.NewHoldHandler(hold_service, wallet_rate_limit, idempotency.Middleware)
+
+	hold_expiry := services.NewHoldExpiryNotifier(log, hold_repo, cfg.Holds.ExpiryCheckPeriod)
+	go hold_expiry.Run(ctx)
+
+	wallet_event_repo := repositories.NewWalletEventRepo(connPool, log)
+	wallet_event_service := services.NewWalletEventService(wallet_event_repo)
+	events_handler := handlers.NewEventsHandler(wallet_service, wallet_event_service, cfg.Events.Heartbeat, cfg.Events.Retry)
+	wallet_events_listener := database.NewListener(log, cfg.Database.WalletDB, entities.WalletEvents_channel)
+	go wallet_events_listener.Run(ctx, func(payload string) {
+		if err := wallet_event_service.Dispatch(payload); err != nil {
+			log.Error().Err(err).Msg("dispatch wallet event")
+		}
+	})
+
+	var webhook_handler handlers.WebhookHandler
+	var webhook_secrets *webhook.Secrets
+	if cfg.Webhooks.Enabled {
+		webhook_secrets, err = webhook.NewSecrets(cfg.Webhooks.SecretKey)
+		if err != nil {
+			log.Fatal().Err(err).Msg("webhook secrets")
+		}
+		webhook_repo := repositories.NewWebhookRepo(connPool, webhook_secrets, log)
+		webhook_service := services.NewWebhookService(webhook_repo, cfg.Webhooks.CheckURL)
+		webhook_handler = handlers.NewWebhookHandler(webhook_service)
+	}
+
+	outbox_store := repositories.NewOutboxRepo(connPool, log)
+	outbox_cleaner := outbox.NewCleaner(log, outbox_store, cfg.Outbox)
+	go outbox_cleaner.Run(ctx)
+
+	if cfg.Outbox.Enabled {
+		outbox_sink, err := outbox.NewSink(cfg.Outbox.Sink)
+		if err != nil {
+			log.Fatal().Err(err).Msg("outbox sink")
+		}
+		if cfg.Webhooks.Enabled {
+			webhook_store := repositories.NewWebhookDeliveryStore(connPool, webhook_secrets, log)
+			outbox_sink = outbox.MultiSink{outbox_sink, webhook.NewSink(webhook_store)}
+			webhook_worker := webhook.NewWorker(log, webhook_store, cfg.Webhooks)
+			go webhook_worker.Run(ctx)
+		}
+		outbox_relay := outbox.NewRelay(log, outbox_store, outbox_sink, cfg.Outbox)
+		go outbox_relay.Run(ctx)
+	}
+
+	openapi_doc, err := openapi.Parse(api.OpenAPI)
+	if err != nil {
+		log.Fatal().Err(err).Msg("openapi document")
+	}
+	openapi_handler := handlers.NewOpenAPIHandler(api.OpenAPI)
 
 	server := httpserver.NewServer(log, cfg.Server)
-	wallet_handler.Register(server)
+	server.Use(httpserver.RequestID, httpserver.RequestLogger, httpserver.Tracing, httpserver.Metrics, httpserver.AccessLog(log), httpserver.Recovery(log))
+	server.AddReadinessCheck("database", connPool.Ping)
+	server.AddReadinessCheck("migrations", func(ctx context.Context) error {
+		return migrations.CheckVersion(ctx, connPool)
+	})
+	openapi_handler.Register(openapi.NewRouter(openapi_doc, server, models.Max_body_bytes))
+
+	var grpc_options []grpc.ServerOption
+	api_group := server.Group("")
+	if cfg.Auth.Enabled {
+		authenticators := auth.Authenticators{auth.NewAPIKeyAuthenticator(repositories.NewAPIKeyRepo(connPool, log))}
+		if cfg.Auth.JWT.JWKSFile != "" {
+			jwt_authenticator, err := auth.LoadJWTAuthenticator(cfg.Auth.JWT)
+			if err != nil {
+				log.Fatal().Err(err).Msg("jwt authenticator")
+			}
+			authenticators = append(authenticators, jwt_authenticator)
+		}
+		authentication := httpserver.NewAuthentication(log, authenticators)
+		api_group.Use(authentication.Middleware)
+		grpc_options = grpcserver.Authentication(authenticators)
+	}
+	api_group.Use(rate_limiter.Client)
+	router := openapi.NewRouter(openapi_doc, api_group, models.Max_body_bytes)
+	wallet_handler.Register(router)
+	ledger_handler.Register(router)
+	fx_handler.Register(router)
+	hold_handler.Register(router)
+	admin_handler.Register(router)
+	if webhook_handler != nil {
+		webhook_handler.Register(router)
+	}
+	events_handler.Register(router)
+
+	if cfg.GRPC.Enabled {
+		grpc_server := grpcserver.NewServer(log, cfg.GRPC, grpc_options...)
+		rpc.NewWalletServer(wallet_service, wallet_event_service).Register(grpc_server)
+		server.Attach(grpc_server)
+	}
 
 	server.Serve(ctx)
 
@@ -6,27 +6,69 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
+	"wallet-api/pkg/auth"
 	"wallet-api/pkg/database"
+	"wallet-api/pkg/grpcserver"
 	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/outbox"
+	"wallet-api/pkg/tracing"
+	"wallet-api/pkg/webhook"
 
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	envWalletDB = "PG_WALLET"
+	envWalletDB         = "PG_WALLET"
+	envWebhookSecretKey = "WEBHOOK_SECRET_KEY"
 )
 
 type Config struct {
 	App      string                  `yaml:"app"`
 	Stack    string                  `yaml:"stack"`
+	Log      logger.Config           `yaml:"log"`
 	Database DB                      `yaml:"db"`
 	Server   httpserver.ServerConfig `yaml:"server"`
+	GRPC     grpcserver.ServerConfig `yaml:"grpc"`
+	Auth     auth.Config             `yaml:"auth"`
+	Tracing  tracing.Config          `yaml:"tracing"`
+	FX       FX                      `yaml:"fx"`
+	Holds    Holds                   `yaml:"holds"`
+	Outbox   outbox.Config           `yaml:"outbox"`
+	Webhooks webhook.Config          `yaml:"webhooks"`
+	Events   Events                  `yaml:"events"`
 }
 
 type DB struct {
 	WalletDB database.ConnectionConfig `yaml:"walletDB"`
 }
 
+// FX configures currency conversion. Rates are keyed by pair, e.g. "USD/RUB",
+// and are read from RatesFile instead when it is set.
+type FX struct {
+	QuoteTTL  time.Duration     `yaml:"quote_ttl"`
+	RatesFile string            `yaml:"rates_file"`
+	Rates     map[string]string `yaml:"rates"`
+}
+
+// Holds limits how long a hold reserves the balance when the request does
+// not set its own ttl, and at most. Expired holds are looked for every
+// ExpiryCheckPeriod to raise hold.expired.
+type Holds struct {
+	DefaultTTL        time.Duration `yaml:"default_ttl"`
+	MaxTTL            time.Duration `yaml:"max_ttl"`
+	ExpiryCheckPeriod time.Duration `yaml:"expiry_check_period"`
+}
+
+// Events configures the wallet event streams: a comment is sent every
+// Heartbeat to keep idle streams open, Retry is the reconnection delay
+// suggested to the clients.
+type Events struct {
+	Heartbeat time.Duration `yaml:"heartbeat"`
+	Retry     time.Duration `yaml:"retry"`
+}
+
 var (
 	//go:embed config.yaml
 	file    string
@@ -42,6 +84,9 @@ func GetConfig() Config {
 		log.Println("Found database in env")
 		config.Database.WalletDB.ConnectionString = envVal
 	}
+	if envVal, exist := os.LookupEnv(envWebhookSecretKey); exist && envVal != "" {
+		config.Webhooks.SecretKey = envVal
+	}
 	if err := validate(config); err != nil {
 		log.Fatal("Invalid configuration", err)
 	}
@@ -52,9 +97,36 @@ func validate(cfg Config) error {
 	switch {
 	case cfg.Database.WalletDB.ConnectionString == "":
 		return errors.New("wallet db connection string is empty")
+	case cfg.Log.Format != logger.FormatJSON && cfg.Log.Format != logger.FormatConsole:
+		return errors.New("unknown log format")
+	case cfg.Database.WalletDB.UsePGBouncer && cfg.Database.WalletDB.ListenConnectionString == "":
+		// LISTEN through pgbouncer in transaction mode never gets notifications
+		return errors.New("wallet db listen connection string is required with pgbouncer")
 	case cfg.Server.Port == 0:
 		return errors.New("server port is empty")
+	case cfg.Server.MetricsPath != "" && (cfg.Server.MetricsPort == 0 || cfg.Server.MetricsPort == cfg.Server.Port):
+		// the metrics are never served on the public api port
+		return errors.New("metrics port must be set apart from the server port")
+	case cfg.GRPC.Enabled && cfg.GRPC.Port == 0:
+		return errors.New("grpc port is empty")
+	case cfg.Server.RateLimit.Enabled && cfg.Server.RateLimit.Store != httpserver.RateLimitStoreMemory &&
+		cfg.Server.RateLimit.Store != httpserver.RateLimitStorePostgres:
+		return errors.New("unknown rate limit store")
+	case cfg.Webhooks.Enabled && !cfg.Outbox.Enabled:
+		// the relay is what turns the events into webhook deliveries
+		return errors.New("webhooks require the outbox")
+	case cfg.Webhooks.Enabled && cfg.Webhooks.SecretKey == "":
+		return errors.New("webhooks require a secret key, set " + envWebhookSecretKey)
+	case cfg.Webhooks.Enabled && !validWebhookSecretKey(cfg.Webhooks.SecretKey):
+		return webhook.ErrInvalidSecretKey
+	case cfg.Tracing.Enabled && cfg.Tracing.Exporter != tracing.ExporterOTLP && cfg.Tracing.Exporter != tracing.ExporterStdout:
+		return errors.New("unknown trace exporter")
 	default:
 		return nil
 	}
 }
+
+func validWebhookSecretKey(key string) bool {
+	_, err := webhook.NewSecrets(key)
+	return err == nil
+}
@@ -0,0 +1,4 @@
+// Package walletv1 is generated from api/proto/wallet/v1/wallet.proto.
+package walletv1
+
+//go:generate protoc -I ../../../../api/proto --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative wallet/v1/wallet.proto
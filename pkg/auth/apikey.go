@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyStore finds the principal of an active api key by its hash, it
+// returns ErrAPIKeyNotFound for unknown and revoked keys.
+type APIKeyStore interface {
+	FindAPIKey(ctx context.Context, hash string) (Principal, error)
+}
+
+type apiKeyAuthenticator struct {
+	store APIKeyStore
+}
+
+func NewAPIKeyAuthenticator(store APIKeyStore) Authenticator {
+	return &apiKeyAuthenticator{store: store}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(ctx context.Context, creds Credentials) (Principal, error) {
+	if creds.APIKey == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	principal, err := a.store.FindAPIKey(ctx, HashAPIKey(creds.APIKey))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return principal, err
+}
@@ -0,0 +1,78 @@
+// Package auth authenticates the callers of the api by static api keys or
+// JWT bearer tokens and carries the resulting principal in the context.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+)
+
+// ScopeAdmin lets the principal touch every wallet and list them all.
+const ScopeAdmin = "wallets:admin"
+
+var (
+	ErrNoCredentials      = errors.New("authentication is required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller, wallets created by it are owned by
+// its ID.
+type Principal struct {
+	ID     string
+	Scopes []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Credentials are taken from the request, a transport sets the ones it got.
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+type Authenticator interface {
+	// Authenticate returns ErrNoCredentials when the credentials it checks
+	// are not set, so the next authenticator can try.
+	Authenticate(ctx context.Context, creds Credentials) (Principal, error)
+}
+
+// Authenticators tries each authenticator in order until one finds its
+// credentials set.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context, creds Credentials) (Principal, error) {
+	for _, authenticator := range a {
+		principal, err := authenticator.Authenticate(ctx, creds)
+		if !errors.Is(err, ErrNoCredentials) {
+			return principal, err
+		}
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type principalKeyType struct{}
+
+var principalKey = principalKeyType{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// FromContext returns false for the calls made without authentication, e.g.
+// when it is disabled or by the background workers.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// HashAPIKey is the form api keys are stored in, the keys themselves are
+// known only to their holders.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
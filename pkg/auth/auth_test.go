@@ -0,0 +1,169 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+	"wallet-api/pkg/auth"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryKeyStore map[string]auth.Principal
+
+func (s memoryKeyStore) FindAPIKey(_ context.Context, hash string) (auth.Principal, error) {
+	principal, ok := s[hash]
+	if !ok {
+		return auth.Principal{}, auth.ErrAPIKeyNotFound
+	}
+	return principal, nil
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	store := memoryKeyStore{auth.HashAPIKey("secret"): {ID: "shop"}}
+	authenticator := auth.NewAPIKeyAuthenticator(store)
+	ctx := context.Background()
+
+	principal, err := authenticator.Authenticate(ctx, auth.Credentials{APIKey: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "shop", principal.ID)
+
+	_, err = authenticator.Authenticate(ctx, auth.Credentials{APIKey: "other"})
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authenticator.Authenticate(ctx, auth.Credentials{BearerToken: "token"})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+}
+
+func encode(v any) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	input := encode(map[string]string{"alg": "RS256", "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	input := encode(map[string]string{"alg": "ES256", "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := auth.JWKS{Keys: []auth.JWK{
+		{
+			Kty: "RSA",
+			Kid: "rsa",
+			N:   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			Kty: "EC",
+			Kid: "ec",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		},
+	}}
+	now := time.Unix(1_750_000_000, 0)
+	authenticator, err := auth.NewJWTAuthenticator(jwks, auth.JWTConfig{Issuer: "idp", Audience: "wallet-api", Leeway: time.Minute}, func() time.Time { return now })
+	if !assert.NoError(t, err) {
+		return
+	}
+	claims := func(changes map[string]any) map[string]any {
+		c := map[string]any{"sub": "shop", "iss": "idp", "aud": []string{"other", "wallet-api"}, "exp": now.Add(time.Hour).Unix(), "scope": "wallets:admin read"}
+		for k, v := range changes {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+
+	t.Run("rs256", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(context.Background(), auth.Credentials{BearerToken: signRS256(t, rsaKey, "rsa", claims(nil))})
+		assert.NoError(t, err)
+		assert.Equal(t, "shop", principal.ID)
+		assert.True(t, principal.HasScope(auth.ScopeAdmin))
+	})
+
+	t.Run("es256", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(context.Background(), auth.Credentials{BearerToken: signES256(t, ecKey, "ec", claims(map[string]any{"aud": "wallet-api", "scope": nil}))})
+		assert.NoError(t, err)
+		assert.Equal(t, "shop", principal.ID)
+		assert.False(t, principal.HasScope(auth.ScopeAdmin))
+	})
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"expired", signRS256(t, rsaKey, "rsa", claims(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()})), auth.ErrTokenExpired},
+		{"within leeway", signRS256(t, rsaKey, "rsa", claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})), nil},
+		{"no expiry", signRS256(t, rsaKey, "rsa", claims(map[string]any{"exp": nil})), auth.ErrTokenExpired},
+		{"not active", signRS256(t, rsaKey, "rsa", claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})), auth.ErrTokenNotActive},
+		{"issuer", signRS256(t, rsaKey, "rsa", claims(map[string]any{"iss": "evil"})), auth.ErrTokenClaims},
+		{"audience", signRS256(t, rsaKey, "rsa", claims(map[string]any{"aud": "other"})), auth.ErrTokenClaims},
+		{"unknown key", signRS256(t, rsaKey, "missing", claims(nil)), auth.ErrUnknownKey},
+		{"other signer", signRS256(t, otherKey, "rsa", claims(nil)), auth.ErrBadSignature},
+		{"algorithm of another key type", signRS256(t, rsaKey, "ec", claims(nil)), auth.ErrBadSignature},
+		{"no signature", encode(map[string]string{"alg": "none", "kid": "rsa"}) + "." + encode(claims(nil)) + ".", auth.ErrBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(context.Background(), auth.Credentials{BearerToken: tt.token})
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	authenticators := auth.Authenticators{auth.NewAPIKeyAuthenticator(memoryKeyStore{auth.HashAPIKey("secret"): {ID: "shop"}})}
+
+	_, err := authenticators.Authenticate(context.Background(), auth.Credentials{})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+
+	principal, err := authenticators.Authenticate(context.Background(), auth.Credentials{APIKey: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "shop", principal.ID)
+}
@@ -0,0 +1,22 @@
+package auth
+
+import "time"
+
+const defaultLeeway = 30 * time.Second
+
+// Config enables the authentication of the api. Api keys are always
+// accepted, bearer tokens only when JWT.JWKSFile is set.
+type Config struct {
+	Enabled bool      `yaml:"enabled"`
+	JWT     JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig checks the tokens against the keys of the local JWKSFile, Issuer
+// and Audience are not checked when empty. Leeway is allowed for the clock
+// skew of the issuer.
+type JWTConfig struct {
+	JWKSFile string        `yaml:"jwks_file"`
+	Issuer   string        `yaml:"issuer"`
+	Audience string        `yaml:"audience"`
+	Leeway   time.Duration `yaml:"leeway"`
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+)
+
+var (
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrBadSignature   = errors.New("bad token signature")
+	ErrTokenExpired   = errors.New("token is expired")
+	ErrTokenNotActive = errors.New("token is not active yet")
+	ErrTokenClaims    = errors.New("token claims do not match")
+)
+
+// JWKS is a JSON Web Key Set, only RSA and P-256 keys are read.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// audience is either a single string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]string)(a))
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*a = audience{single}
+	return nil
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+type jwtAuthenticator struct {
+	keys   map[string]crypto.PublicKey
+	config JWTConfig
+	now    func() time.Time
+}
+
+// LoadJWTAuthenticator reads the keys from cfg.JWKSFile, they are not
+// refreshed while the service runs.
+func LoadJWTAuthenticator(cfg JWTConfig) (Authenticator, error) {
+	data, err := os.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+	var jwks JWKS
+	if err = json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+	return NewJWTAuthenticator(jwks, cfg, time.Now)
+}
+
+func NewJWTAuthenticator(jwks JWKS, cfg JWTConfig, now func() time.Time) (Authenticator, error) {
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = defaultLeeway
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no keys")
+	}
+	return &jwtAuthenticator{keys: keys, config: cfg, now: now}, nil
+}
+
+func (jwk JWK) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (a *jwtAuthenticator) Authenticate(_ context.Context, creds Credentials) (Principal, error) {
+	if creds.BearerToken == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	claims, err := a.verify(creds.BearerToken)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	return Principal{ID: claims.Subject, Scopes: strings.Fields(claims.Scope)}, nil
+}
+
+// verify checks the signature first, the claims of a token with a bad
+// signature are not looked at.
+func (a *jwtAuthenticator) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, err
+	}
+	key, ok := a.keys[header.Kid]
+	if !ok {
+		return jwtClaims{}, ErrUnknownKey
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], signature) {
+		return jwtClaims{}, ErrBadSignature
+	}
+
+	var claims jwtClaims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, err
+	}
+	now := a.now()
+	switch {
+	case claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(a.config.Leeway)):
+		return jwtClaims{}, ErrTokenExpired
+	case claims.NotBefore != nil && now.Add(a.config.Leeway).Before(time.Unix(*claims.NotBefore, 0)):
+		return jwtClaims{}, ErrTokenNotActive
+	case claims.Subject == "":
+		return jwtClaims{}, fmt.Errorf("%w: no subject", ErrTokenClaims)
+	case a.config.Issuer != "" && claims.Issuer != a.config.Issuer:
+		return jwtClaims{}, fmt.Errorf("%w: issuer", ErrTokenClaims)
+	case a.config.Audience != "" && !slices.Contains(claims.Audience, a.config.Audience):
+		return jwtClaims{}, fmt.Errorf("%w: audience", ErrTokenClaims)
+	}
+	return claims, nil
+}
+
+// verifySignature accepts only the algorithm that matches the type of the
+// key, so a token can not pick a weaker one.
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return alg == algRS256 && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if alg != algES256 || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key, digest, r, s)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
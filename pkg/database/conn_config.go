@@ -2,11 +2,14 @@ package database
 
 import "time"
 
+// ConnectionConfig of a database. ListenConnectionString is used by
+// Listener, it must bypass pgbouncer in transaction mode.
 type ConnectionConfig struct {
-	ConnectionString      string        `yaml:"conn_string"`
-	ConnectionTimeout     time.Duration `yaml:"conn_timeout"`
-	UsePGBouncer          bool          `yaml:"use_pgbouncer"`
-	PoolMinConns          int32         `yaml:"pool_min_conns"`
-	PoolMaxConns          int32         `yaml:"pool_max_conns"`
-	PoolHealthCheckPeriod time.Duration `yaml:"pool_health_check_period"`
+	ConnectionString       string        `yaml:"conn_string"`
+	ListenConnectionString string        `yaml:"listen_conn_string"`
+	ConnectionTimeout      time.Duration `yaml:"conn_timeout"`
+	UsePGBouncer           bool          `yaml:"use_pgbouncer"`
+	PoolMinConns           int32         `yaml:"pool_min_conns"`
+	PoolMaxConns           int32         `yaml:"pool_max_conns"`
+	PoolHealthCheckPeriod  time.Duration `yaml:"pool_health_check_period"`
 }
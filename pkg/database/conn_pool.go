@@ -13,6 +13,8 @@ import (
 
 type ConnectionPool interface {
 	GetConnection(ctx context.Context) (Connection, error)
+	// Ping acquires a connection and checks that the database answers.
+	Ping(ctx context.Context) error
 	Close()
 }
 
@@ -34,6 +36,7 @@ func NewConnectionPool(ctx context.Context, c ConnectionConfig) ConnectionPool {
 		log.Fatal("Failed to parse connection string", err)
 	}
 	cfg.ConnConfig.ConnectTimeout = c.ConnectionTimeout
+	cfg.ConnConfig.Tracer = queryTracer{}
 	if app, ok := utils.ContextApp(ctx); ok {
 		cfg.ConnConfig.RuntimeParams["application_name"] = app
 	}
@@ -51,6 +54,7 @@ func NewConnectionPool(ctx context.Context, c ConnectionConfig) ConnectionPool {
 	if err != nil {
 		log.Fatal("Failed to create connection pool", err)
 	}
+	registerPoolMetrics(p)
 	pool := &pool{p}
 	return pool
 }
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"time"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const (
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+// Listener receives the notifications of one channel on a dedicated
+// connection, LISTEN does not survive returning a connection to the pool or
+// pgbouncer in transaction mode. The connection is opened again after a
+// failure, notifications sent while it was down are lost.
+type Listener struct {
+	logger           zerolog.Logger
+	connectionString string
+	channel          string
+}
+
+// NewListener uses ListenConnectionString of the config, ConnectionString
+// when it is empty; the config validation refuses an empty one together with
+// UsePGBouncer.
+func NewListener(log zerolog.Logger, c ConnectionConfig, channel string) *Listener {
+	connectionString := c.ListenConnectionString
+	if connectionString == "" {
+		connectionString = c.ConnectionString
+	}
+	return &Listener{
+		logger:           logger.WithModule(log, "listener").With().Str("channel", channel).Logger(),
+		connectionString: connectionString,
+		channel:          channel,
+	}
+}
+
+// Run passes the payload of every notification to handle until ctx is done.
+func (l *Listener) Run(ctx context.Context, handle func(payload string)) {
+	delay := listenRetryMin
+	for {
+		err := l.listen(ctx, handle, func() { delay = listenRetryMin })
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Error().Err(err).Dur("retry_in", delay).Msg("listen")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, listenRetryMax)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, handle func(payload string), connected func()) error {
+	cfg, err := pgx.ParseConfig(l.connectionString)
+	if err != nil {
+		return err
+	}
+	if app, ok := utils.ContextApp(ctx); ok {
+		cfg.RuntimeParams["application_name"] = app
+	}
+	conn, err := pgx.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	if _, err = conn.Exec(ctx, "listen "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+	connected()
+	l.logger.Info().Msg("listening")
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(notification.Payload)
+	}
+}
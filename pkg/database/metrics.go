@@ -0,0 +1,47 @@
+package database
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// registerPoolMetrics exposes pgxpool.Stat of the pool, it is read on every
+// scrape. Only one pool may be registered.
+func registerPoolMetrics(p *pgxpool.Pool) {
+	gauges := map[string]struct {
+		help  string
+		value func(s *pgxpool.Stat) int32
+	}{
+		"db_pool_acquired_connections":     {"Connections currently in use.", (*pgxpool.Stat).AcquiredConns},
+		"db_pool_idle_connections":         {"Idle connections in the pool.", (*pgxpool.Stat).IdleConns},
+		"db_pool_constructing_connections": {"Connections being opened.", (*pgxpool.Stat).ConstructingConns},
+		"db_pool_total_connections":        {"All connections of the pool.", (*pgxpool.Stat).TotalConns},
+		"db_pool_max_connections":          {"Maximum size of the pool.", (*pgxpool.Stat).MaxConns},
+	}
+	for name, gauge := range gauges {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: gauge.help}, func() float64 {
+			return float64(gauge.value(p.Stat()))
+		})
+	}
+	counters := map[string]struct {
+		help  string
+		value func(s *pgxpool.Stat) int64
+	}{
+		"db_pool_acquires_total":          {"Connections acquired from the pool.", (*pgxpool.Stat).AcquireCount},
+		"db_pool_canceled_acquires_total": {"Acquires canceled by their context.", (*pgxpool.Stat).CanceledAcquireCount},
+		"db_pool_empty_acquires_total":    {"Acquires that waited for a connection.", (*pgxpool.Stat).EmptyAcquireCount},
+		"db_pool_new_connections_total":   {"Connections opened by the pool.", (*pgxpool.Stat).NewConnsCount},
+	}
+	for name, counter := range counters {
+		promauto.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: counter.help}, func() float64 {
+			return float64(counter.value(p.Stat()))
+		})
+	}
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_acquire_duration_seconds_total",
+		Help: "Time spent acquiring connections.",
+	}, func() float64 {
+		return p.Stat().AcquireDuration().Seconds()
+	})
+}
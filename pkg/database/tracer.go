@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"wallet-api/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const rowsAffectedAttribute = attribute.Key("db.response.rows_affected")
+
+// queryTracer makes a client span of every statement sent on the pool, the
+// transaction statements included. The spans of a request are children of
+// its span through the context.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, operationName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(rowsAffectedAttribute.Int64(data.CommandTag.RowsAffected()))
+	}
+	tracing.End(span, data.Err)
+}
+
+// operationName is the first keyword of the statement, e.g. SELECT.
+func operationName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
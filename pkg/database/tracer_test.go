@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wallet-api/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(tracing.Config{}, exporter)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(nil)
+
+	tracer := queryTracer{}
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "\n  update wallet set balance = balance + $2 where id = $1;"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("conn closed")})
+	assert.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	assert.Equal(t, "UPDATE", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.Int64("db.response.rows_affected", 1))
+	assert.Equal(t, "SELECT", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
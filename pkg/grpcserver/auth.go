@@ -0,0 +1,63 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+	bearerPrefix          = "bearer "
+)
+
+// Authentication returns the server options checking the same credentials as
+// the http server, taken from the call metadata.
+func Authentication(authenticator auth.Authenticator) []grpc.ServerOption {
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+	}
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream)}
+}
+
+func authenticate(ctx context.Context, authenticator auth.Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var creds auth.Credentials
+	if values := md.Get(apiKeyMetadata); len(values) > 0 {
+		creds.APIKey = values[0]
+	}
+	if values := md.Get(authorizationMetadata); len(values) > 0 && len(values[0]) > len(bearerPrefix) && strings.EqualFold(values[0][:len(bearerPrefix)], bearerPrefix) {
+		creds.BearerToken = strings.TrimSpace(values[0][len(bearerPrefix):])
+	}
+	principal, err := authenticator.Authenticate(ctx, creds)
+	switch {
+	case err == nil:
+		logger.AddFields(ctx, "principal", principal.ID)
+		return auth.WithPrincipal(ctx, principal), nil
+	case errors.Is(err, auth.ErrNoCredentials):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidCredentials.Error())
+	default:
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+}
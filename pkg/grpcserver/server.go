@@ -0,0 +1,120 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"wallet-api/pkg/logger"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// Server is a gRPC server run next to the http one, see
+// httpserver.Server.Attach.
+type Server interface {
+	grpc.ServiceRegistrar
+	Run() error
+	Shutdown(ctx context.Context) error
+}
+
+type server struct {
+	logger       zerolog.Logger
+	config       ServerConfig
+	shuttingDown chan struct{}
+	shutdownOnce sync.Once
+	*grpc.Server
+}
+
+func NewServer(log zerolog.Logger, cfg ServerConfig, opts ...grpc.ServerOption) Server {
+	s := &server{
+		logger:       logger.WithModule(log, "grpc_server"),
+		config:       cfg,
+		shuttingDown: make(chan struct{}),
+	}
+	// the log fields come first, so that the authentication adds the principal
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(logFieldsUnaryInterceptor),
+		grpc.ChainStreamInterceptor(logFieldsStreamInterceptor),
+	}, opts...)
+	opts = append(opts, grpc.ChainStreamInterceptor(s.streamInterceptor))
+	s.Server = grpc.NewServer(opts...)
+	return s
+}
+
+func (s *server) Run() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.Port))
+	if err != nil {
+		return err
+	}
+	s.logger.Info().Int32("port", s.config.Port).Msg("serving grpc")
+	if err = s.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown waits for the running calls, streams are told to end through
+// ShuttingDown. Calls still running when ctx is done are cancelled.
+func (s *server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() { close(s.shuttingDown) })
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+type shutdownKeyType struct{}
+
+var shutdownKey = shutdownKeyType{}
+
+// ShuttingDown returns a channel closed when the server starts shutting down,
+// it is set for streaming calls only. Outside of the server the channel is
+// never closed.
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	done, _ := ctx.Value(shutdownKey).(<-chan struct{})
+	return done
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *server) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := context.WithValue(ss.Context(), shutdownKey, (<-chan struct{})(s.shuttingDown))
+	return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// logFields puts the method and the peer address of the call into the log
+// fields of its context, as httpserver.RequestLogger does for the requests.
+func logFields(ctx context.Context, method string) context.Context {
+	keyvals := []any{"grpc_method", method}
+	if p, ok := peer.FromContext(ctx); ok {
+		keyvals = append(keyvals, "remote_addr", p.Addr.String())
+	}
+	return logger.NewContext(ctx, keyvals...)
+}
+
+func logFieldsUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(logFields(ctx, info.FullMethod), req)
+}
+
+func logFieldsStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, serverStream{ServerStream: ss, ctx: logFields(ss.Context(), info.FullMethod)})
+}
@@ -0,0 +1,6 @@
+package grpcserver
+
+type ServerConfig struct {
+	Enabled bool  `yaml:"enabled"`
+	Port    int32 `yaml:"port"`
+}
@@ -0,0 +1,60 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/pkg/logger"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	APIKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+type Authentication struct {
+	logger        zerolog.Logger
+	authenticator auth.Authenticator
+}
+
+func NewAuthentication(log zerolog.Logger, authenticator auth.Authenticator) *Authentication {
+	return &Authentication{logger: logger.WithModule(log, "authentication"), authenticator: authenticator}
+}
+
+// Middleware refuses requests without valid credentials and puts the
+// principal into the request context for the handlers and services.
+func (a *Authentication) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticator.Authenticate(r.Context(), RequestCredentials(r))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case errors.Is(err, auth.ErrNoCredentials):
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				utils.RespondError(w, http.StatusUnauthorized, err.Error())
+			case errors.Is(err, auth.ErrInvalidCredentials):
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				utils.RespondError(w, http.StatusUnauthorized, auth.ErrInvalidCredentials.Error())
+			default:
+				logger.FromContext(r.Context(), a.logger).Error().Err(err).Msg("authenticate")
+				utils.RespondError(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		logger.AddFields(r.Context(), "principal", principal.ID)
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+func RequestCredentials(r *http.Request) auth.Credentials {
+	creds := auth.Credentials{APIKey: r.Header.Get(APIKeyHeader)}
+	if header := r.Header.Get(AuthorizationHeader); len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		creds.BearerToken = strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	return creds
+}
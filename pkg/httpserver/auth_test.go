@@ -0,0 +1,68 @@
+package httpserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type authenticatorFunc func(ctx context.Context, creds auth.Credentials) (auth.Principal, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, creds auth.Credentials) (auth.Principal, error) {
+	return f(ctx, creds)
+}
+
+func TestAuthentication_Middleware(t *testing.T) {
+	authentication := httpserver.NewAuthentication(zerolog.Nop(), authenticatorFunc(func(_ context.Context, creds auth.Credentials) (auth.Principal, error) {
+		switch {
+		case creds.APIKey == "secret", creds.BearerToken == "token":
+			return auth.Principal{ID: "shop"}, nil
+		case creds.APIKey == "broken":
+			return auth.Principal{}, errors.New("connection refused")
+		case creds.APIKey != "", creds.BearerToken != "":
+			return auth.Principal{}, auth.ErrInvalidCredentials
+		}
+		return auth.Principal{}, auth.ErrNoCredentials
+	}))
+	handler := authentication.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "shop", principal.ID)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"api key", httpserver.APIKeyHeader, "secret", http.StatusOK},
+		{"bearer token", httpserver.AuthorizationHeader, "bearer token", http.StatusOK},
+		{"invalid token", httpserver.AuthorizationHeader, "Bearer other", http.StatusUnauthorized},
+		{"basic auth", httpserver.AuthorizationHeader, "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"store failure", httpserver.APIKeyHeader, "broken", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+	"wallet-api/pkg/httpserver/utils"
+)
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+
+	shutdownCheck           = "shutdown"
+	defaultReadinessTimeout = 2 * time.Second
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// HealthCheck returns an error when a dependency is not ready to serve.
+type HealthCheck func(ctx context.Context) error
+
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// Health serves the liveness and the readiness probes.
+type Health struct {
+	timeout  time.Duration
+	mu       sync.Mutex
+	checks   map[string]HealthCheck
+	draining atomic.Bool
+}
+
+// NewHealth gives every readiness check timeout to answer.
+func NewHealth(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	return &Health{timeout: timeout, checks: map[string]HealthCheck{}}
+}
+
+func (h *Health) AddCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Drain fails the readiness from now on, so that the load balancers stop
+// sending requests before the server shuts down.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Live answers 200 while the process is able to serve requests at all.
+func (h *Health) Live(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	utils.RespondJSON(w, http.StatusOK, HealthResponse{Status: HealthStatusOK})
+}
+
+// Ready runs the checks concurrently and answers 503 with the failed ones
+// when any of them fails.
+func (h *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	h.mu.Lock()
+	checks := make(map[string]HealthCheck, len(h.checks)+1)
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.Unlock()
+	checks[shutdownCheck] = func(context.Context) error {
+		if h.draining.Load() {
+			return ErrShuttingDown
+		}
+		return nil
+	}
+
+	response := HealthResponse{Status: HealthStatusOK, Checks: make(map[string]HealthCheckResult, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := HealthCheckResult{Status: HealthStatusOK}
+			if err := check(ctx); err != nil {
+				result = HealthCheckResult{Status: HealthStatusFail, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = result
+			if result.Status == HealthStatusFail {
+				response.Status = HealthStatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if response.Status == HealthStatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	utils.RespondJSON(w, status, response)
+}
@@ -0,0 +1,87 @@
+package httpserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-api/pkg/httpserver"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthLive(t *testing.T) {
+	health := httpserver.NewHealth(time.Second)
+	health.AddCheck("database", func(context.Context) error { return errors.New("connection refused") })
+	health.Drain()
+
+	w := httptest.NewRecorder()
+	health.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestHealthReady(t *testing.T) {
+	ready := func(health *httpserver.Health) (int, httpserver.HealthResponse) {
+		w := httptest.NewRecorder()
+		health.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var response httpserver.HealthResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	ok := func(context.Context) error { return nil }
+
+	t.Run("ready", func(t *testing.T) {
+		health := httpserver.NewHealth(time.Second)
+		health.AddCheck("database", ok)
+		health.AddCheck("migrations", ok)
+
+		status, response := ready(health)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, httpserver.HealthResponse{
+			Status: httpserver.HealthStatusOK,
+			Checks: map[string]httpserver.HealthCheckResult{
+				"database":   {Status: httpserver.HealthStatusOK},
+				"migrations": {Status: httpserver.HealthStatusOK},
+				"shutdown":   {Status: httpserver.HealthStatusOK},
+			},
+		}, response)
+	})
+
+	t.Run("failed check", func(t *testing.T) {
+		health := httpserver.NewHealth(time.Second)
+		health.AddCheck("database", ok)
+		health.AddCheck("migrations", func(context.Context) error { return errors.New("migration version 20, expected 21") })
+
+		status, response := ready(health)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, httpserver.HealthStatusFail, response.Status)
+		assert.Equal(t, httpserver.HealthCheckResult{Status: httpserver.HealthStatusOK}, response.Checks["database"])
+		assert.Equal(t, httpserver.HealthCheckResult{Status: httpserver.HealthStatusFail, Error: "migration version 20, expected 21"}, response.Checks["migrations"])
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		health := httpserver.NewHealth(10 * time.Millisecond)
+		health.AddCheck("database", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		status, response := ready(health)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, httpserver.HealthCheckResult{Status: httpserver.HealthStatusFail, Error: context.DeadlineExceeded.Error()}, response.Checks["database"])
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		health := httpserver.NewHealth(time.Second)
+		health.AddCheck("database", ok)
+		health.Drain()
+
+		status, response := ready(health)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, httpserver.HealthCheckResult{Status: httpserver.HealthStatusFail, Error: httpserver.ErrShuttingDown.Error()}, response.Checks["shutdown"])
+	})
+}
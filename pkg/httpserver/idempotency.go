@@ -0,0 +1,223 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyStatusProgress = 0
+	// a key released between the claim and the replay is claimed again, at
+	// most this many times in all
+	maxIdempotencyClaimAttempts = 3
+)
+
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// IdempotencyStore persists the first response given for an idempotency key.
+type IdempotencyStore interface {
+	// Claim reserves the key for ttl under token, a claim still in progress
+	// after lease is taken to be abandoned by a crashed instance and may be
+	// claimed again with another token.
+	Claim(ctx context.Context, key, token, requestHash string, ttl, lease time.Duration) (bool, error)
+	// Find returns ErrIdempotencyKeyNotFound for a key that is not claimed.
+	Find(ctx context.Context, key string) (string, int, []byte, error)
+	// Complete and Release do nothing once the claim of token is taken over.
+	Complete(ctx context.Context, key, token string, status int, body []byte) error
+	Release(ctx context.Context, key, token string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type Idempotency struct {
+	logger       zerolog.Logger
+	store        IdempotencyStore
+	config       IdempotencyConfig
+	maxBodyBytes int64
+}
+
+// NewIdempotency hashes request bodies up to maxBodyBytes, larger ones are
+// answered with 413.
+func NewIdempotency(log zerolog.Logger, store IdempotencyStore, cfg IdempotencyConfig, maxBodyBytes int64) *Idempotency {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+	if cfg.CleanupPeriod <= 0 {
+		cfg.CleanupPeriod = defaultIdempotencyCleanupPeriod
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultIdempotencyLease
+	}
+	return &Idempotency{logger: logger.WithModule(log, "idempotency"), store: store, config: cfg, maxBodyBytes: maxBodyBytes}
+}
+
+// Middleware replays the stored response for a repeated Idempotency-Key of
+// the same principal, it has to run after the authentication. Requests
+// without the header are passed through untouched. Server errors are
+// not stored, so the client is free to retry them with the same key.
+func (i *Idempotency) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if len(key) > maxIdempotencyKeyLength {
+			utils.RespondError(w, http.StatusBadRequest, "idempotency key is too long")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, i.maxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.RespondError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+				return
+			}
+			utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(r, body)
+
+		ctx := r.Context()
+		key = scopedIdempotencyKey(ctx, key)
+		// a request outliving its lease must not save over the one that took
+		// the key over
+		token := uuid.NewString()
+		for attempt := 1; ; attempt++ {
+			claimed, err := i.store.Claim(ctx, key, token, requestHash, i.config.TTL, i.config.Lease)
+			if err != nil {
+				logger.FromContext(ctx, i.logger).Error().Err(err).Msg("claim idempotency key")
+				utils.RespondError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			if claimed {
+				break
+			}
+			// the key is released between the claim and the replay when the
+			// first request fails, it is free to claim again
+			if i.replay(w, r, key, requestHash, attempt == maxIdempotencyClaimAttempts) {
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		// the request context may already be cancelled here, the outcome still
+		// has to be saved
+		storeCtx := context.WithoutCancel(ctx)
+		if recorder.status >= http.StatusInternalServerError {
+			err = i.store.Release(storeCtx, key, token)
+		} else {
+			err = i.store.Complete(storeCtx, key, token, recorder.status, recorder.body.Bytes())
+		}
+		if err != nil {
+			logger.FromContext(ctx, i.logger).Error().Err(err).Str("key", key).Msg("save idempotent response")
+		}
+	}
+}
+
+// replay answers with the stored response and returns true, or returns false
+// when the key is gone and may be claimed again, unless it is the last
+// attempt.
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, key, requestHash string, last bool) bool {
+	storedHash, status, body, err := i.store.Find(r.Context(), key)
+	if errors.Is(err, ErrIdempotencyKeyNotFound) {
+		if !last {
+			return false
+		}
+		// claimed and released over and over by concurrent requests
+		utils.RespondError(w, http.StatusConflict, "request with this idempotency key is in progress")
+		return true
+	}
+	if err != nil {
+		logger.FromContext(r.Context(), i.logger).Error().Err(err).Msg("find idempotency key")
+		utils.RespondError(w, http.StatusInternalServerError, "internal server error")
+		return true
+	}
+	if storedHash != requestHash {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "idempotency key is already used for another request")
+		return true
+	}
+	if status == idempotencyStatusProgress {
+		utils.RespondError(w, http.StatusConflict, "request with this idempotency key is in progress")
+		return true
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+	return true
+}
+
+// RunCleanup periodically removes expired keys until ctx is done.
+func (i *Idempotency) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(i.config.CleanupPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := i.store.DeleteExpired(ctx)
+			if err != nil {
+				i.logger.Error().Err(err).Msg("delete expired idempotency keys")
+				continue
+			}
+			i.logger.Debug().Int64("deleted", deleted).Msg("expired idempotency keys deleted")
+		}
+	}
+}
+
+// scopedIdempotencyKey prefixes key with the authenticated principal, so that
+// a client can neither replay the responses of another one nor take the keys
+// it is going to use. The length of the id keeps the prefix unambiguous.
+func scopedIdempotencyKey(ctx context.Context, key string) string {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return key
+	}
+	return strconv.Itoa(len(principal.ID)) + ":" + principal.ID + ":" + key
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
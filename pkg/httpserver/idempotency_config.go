@@ -0,0 +1,20 @@
+package httpserver
+
+import "time"
+
+const (
+	defaultIdempotencyTTL           = 24 * time.Hour
+	defaultIdempotencyCleanupPeriod = time.Hour
+	defaultIdempotencyLease         = 10 * time.Minute
+)
+
+// IdempotencyConfig keeps the responses for TTL. A request still in progress
+// after Lease, e.g. of an instance that crashed before saving the response,
+// no longer holds its key; Lease has to be well above the longest a request
+// may run, the write timeout does not stop a handler retrying its
+// transaction.
+type IdempotencyConfig struct {
+	TTL           time.Duration `yaml:"ttl"`
+	Lease         time.Duration `yaml:"lease"`
+	CleanupPeriod time.Duration `yaml:"cleanup_period"`
+}
@@ -0,0 +1,259 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRecord struct {
+	token  string
+	hash   string
+	status int
+	body   []byte
+	// leaseExpired lets the next claim take the key over
+	leaseExpired bool
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]memoryIdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Claim(_ context.Context, key, token, requestHash string, _, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && !(record.status == 0 && record.leaseExpired) {
+		return false, nil
+	}
+	s.records[key] = memoryIdempotencyRecord{token: token, hash: requestHash}
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) expireLease(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[key]
+	record.leaseExpired = true
+	s.records[key] = record
+}
+
+func (s *memoryIdempotencyStore) Find(_ context.Context, key string) (string, int, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return "", 0, nil, httpserver.ErrIdempotencyKeyNotFound
+	}
+	return record.hash, record.status, record.body, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key, token string, status int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || record.token != token || record.status != 0 {
+		return nil
+	}
+	record.status, record.body = status, body
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records[key].token == token {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency_Middleware(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	idempotency := httpserver.NewIdempotency(zerolog.Nop(), store, httpserver.IdempotencyConfig{}, 1024)
+
+	calls := 0
+	status := http.StatusOK
+	var during func()
+	handler := idempotency.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if during != nil {
+			during()
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"ok"}`))
+	})
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(httpserver.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("without key", func(t *testing.T) {
+		calls = 0
+		send("", `{"amount":1}`)
+		send("", `{"amount":1}`)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("repeated request is replayed", func(t *testing.T) {
+		calls = 0
+		first := send("key-1", `{"amount":1}`)
+		second := send("key-1", `{"amount":1}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(httpserver.IdempotentReplayedHeader))
+	})
+
+	t.Run("key reused with another payload", func(t *testing.T) {
+		calls = 0
+		w := send("key-1", `{"amount":2}`)
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		var concurrent *httptest.ResponseRecorder
+		during = func() {
+			during = nil
+			concurrent = send("key-2", `{"amount":1}`)
+		}
+		send("key-2", `{"amount":1}`)
+
+		assert.Equal(t, http.StatusConflict, concurrent.Code)
+	})
+
+	t.Run("server error is not stored", func(t *testing.T) {
+		calls = 0
+		status = http.StatusInternalServerError
+		send("key-3", `{"amount":1}`)
+		status = http.StatusOK
+		w := send("key-3", `{"amount":1}`)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("request outliving its lease", func(t *testing.T) {
+		calls = 0
+		during = func() {
+			during = nil
+			store.expireLease("key-4")
+			status = http.StatusCreated
+			send("key-4", `{"amount":1}`)
+			status = http.StatusOK
+		}
+		send("key-4", `{"amount":1}`)
+		w := send("key-4", `{"amount":1}`)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusCreated, w.Code, "the response of the request that took the key over is kept")
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		calls = 0
+		w := send("key-5", `{"amount":1,"comment":"`+strings.Repeat("a", 1024)+`"}`)
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+// releasingIdempotencyStore refuses the first claims as if the key were taken
+// and released right before the replay looks for it.
+type releasingIdempotencyStore struct {
+	*memoryIdempotencyStore
+	refuse int
+}
+
+func (s *releasingIdempotencyStore) Claim(ctx context.Context, key, token, requestHash string, ttl, lease time.Duration) (bool, error) {
+	if s.refuse > 0 {
+		s.refuse--
+		return false, nil
+	}
+	return s.memoryIdempotencyStore.Claim(ctx, key, token, requestHash, ttl, lease)
+}
+
+func TestIdempotency_ReleasedKey(t *testing.T) {
+	calls := 0
+	send := func(store httpserver.IdempotencyStore) *httptest.ResponseRecorder {
+		handler := httpserver.NewIdempotency(zerolog.Nop(), store, httpserver.IdempotencyConfig{}, 1024).Middleware(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(`{"amount":1}`))
+		req.Header.Set(httpserver.IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("claimed again", func(t *testing.T) {
+		calls = 0
+		w := send(&releasingIdempotencyStore{memoryIdempotencyStore: newMemoryIdempotencyStore(), refuse: 2})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		calls = 0
+		w := send(&releasingIdempotencyStore{memoryIdempotencyStore: newMemoryIdempotencyStore(), refuse: 3})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Zero(t, calls)
+	})
+}
+
+func TestIdempotency_Principals(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	idempotency := httpserver.NewIdempotency(zerolog.Nop(), store, httpserver.IdempotencyConfig{}, 1024)
+
+	var calls []string
+	handler := idempotency.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := auth.FromContext(r.Context())
+		calls = append(calls, principal.ID)
+		_, _ = w.Write([]byte(`{"owner":"` + principal.ID + `"}`))
+	})
+	send := func(principal string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(`{"currency":"RUB"}`))
+		req.Header.Set(httpserver.IdempotencyKeyHeader, "key-1")
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: principal}))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	first := send("shop-a")
+	second := send("shop-b")
+	replayed := send("shop-a")
+
+	assert.Equal(t, []string{"shop-a", "shop-b"}, calls, "the key of shop-a is not used for shop-b")
+	assert.JSONEq(t, `{"owner":"shop-b"}`, second.Body.String())
+	assert.Empty(t, second.Header().Get(httpserver.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, "true", replayed.Header().Get(httpserver.IdempotentReplayedHeader))
+}
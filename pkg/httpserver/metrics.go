@@ -0,0 +1,68 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Served http requests.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to serve http requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Metrics counts the requests and their durations by the route pattern.
+func Metrics(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r)
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		route := routePattern(r)
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(sw.status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsServer serves the default Prometheus registry on its own port, so
+// that the api port never exposes it.
+type metricsServer struct {
+	*http.Server
+}
+
+func newMetricsServer(cfg ServerConfig) *metricsServer {
+	mux := http.NewServeMux()
+	mux.Handle(http.MethodGet+" "+cfg.MetricsPath, promhttp.Handler())
+	return &metricsServer{Server: &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.MetricsPort),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		Handler:           mux,
+	}}
+}
+
+func (s *metricsServer) Run() error {
+	if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *metricsServer) Shutdown(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"time"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+type Middleware func(next http.HandlerFunc) http.HandlerFunc
+
+// Chain wraps handler so that the first middleware is the outermost one.
+func Chain(handler http.HandlerFunc, middlewares ...Middleware) http.HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type requestIDKeyType struct{}
+
+var requestIDKey = requestIDKeyType{}
+
+// RequestIDFromContext returns the id given to the request by RequestID, or
+// an empty string outside of it.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// RequestID keeps the RequestIDHeader of the client or generates a new one,
+// puts it into the request context and sends it back in the response.
+func RequestID(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	}
+}
+
+// RequestLogger puts the request id, the route and the remote address into
+// the log fields of the request context, for logger.FromContext in the
+// middlewares, services and repositories. It has to run after RequestID.
+func RequestLogger(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := logger.NewContext(r.Context(),
+			"request_id", RequestIDFromContext(r.Context()),
+			"route", routePattern(r),
+			"remote_addr", r.RemoteAddr,
+		)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Recovery answers 500 instead of dropping the connection when a handler
+// panics, and logs the panic with its stack.
+func Recovery(log zerolog.Logger) Middleware {
+	log = logger.WithModule(log, "recovery")
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+				logger.FromContext(r.Context(), log).Error().
+					Interface("panic", rec).
+					Bytes("stack", debug.Stack()).
+					Msg("handler panic")
+				if sw.status == 0 {
+					w.Header().Set("Content-Type", "application/json")
+					utils.RespondError(w, http.StatusInternalServerError, "internal server error")
+				}
+			}()
+			next(sw, r)
+		}
+	}
+}
+
+// AccessLog logs every request once it is served, with the log fields the
+// inner middlewares and services added to its context.
+func AccessLog(log zerolog.Logger) Middleware {
+	log = logger.WithModule(log, "access")
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next(sw, r)
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			logger.FromContext(r.Context(), log).Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", sw.status).
+				Int("size", sw.size).
+				Dur("duration", time.Since(start)).
+				Msg("request")
+		}
+	}
+}
+
+// statusWriter remembers the status and the size of the response. Unwrap
+// keeps http.ResponseController working through it for the event streams.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.size += n
+	return n, err
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
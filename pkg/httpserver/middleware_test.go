@@ -0,0 +1,170 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/logger"
+
+	"github.com/goccy/go-json"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// tag appends name to the X-Trace header, to see the order the middlewares
+// run in.
+func tag(name string) httpserver.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next(w, r)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	mux := http.NewServeMux()
+	router := httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	})
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router.GET("/public", ok)
+	router.Use(tag("root"))
+	router.PUT("/items/{id}", ok)
+	group := router.Group("/admin", tag("admin"))
+	group.Use(tag("inner"))
+	group.DELETE("/items/{id}", ok)
+	group.PATCH("/items/{id}", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		trace  []string
+	}{
+		{"registered before Use", http.MethodGet, "/public", http.StatusOK, nil},
+		{"put", http.MethodPut, "/items/1", http.StatusOK, []string{"root"}},
+		{"group delete", http.MethodDelete, "/admin/items/1", http.StatusOK, []string{"root", "admin", "inner"}},
+		{"group patch", http.MethodPatch, "/admin/items/1", http.StatusOK, []string{"root", "admin", "inner"}},
+		{"prefix is not registered on parent", http.MethodDelete, "/items/1", http.StatusMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.trace, w.Header().Values("X-Trace"))
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := httpserver.RequestID(func(_ http.ResponseWriter, r *http.Request) {
+		seen = httpserver.RequestIDFromContext(r.Context())
+	})
+
+	t.Run("kept from the client", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(httpserver.RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		assert.Equal(t, "req-1", seen)
+		assert.Equal(t, "req-1", w.Header().Get(httpserver.RequestIDHeader))
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		for _, id := range []string{"", "has space", strings.Repeat("a", 129)} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(httpserver.RequestIDHeader, id)
+			w := httptest.NewRecorder()
+			handler(w, r)
+			assert.Len(t, seen, 36)
+			assert.Equal(t, seen, w.Header().Get(httpserver.RequestIDHeader))
+		}
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	recovery := httpserver.Recovery(zerolog.New(&logs))
+
+	t.Run("panic before the response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		recovery(func(http.ResponseWriter, *http.Request) { panic("boom") })(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"code":500,"error":"internal server error"}`, w.Body.String())
+		assert.Contains(t, logs.String(), `"panic":"boom"`)
+	})
+
+	t.Run("panic after the response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		recovery(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		})(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("abort is not recovered", func(t *testing.T) {
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			recovery(func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) })(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	mux := http.NewServeMux()
+	router := httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	})
+	router.Use(httpserver.RequestID, httpserver.RequestLogger, httpserver.AccessLog(zerolog.New(&logs)))
+	router.POST("/wallets/{ID}", func(w http.ResponseWriter, r *http.Request) {
+		logger.AddFields(r.Context(), "wallet_id", r.PathValue("ID"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/wallets/w1", nil)
+	r.Header.Set(httpserver.RequestIDHeader, "req-1")
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry map[string]any
+	if !assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry)) {
+		return
+	}
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "/wallets/{ID}", entry["route"])
+	assert.Equal(t, "192.0.2.1:1234", entry["remote_addr"])
+	assert.Equal(t, "w1", entry["wallet_id"], "added by the handler")
+	assert.Equal(t, http.MethodPost, entry["method"])
+	assert.Equal(t, "/wallets/w1", entry["path"])
+	assert.EqualValues(t, http.StatusCreated, entry["status"])
+	assert.EqualValues(t, len("created"), entry["size"])
+}
+
+func TestMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	router := httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	})
+	router.Use(httpserver.Metrics).GET("/metrics-test/{ID}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/1", nil))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/2", nil))
+
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	exposed := w.Body.String()
+	assert.Contains(t, exposed, `http_requests_total{method="GET",route="/metrics-test/{ID}",status="404"} 2`)
+	assert.Contains(t, exposed, `http_request_duration_seconds_count{method="GET",route="/metrics-test/{ID}"} 2`)
+}
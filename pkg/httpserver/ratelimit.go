@@ -0,0 +1,194 @@
+package httpserver
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/pkg/logger"
+
+	"github.com/rs/zerolog"
+)
+
+const RetryAfterHeader = "Retry-After"
+
+// RateLimitStore keeps the token buckets.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of every one of the distinct keys in
+	// one go and returns zero, or how long to wait for the next token of the
+	// emptiest bucket when any of them is empty. The buckets that had a token
+	// lose it either way.
+	Take(ctx context.Context, keys []string, rate float64, burst int) (time.Duration, error)
+	// DeleteIdle removes the buckets not used for idle, they are full again
+	// by then.
+	DeleteIdle(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+type RateLimiter struct {
+	logger zerolog.Logger
+	store  RateLimitStore
+	config RateLimitConfig
+}
+
+func NewRateLimiter(log zerolog.Logger, store RateLimitStore, cfg RateLimitConfig) *RateLimiter {
+	if cfg.CleanupPeriod <= 0 {
+		cfg.CleanupPeriod = defaultRateLimitCleanupPeriod
+	}
+	cfg.Client.Burst = max(cfg.Client.Burst, 1)
+	cfg.Wallet.Burst = max(cfg.Wallet.Burst, 1)
+	return &RateLimiter{logger: logger.WithModule(log, "rate_limit"), store: store, config: cfg}
+}
+
+// Client limits the requests of every client: the authenticated principal,
+// or the remote address without one. It has to run after the
+// authentication.
+func (l *RateLimiter) Client(next http.HandlerFunc) http.HandlerFunc {
+	if !l.config.Enabled || l.config.Client.Rate <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if l.take(w, r, l.config.Client, []string{"client:" + clientID(r)}) {
+			next(w, r)
+		}
+	}
+}
+
+// Wallet limits the requests changing every wallet, wallets returns the
+// distinct ones the request targets. A request for several wallets takes a
+// token from each of them with one store call, however many they are.
+func (l *RateLimiter) Wallet(wallets func(r *http.Request) []string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if !l.config.Enabled || l.config.Wallet.Rate <= 0 {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			keys := wallets(r)
+			if len(keys) == 0 {
+				next(w, r)
+				return
+			}
+			for i, wallet := range keys {
+				keys[i] = "wallet:" + wallet
+			}
+			if l.take(w, r, l.config.Wallet, keys) {
+				next(w, r)
+			}
+		}
+	}
+}
+
+// take answers 429 and returns false when a bucket of keys is empty. Store
+// failures let the request through, the limits must not take the api down
+// together with the store.
+func (l *RateLimiter) take(w http.ResponseWriter, r *http.Request, limit RateLimit, keys []string) bool {
+	wait, err := l.store.Take(r.Context(), keys, limit.Rate, limit.Burst)
+	if err != nil {
+		logger.FromContext(r.Context(), l.logger).Error().Err(err).Strs("keys", keys).Msg("take rate limit token")
+		return true
+	}
+	if wait <= 0 {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(RetryAfterHeader, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	utils.RespondError(w, http.StatusTooManyRequests, "too many requests")
+	return false
+}
+
+func clientID(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RunCleanup periodically removes the idle buckets until ctx is done.
+func (l *RateLimiter) RunCleanup(ctx context.Context) {
+	if !l.config.Enabled {
+		return
+	}
+	// a bucket is full and the same as a new one after burst/rate seconds
+	var idle time.Duration
+	for _, limit := range []RateLimit{l.config.Client, l.config.Wallet} {
+		if limit.Rate > 0 {
+			idle = max(idle, time.Duration(float64(limit.Burst)/limit.Rate*float64(time.Second)))
+		}
+	}
+	ticker := time.NewTicker(l.config.CleanupPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := l.store.DeleteIdle(ctx, idle)
+			if err != nil {
+				l.logger.Error().Err(err).Msg("delete idle rate limit buckets")
+				continue
+			}
+			l.logger.Debug().Int64("deleted", deleted).Msg("idle rate limit buckets deleted")
+		}
+	}
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+// NewMemoryRateLimitStore keeps the buckets in memory, every instance limits
+// the requests it serves on its own.
+func NewMemoryRateLimitStore(now func() time.Time) RateLimitStore {
+	return &memoryRateLimitStore{buckets: map[string]*memoryBucket{}, now: now}
+}
+
+func (s *memoryRateLimitStore) Take(_ context.Context, keys []string, rate float64, burst int) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var wait time.Duration
+	for _, key := range keys {
+		bucket, ok := s.buckets[key]
+		if !ok {
+			bucket = &memoryBucket{tokens: float64(burst), updated: now}
+			s.buckets[key] = bucket
+		}
+		bucket.tokens = min(float64(burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+		bucket.updated = now
+		if bucket.tokens >= 1 {
+			bucket.tokens--
+			continue
+		}
+		wait = max(wait, time.Duration((1-bucket.tokens)/rate*float64(time.Second)))
+	}
+	return wait, nil
+}
+
+func (s *memoryRateLimitStore) DeleteIdle(_ context.Context, idle time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	before := s.now().Add(-idle)
+	for key, bucket := range s.buckets {
+		if bucket.updated.Before(before) {
+			delete(s.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
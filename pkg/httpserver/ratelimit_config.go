@@ -0,0 +1,28 @@
+package httpserver
+
+import "time"
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+
+	defaultRateLimitCleanupPeriod = time.Minute
+)
+
+// RateLimitConfig limits the requests of every client and the requests
+// changing every wallet. Buckets are kept in memory of the instance, or in
+// Postgres to be shared by all of them.
+type RateLimitConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Store         string        `yaml:"store"`
+	CleanupPeriod time.Duration `yaml:"cleanup_period"`
+	Client        RateLimit     `yaml:"client"`
+	Wallet        RateLimit     `yaml:"wallet"`
+}
+
+// RateLimit is a token bucket refilled by Rate tokens per second up to Burst,
+// a zero Rate turns the limit off.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
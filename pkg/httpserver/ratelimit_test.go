@@ -0,0 +1,160 @@
+package httpserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)}
+	store := httpserver.NewMemoryRateLimitStore(clock.Now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		wait, err := store.Take(ctx, []string{"a"}, 2, 3)
+		assert.NoError(t, err)
+		assert.Zero(t, wait, "burst token %d", i)
+	}
+	wait, err := store.Take(ctx, []string{"a"}, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	wait, _ = store.Take(ctx, []string{"b"}, 2, 3)
+	assert.Zero(t, wait, "buckets are separate")
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	wait, _ = store.Take(ctx, []string{"a"}, 2, 3)
+	assert.Zero(t, wait, "refilled")
+	wait, _ = store.Take(ctx, []string{"a"}, 2, 3)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	wait, _ = store.Take(ctx, []string{"b", "a"}, 2, 3)
+	assert.Equal(t, 500*time.Millisecond, wait, "one of the buckets is empty")
+	for i := 0; i < 2; i++ {
+		wait, _ = store.Take(ctx, []string{"b"}, 2, 3)
+		assert.Zero(t, wait)
+	}
+	wait, _ = store.Take(ctx, []string{"b"}, 2, 3)
+	assert.Equal(t, 500*time.Millisecond, wait, "the other buckets are taken from")
+
+	clock.now = clock.now.Add(time.Minute)
+	deleted, err := store.DeleteIdle(ctx, 30*time.Second)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+}
+
+type rateLimitStoreFunc func(key string) (time.Duration, error)
+
+func (f rateLimitStoreFunc) Take(_ context.Context, keys []string, _ float64, _ int) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range keys {
+		w, err := f(key)
+		if err != nil {
+			return 0, err
+		}
+		wait = max(wait, w)
+	}
+	return wait, nil
+}
+
+func (f rateLimitStoreFunc) DeleteIdle(context.Context, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func TestRateLimiter(t *testing.T) {
+	var taken []string
+	store := rateLimitStoreFunc(func(key string) (time.Duration, error) {
+		taken = append(taken, key)
+		switch key {
+		case "client:blocked", "wallet:w2":
+			return 1500 * time.Millisecond, nil
+		case "client:broken":
+			return 0, errors.New("connection refused")
+		}
+		return 0, nil
+	})
+	cfg := httpserver.RateLimitConfig{
+		Enabled: true,
+		Client:  httpserver.RateLimit{Rate: 1, Burst: 1},
+		Wallet:  httpserver.RateLimit{Rate: 1, Burst: 1},
+	}
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	t.Run("client", func(t *testing.T) {
+		handler := httpserver.NewRateLimiter(zerolog.Nop(), store, cfg).Client(ok)
+		tests := []struct {
+			name       string
+			principal  string
+			key        string
+			status     int
+			retryAfter string
+		}{
+			{"principal", "shop", "client:shop", http.StatusOK, ""},
+			{"remote address", "", "client:192.0.2.1", http.StatusOK, ""},
+			{"limited", "blocked", "client:blocked", http.StatusTooManyRequests, "2"},
+			{"store failure", "broken", "client:broken", http.StatusOK, ""},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				taken = nil
+				r := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+				if tt.principal != "" {
+					r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{ID: tt.principal}))
+				}
+				w := httptest.NewRecorder()
+				handler(w, r)
+				assert.Equal(t, tt.status, w.Code)
+				assert.Equal(t, []string{tt.key}, taken)
+				assert.Equal(t, tt.retryAfter, w.Header().Get(httpserver.RetryAfterHeader))
+			})
+		}
+	})
+
+	t.Run("wallet", func(t *testing.T) {
+		limiter := httpserver.NewRateLimiter(zerolog.Nop(), store, cfg)
+		wallets := []string{"w1", "w2", "w3"}
+		handler := limiter.Wallet(func(*http.Request) []string { return wallets })(ok)
+
+		taken = nil
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/api/v1/wallet/batch", nil))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, []string{"wallet:w1", "wallet:w2", "wallet:w3"}, taken)
+
+		taken, wallets = nil, nil
+		w = httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/api/v1/wallets", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, taken)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg := cfg
+		cfg.Enabled = false
+		limiter := httpserver.NewRateLimiter(zerolog.Nop(), store, cfg)
+		taken = nil
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+		r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{ID: "blocked"}))
+		limiter.Client(ok)(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, taken)
+	})
+}
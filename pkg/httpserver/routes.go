@@ -2,21 +2,92 @@ package httpserver
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 )
 
 type Router interface {
 	GET(relativePath string, handler http.HandlerFunc) Router
 	POST(relativePath string, handler http.HandlerFunc) Router
+	PUT(relativePath string, handler http.HandlerFunc) Router
+	PATCH(relativePath string, handler http.HandlerFunc) Router
+	DELETE(relativePath string, handler http.HandlerFunc) Router
+	Handle(method, relativePath string, handler http.HandlerFunc) Router
+	// Use wraps the routes registered after it by the middlewares.
+	Use(middlewares ...Middleware) Router
+	// Group returns a router registering its routes under prefix, wrapped by
+	// the middlewares of this router and then by its own ones.
+	Group(prefix string, middlewares ...Middleware) Router
 }
 
-func (s *server) GET(relativePath string, handler http.HandlerFunc) Router {
-	pattern := http.MethodGet + " /api/v1" + relativePath
-	s.mux.Handle(pattern, handler)
-	return s
+// HandleFunc registers handler for the method and the path relative to
+// /api/v1.
+type HandleFunc func(method, relativePath string, handler http.HandlerFunc)
+
+type router struct {
+	handle      HandleFunc
+	prefix      string
+	middlewares []Middleware
+}
+
+// NewRouter returns a router passing every route to handle, so routers
+// wrapping another one only have to implement the registration.
+func NewRouter(handle HandleFunc) Router {
+	return &router{handle: handle}
+}
+
+func (r *router) Handle(method, relativePath string, handler http.HandlerFunc) Router {
+	r.handle(method, r.prefix+relativePath, Chain(handler, r.middlewares...))
+	return r
+}
+
+func (r *router) GET(relativePath string, handler http.HandlerFunc) Router {
+	return r.Handle(http.MethodGet, relativePath, handler)
+}
+
+func (r *router) POST(relativePath string, handler http.HandlerFunc) Router {
+	return r.Handle(http.MethodPost, relativePath, handler)
+}
+
+func (r *router) PUT(relativePath string, handler http.HandlerFunc) Router {
+	return r.Handle(http.MethodPut, relativePath, handler)
+}
+
+func (r *router) PATCH(relativePath string, handler http.HandlerFunc) Router {
+	return r.Handle(http.MethodPatch, relativePath, handler)
+}
+
+func (r *router) DELETE(relativePath string, handler http.HandlerFunc) Router {
+	return r.Handle(http.MethodDelete, relativePath, handler)
+}
+
+func (r *router) Use(middlewares ...Middleware) Router {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+func (r *router) Group(prefix string, middlewares ...Middleware) Router {
+	return &router{
+		handle: func(method, relativePath string, handler http.HandlerFunc) {
+			r.Handle(method, relativePath, handler)
+		},
+		prefix:      prefix,
+		middlewares: slices.Clone(middlewares),
+	}
+}
+
+func muxHandle(mux *http.ServeMux) HandleFunc {
+	return func(method, relativePath string, handler http.HandlerFunc) {
+		mux.Handle(method+" /api/v1"+relativePath, handler)
+	}
 }
 
-func (s *server) POST(relativePath string, handler http.HandlerFunc) Router {
-	pattern := http.MethodPost + " /api/v1" + relativePath
-	s.mux.Handle(pattern, handler)
-	return s
+// routePattern is the path pattern of the route serving r, e.g.
+// /api/v1/wallets/{WALLET_UUID}, so that the ids in the paths do not make
+// a metric series or a span name each.
+func routePattern(r *http.Request) string {
+	if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+		return path
+	}
+	return r.Pattern
 }
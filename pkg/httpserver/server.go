@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 	"wallet-api/pkg/logger"
 
 	"github.com/rs/zerolog"
@@ -15,18 +18,43 @@ import (
 
 type Server interface {
 	Serve(parentContext context.Context)
+	Attach(r Runner)
+	// AddReadinessCheck must be called before Serve.
+	AddReadinessCheck(name string, check HealthCheck)
 	Router
 }
 
+// Runner is another server started and shut down together with this one,
+// e.g. the gRPC server.
+type Runner interface {
+	Run() error
+	Shutdown(ctx context.Context) error
+}
+
 type server struct {
 	logger zerolog.Logger
-	mux    *http.ServeMux
+	Router
 	*http.Server
-	config ServerConfig
+	config  ServerConfig
+	runners []Runner
+	health  *Health
+}
+
+type shutdownKeyType struct{}
+
+var shutdownKey = shutdownKeyType{}
+
+// ShuttingDown returns a channel closed when the server starts shutting down.
+// Shutdown waits for the running requests, so long-lived ones such as event
+// streams must end on it. Outside of the server the channel is never closed.
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	done, _ := ctx.Value(shutdownKey).(<-chan struct{})
+	return done
 }
 
 func NewServer(log zerolog.Logger, cfg ServerConfig) Server {
 	mux := http.NewServeMux()
+	shuttingDown, shutdown := context.WithCancel(context.Background())
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.Port),
 		ReadTimeout:       cfg.ReadTimeout,
@@ -34,9 +62,29 @@ func NewServer(log zerolog.Logger, cfg ServerConfig) Server {
 		IdleTimeout:       cfg.IdleTimeout,
 		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 		Handler:           mux,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), shutdownKey, shuttingDown.Done())
+		},
 	}
+	srv.RegisterOnShutdown(shutdown)
+	health := NewHealth(cfg.ReadinessTimeout)
+	mux.HandleFunc("GET /healthz", health.Live)
+	mux.HandleFunc("GET /readyz", health.Ready)
 	log = logger.WithModule(log, "server")
-	return &server{logger: log, mux: mux, Server: srv, config: cfg}
+	s := &server{logger: log, Router: NewRouter(muxHandle(mux)), Server: srv, config: cfg, health: health}
+	if cfg.MetricsPath != "" && cfg.MetricsPort != 0 {
+		s.Attach(newMetricsServer(cfg))
+	}
+	return s
+}
+
+// Attach must be called before Serve.
+func (s *server) Attach(r Runner) {
+	s.runners = append(s.runners, r)
+}
+
+func (s *server) AddReadinessCheck(name string, check HealthCheck) {
+	s.health.AddCheck(name, check)
 }
 
 func (s *server) Serve(parentContext context.Context) {
@@ -45,18 +93,48 @@ func (s *server) Serve(parentContext context.Context) {
 			s.logger.Fatal().Err(err).Msg("server fault")
 		}
 	}()
+	for _, r := range s.runners {
+		go func() {
+			if err := r.Run(); err != nil {
+				s.logger.Fatal().Err(err).Msg("server fault")
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 2)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// the readiness fails for DrainDelay before the shutdown, so that the load
+	// balancers stop sending requests first; a second signal skips the wait
+	s.health.Drain()
+	if s.config.DrainDelay > 0 {
+		s.logger.Info().Dur("delay", s.config.DrainDelay).Msg("draining server...")
+		select {
+		case <-time.After(s.config.DrainDelay):
+		case <-quit:
+		}
+	}
+
+	// Shutdown closes ShuttingDown first, so event streams end instead of
+	// holding it until the timeout
 	s.logger.Info().Msg("shutting down server...")
 	ctx, cancel := context.WithTimeout(parentContext, s.config.ShutdownTimeout)
 	defer cancel()
 
+	var wg sync.WaitGroup
+	for _, r := range s.runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.Shutdown(ctx); err != nil {
+				s.logger.Error().Err(err).Msg("server shutdown")
+			}
+		}()
+	}
 	if err := s.Shutdown(ctx); err != nil {
 		s.logger.Fatal().Err(err).Msg("server shutdown")
 	}
-	<-ctx.Done()
+	wg.Wait()
 	s.logger.Info().Msg("server exiting")
 }
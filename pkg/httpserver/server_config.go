@@ -2,11 +2,22 @@ package httpserver
 
 import "time"
 
+// ServerConfig of the http server. The metrics are served on MetricsPath of
+// the separate MetricsPort when both are set, e.g. /metrics on 9100, the
+// port must stay internal to the scrapers. On SIGTERM /readyz fails
+// for DrainDelay before the server shuts down, every readiness check has
+// ReadinessTimeout to answer.
 type ServerConfig struct {
-	Port              int32         `yaml:"port"`
-	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
-	ReadTimeout       time.Duration `yaml:"read_timeout"`
-	WriteTimeout      time.Duration `yaml:"write_timeout"`
-	IdleTimeout       time.Duration `yaml:"idle_timeout"`
-	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	Port              int32             `yaml:"port"`
+	ReadHeaderTimeout time.Duration     `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration     `yaml:"read_timeout"`
+	WriteTimeout      time.Duration     `yaml:"write_timeout"`
+	IdleTimeout       time.Duration     `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration     `yaml:"shutdown_timeout"`
+	DrainDelay        time.Duration     `yaml:"drain_delay"`
+	ReadinessTimeout  time.Duration     `yaml:"readiness_timeout"`
+	MetricsPath       string            `yaml:"metrics_path"`
+	MetricsPort       int32             `yaml:"metrics_port"`
+	Idempotency       IdempotencyConfig `yaml:"idempotency"`
+	RateLimit         RateLimitConfig   `yaml:"rate_limit"`
 }
@@ -0,0 +1,83 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrStreamingUnsupported = errors.New("streaming unsupported")
+
+// SSEWriter writes a text/event-stream response. The server write timeout is
+// lifted for the response, a stream stays open as long as the client wants.
+type SSEWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSEWriter sends the stream headers, retry tells the client how long to
+// wait before reconnecting, zero leaves it to the client.
+func NewSSEWriter(w http.ResponseWriter, retry time.Duration) (*SSEWriter, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	s := &SSEWriter{w: w, rc: rc}
+	if retry > 0 {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds()); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Event sends one event, the client sends id back in Last-Event-ID when it
+// reconnects.
+func (s *SSEWriter) Event(id int64, event string, data []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString("id: " + strconv.FormatInt(id, 10) + "\n")
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Comment sends a line the client ignores, it keeps proxies from closing an
+// idle stream.
+func (s *SSEWriter) Comment(text string) error {
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *SSEWriter) flush() error {
+	if err := s.rc.Flush(); err != nil {
+		if errors.Is(err, http.ErrNotSupported) {
+			return ErrStreamingUnsupported
+		}
+		return err
+	}
+	return nil
+}
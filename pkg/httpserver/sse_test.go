@@ -0,0 +1,37 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-api/pkg/httpserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	stream, err := httpserver.NewSSEWriter(w, 3*time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Event(7, "balance.changed", []byte("{\"amount\":100}\n{\"amount\":200}")))
+	assert.NoError(t, stream.Comment("heartbeat"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: 7\nevent: balance.changed\ndata: {\"amount\":100}\ndata: {\"amount\":200}\n\n"+
+		": heartbeat\n\n", w.Body.String())
+}
+
+func TestShuttingDown_OutsideServer(t *testing.T) {
+	select {
+	case <-httpserver.ShuttingDown(context.Background()):
+		t.Fatal("closed outside of the server")
+	default:
+	}
+}
@@ -0,0 +1,53 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDAttribute = attribute.Key("http.request.id")
+
+// Tracing starts a server span for every request named by its route
+// pattern, continuing the trace of the traceparent header of the client.
+// Server errors mark the span as failed, the trace id is added to the log
+// fields of the request.
+func Tracing(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		route := routePattern(r)
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+		if id := RequestIDFromContext(ctx); id != "" {
+			span.SetAttributes(requestIDAttribute.String(id))
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			logger.AddFields(ctx, "trace_id", sc.TraceID().String())
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r.WithContext(ctx))
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", sw.status))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Config{})
+	assert.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(tracing.Config{}, exporter)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(nil)
+
+	mux := http.NewServeMux()
+	router := httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	})
+	var inner trace.SpanContext
+	router.Use(httpserver.Tracing).GET("/wallets/{WALLET_UUID}", func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/wallets/8d3f0b6e-6a4b-4c51-9d53-2c1f1b1f1a01", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	assert.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "GET /wallets/{WALLET_UUID}", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, span.SpanContext.SpanID(), inner.SpanID(), "handlers get the request span")
+	assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusServiceUnavailable))
+	assert.Equal(t, codes.Error, span.Status.Code)
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/goccy/go-json"
 )
@@ -23,5 +22,5 @@ func RespondJSON(w http.ResponseWriter, status int, payload interface{}) {
 }
 
 func RespondError(w http.ResponseWriter, code int, message string) {
-	RespondJSON(w, code, map[string]string{"code": strconv.Itoa(code), "error": message})
+	RespondJSON(w, code, map[string]any{"code": code, "error": message})
 }
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type fieldsKeyType struct{}
+
+var fieldsKey = fieldsKeyType{}
+
+// fields are shared by all the contexts derived from the one of NewContext,
+// so that the fields added deeper, e.g. by a service, are also logged by the
+// middlewares around it.
+type fields struct {
+	mu      sync.Mutex
+	keyvals []any
+}
+
+// NewContext returns ctx carrying the log fields of a request, keyvals are
+// pairs of a key and a value. The fields already carried by ctx are kept.
+func NewContext(ctx context.Context, keyvals ...any) context.Context {
+	f := &fields{}
+	if parent, ok := ctx.Value(fieldsKey).(*fields); ok {
+		f.keyvals = parent.list()
+	}
+	f.add(keyvals)
+	return context.WithValue(ctx, fieldsKey, f)
+}
+
+// AddFields adds keyvals to the fields carried by ctx, replacing the values
+// of the keys it already has. It does nothing outside of NewContext.
+func AddFields(ctx context.Context, keyvals ...any) {
+	if f, ok := ctx.Value(fieldsKey).(*fields); ok {
+		f.add(keyvals)
+	}
+}
+
+// FromContext returns base with the fields carried by ctx, e.g. the request
+// id, or base itself outside of a request. It is a pointer as zerolog.Ctx,
+// for the chained calls.
+func FromContext(ctx context.Context, base zerolog.Logger) *zerolog.Logger {
+	if f, ok := ctx.Value(fieldsKey).(*fields); ok {
+		if keyvals := f.list(); len(keyvals) > 0 {
+			base = base.With().Fields(keyvals).Logger()
+		}
+	}
+	return &base
+}
+
+// add skips the keys that are not strings, as zerolog does.
+func (f *fields) add(keyvals []any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		if j := f.index(key); j >= 0 {
+			f.keyvals[j+1] = keyvals[i+1]
+			continue
+		}
+		f.keyvals = append(f.keyvals, key, keyvals[i+1])
+	}
+}
+
+func (f *fields) index(key string) int {
+	for j := 0; j < len(f.keyvals); j += 2 {
+		if f.keyvals[j] == key {
+			return j
+		}
+	}
+	return -1
+}
+
+func (f *fields) list() []any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]any(nil), f.keyvals...)
+}
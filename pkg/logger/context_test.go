@@ -0,0 +1,53 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"wallet-api/pkg/logger"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func logEntry(t *testing.T, ctx context.Context) map[string]any {
+	var logs bytes.Buffer
+	log := logger.WithModule(zerolog.New(&logs), "test")
+	logger.FromContext(ctx, log).Info().Msg("entry")
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	return entry
+}
+
+func TestFromContext(t *testing.T) {
+	t.Run("outside of a request", func(t *testing.T) {
+		ctx := context.Background()
+		logger.AddFields(ctx, "wallet_id", "w1")
+		assert.Equal(t, map[string]any{"level": "info", "module": "test", "message": "entry"}, logEntry(t, ctx))
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		ctx := logger.NewContext(context.Background(), "request_id", "req-1")
+		inner := context.WithValue(ctx, struct{}{}, true)
+		logger.AddFields(inner, "wallet_id", "w1", "amount", 100)
+		logger.AddFields(inner, "amount", 200, 42, "not a key")
+
+		entry := logEntry(t, ctx)
+		assert.Equal(t, "test", entry["module"])
+		assert.Equal(t, "req-1", entry["request_id"])
+		assert.Equal(t, "w1", entry["wallet_id"], "added through a derived context")
+		assert.EqualValues(t, 200, entry["amount"], "replaced")
+		assert.Len(t, entry, 6)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		outer := logger.NewContext(context.Background(), "request_id", "req-1")
+		inner := logger.NewContext(outer, "grpc_method", "/wallet.v1.WalletService/GetWallet")
+		logger.AddFields(inner, "wallet_id", "w1")
+
+		assert.Equal(t, "/wallet.v1.WalletService/GetWallet", logEntry(t, inner)["grpc_method"])
+		assert.Equal(t, "req-1", logEntry(t, inner)["request_id"])
+		assert.NotContains(t, logEntry(t, outer), "wallet_id")
+	})
+}
@@ -1,13 +1,40 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Config of the logger, Format is json or console, the human-readable one
+// for the local runs.
+type Config struct {
+	Level  zerolog.Level `yaml:"level"`
+	Format string        `yaml:"format"`
+}
+
 func NewLogger(level zerolog.Level) zerolog.Logger {
-	return zerolog.New(os.Stdout).With().Timestamp().Caller().Logger().Level(level)
+	return newLogger(os.Stdout, level)
+}
+
+// New returns the logger of cfg writing to stdout.
+func New(cfg Config) zerolog.Logger {
+	var out io.Writer = os.Stdout
+	if cfg.Format == FormatConsole {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+	return newLogger(out, cfg.Level)
+}
+
+func newLogger(out io.Writer, level zerolog.Level) zerolog.Logger {
+	return zerolog.New(out).With().Timestamp().Caller().Logger().Level(level)
 }
 
 func WithModule(base zerolog.Logger, module string) zerolog.Logger {
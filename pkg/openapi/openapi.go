@@ -0,0 +1,205 @@
+// Package openapi reads the subset of OpenAPI 3 used by the api document and
+// validates requests and responses against it.
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	schemaRefPrefix    = "#/components/schemas/"
+	parameterRefPrefix = "#/components/parameters/"
+	contentTypeJSON    = "application/json"
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas,omitempty"`
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+}
+
+// PathItem holds the operations of a path keyed by the lower case method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Minimum     *int64             `json:"minimum,omitempty"`
+	Maximum     *int64             `json:"maximum,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Parse reads the document and checks that every reference in it resolves.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if err := doc.checkOperation(op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+	for name, schema := range doc.Components.Schemas {
+		if err := doc.checkSchema(schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	return &doc, nil
+}
+
+// Operation returns nil when the path has no such method in the document,
+// path is a route pattern as registered on httpserver.Router.
+func (d *Document) Operation(method, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+func (d *Document) checkOperation(op *Operation) error {
+	if len(op.Responses) == 0 {
+		return fmt.Errorf("no responses")
+	}
+	for _, param := range op.Parameters {
+		param, err := d.parameter(param)
+		if err != nil {
+			return err
+		}
+		if err = d.checkSchema(param.Schema); err != nil {
+			return fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+	}
+	if op.RequestBody != nil {
+		if err := d.checkSchema(op.RequestBody.Content[contentTypeJSON].Schema); err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+	}
+	for status, resp := range op.Responses {
+		for _, media := range resp.Content {
+			if err := d.checkSchema(media.Schema); err != nil {
+				return fmt.Errorf("response %s: %w", status, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Document) checkSchema(schema *Schema) error {
+	if schema == nil {
+		return fmt.Errorf("no schema")
+	}
+	if schema.Ref != "" {
+		_, err := d.schema(schema)
+		return err
+	}
+	for _, property := range schema.Properties {
+		if err := d.checkSchema(property); err != nil {
+			return err
+		}
+	}
+	for _, variant := range schema.OneOf {
+		if err := d.checkSchema(variant); err != nil {
+			return err
+		}
+	}
+	if schema.Items != nil {
+		return d.checkSchema(schema.Items)
+	}
+	return nil
+}
+
+// schema resolves a reference to the components, other schemas are returned
+// as they are.
+func (d *Document) schema(schema *Schema) (*Schema, error) {
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	resolved, ok := d.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	if !ok || !strings.HasPrefix(schema.Ref, schemaRefPrefix) {
+		return nil, fmt.Errorf("unresolved reference %s", schema.Ref)
+	}
+	return resolved, nil
+}
+
+func (d *Document) parameter(param *Parameter) (*Parameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	resolved, ok := d.Components.Parameters[strings.TrimPrefix(param.Ref, parameterRefPrefix)]
+	if !ok || !strings.HasPrefix(param.Ref, parameterRefPrefix) {
+		return nil, fmt.Errorf("unresolved reference %s", param.Ref)
+	}
+	return resolved, nil
+}
+
+// PathParameters returns the names of the path parameters of the operation.
+func (d *Document) PathParameters(op *Operation) []string {
+	var names []string
+	for _, param := range op.Parameters {
+		if param, err := d.parameter(param); err == nil && param.In == "path" {
+			names = append(names, param.Name)
+		}
+	}
+	return names
+}
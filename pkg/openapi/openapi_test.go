@@ -0,0 +1,163 @@
+package openapi_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/openapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const document = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "1"},
+  "paths": {
+    "/items/{ID}": {
+      "post": {
+        "operationId": "changeItem",
+        "parameters": [
+          {"$ref": "#/components/parameters/ID"},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 10}}
+        ],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+          "204": {"description": "no content"}
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "ID": {"name": "ID", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}}
+    },
+    "schemas": {
+      "Item": {
+        "type": "object",
+        "required": ["kind", "amount"],
+        "properties": {
+          "kind": {"type": "string", "enum": ["a", "b"]},
+          "amount": {"type": "integer", "minimum": 1},
+          "limit": {"type": "integer", "nullable": true},
+          "tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+        }
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	doc, err := openapi.Parse([]byte(document))
+	if !assert.NoError(t, err) {
+		return
+	}
+	op := doc.Operation(http.MethodPost, "/items/{ID}")
+	if assert.NotNil(t, op) {
+		assert.Equal(t, []string{"ID"}, doc.PathParameters(op))
+	}
+	assert.Nil(t, doc.Operation(http.MethodGet, "/items/{ID}"))
+
+	_, err = openapi.Parse([]byte(strings.Replace(document, "#/components/schemas/Item", "#/components/schemas/Missing", 1)))
+	assert.Error(t, err)
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc, err := openapi.Parse([]byte(document))
+	if !assert.NoError(t, err) {
+		return
+	}
+	op := doc.Operation(http.MethodPost, "/items/{ID}")
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		body   string
+		field  string
+		reason string
+	}{
+		{name: "valid", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", body: `{"kind": "a", "amount": 1, "limit": null}`},
+		{name: "incorrect path parameter", id: "abc", body: `{}`, field: "ID", reason: "must be uuid"},
+		{name: "query out of range", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", query: "?limit=11", body: `{}`, field: "limit", reason: "must be at most 10"},
+		{name: "no body", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", reason: "request body is required"},
+		{name: "required field", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", body: `{"kind": "a"}`, field: "amount", reason: "is required"},
+		{name: "enum", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", body: `{"kind": "c", "amount": 1}`, field: "kind", reason: "must be one of [a b]"},
+		{name: "fraction", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", body: `{"kind": "a", "amount": 1.5}`, field: "amount", reason: "must be integer"},
+		{name: "array item", id: "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a", body: `{"kind": "a", "amount": 1, "tags": ["x", 1]}`, field: "tags[1]", reason: "must be string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/items/"+tt.id+tt.query, strings.NewReader(tt.body))
+			r.SetPathValue("ID", tt.id)
+			err := doc.ValidateRequest(op, r, 1024)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *openapi.ValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				assert.Equal(t, tt.field, validationErr.Field)
+				assert.Equal(t, tt.reason, validationErr.Reason)
+			}
+		})
+	}
+
+	t.Run("unreadable body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/items/x", strings.NewReader(`{"kind":`))
+		r.SetPathValue("ID", "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a")
+		assert.ErrorIs(t, doc.ValidateRequest(op, r, 1024), openapi.ErrUnreadableBody)
+	})
+
+	t.Run("too large body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/items/x", strings.NewReader(`{"kind": "a", "amount": 1, "tags": ["`+strings.Repeat("x", 1024)+`"]}`))
+		r.SetPathValue("ID", "0b6f9a6e-4f0b-4a3e-9d5b-7d0c1d1c2f3a")
+		assert.ErrorIs(t, doc.ValidateRequest(op, r, 1024), openapi.ErrBodyTooLarge)
+	})
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc, err := openapi.Parse([]byte(document))
+	if !assert.NoError(t, err) {
+		return
+	}
+	op := doc.Operation(http.MethodPost, "/items/{ID}")
+
+	assert.NoError(t, doc.ValidateResponse(op, http.StatusOK, []byte(`{"kind": "b", "amount": 2}`)))
+	assert.NoError(t, doc.ValidateResponse(op, http.StatusNoContent, nil))
+	assert.Error(t, doc.ValidateResponse(op, http.StatusOK, []byte(`{"kind": "b"}`)))
+	assert.Error(t, doc.ValidateResponse(op, http.StatusNoContent, []byte(`{}`)))
+	assert.Error(t, doc.ValidateResponse(op, http.StatusNotFound, nil))
+}
+
+func TestRouter_BodyLimit(t *testing.T) {
+	doc, err := openapi.Parse([]byte(document))
+	if !assert.NoError(t, err) {
+		return
+	}
+	mux := http.NewServeMux()
+	router := openapi.NewRouter(doc, httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	}), 1024)
+	var received string
+	router.Handle(http.MethodPost, "/undocumented", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	send := func(body string) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/undocumented", strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(`{"amount": 1}`))
+	assert.Equal(t, `{"amount": 1}`, received, "the body is put back for the handler")
+	received = ""
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(strings.Repeat("x", 1025)))
+	assert.Empty(t, received)
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+)
+
+type router struct {
+	doc          *Document
+	next         httpserver.Router
+	maxBodyBytes int64
+}
+
+// NewRouter validates the requests of the routes described in doc before
+// passing them to the handlers. Bodies over maxBodyBytes are refused with 413
+// on every route, described in doc or not.
+func NewRouter(doc *Document, next httpserver.Router, maxBodyBytes int64) httpserver.Router {
+	r := &router{doc: doc, next: next, maxBodyBytes: maxBodyBytes}
+	return httpserver.NewRouter(r.handle)
+}
+
+func (r *router) handle(method, relativePath string, handler http.HandlerFunc) {
+	r.next.Handle(method, relativePath, r.wrap(method, relativePath, handler))
+}
+
+func (r *router) wrap(method, relativePath string, handler http.HandlerFunc) http.HandlerFunc {
+	op := r.doc.Operation(method, relativePath)
+	return func(w http.ResponseWriter, req *http.Request) {
+		var err error
+		if op == nil || op.RequestBody == nil {
+			// the validation reads only the described bodies
+			_, err = readBody(req, r.maxBodyBytes)
+		}
+		if err == nil && op != nil {
+			err = r.doc.ValidateRequest(op, req, r.maxBodyBytes)
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			if errors.Is(err, ErrBodyTooLarge) {
+				utils.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			if errors.Is(err, ErrUnreadableBody) {
+				utils.RespondError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		handler(w, req)
+	}
+}
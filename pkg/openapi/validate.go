@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnreadableBody = errors.New("unable read request body")
+	ErrBodyTooLarge   = errors.New("request body is too large")
+)
+
+// ValidationError describes the first value that does not match the schema,
+// Field is empty for the whole body.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return e.Field + ": " + e.Reason
+}
+
+// ValidateRequest checks the parameters and the json body of the request.
+// The body is read and put back, so the handler still can decode it, bodies
+// over maxBodyBytes fail with ErrBodyTooLarge.
+func (d *Document) ValidateRequest(op *Operation, r *http.Request, maxBodyBytes int64) error {
+	for _, param := range op.Parameters {
+		param, err := d.parameter(param)
+		if err != nil {
+			return err
+		}
+		var value string
+		switch param.In {
+		case "path":
+			value = r.PathValue(param.Name)
+		case "query":
+			value = r.URL.Query().Get(param.Name)
+		case "header":
+			value = r.Header.Get(param.Name)
+		}
+		if value == "" {
+			if param.Required {
+				return &ValidationError{Field: param.Name, Reason: "is required"}
+			}
+			continue
+		}
+		if err = d.validateParameter(param, value); err != nil {
+			return err
+		}
+	}
+	if op.RequestBody == nil {
+		return nil
+	}
+	body, err := readBody(r, maxBodyBytes)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return &ValidationError{Reason: "request body is required"}
+		}
+		return nil
+	}
+	value, err := decode(body)
+	if err != nil {
+		return ErrUnreadableBody
+	}
+	return d.validate(op.RequestBody.Content[contentTypeJSON].Schema, value, "")
+}
+
+// readBody reads the body up to maxBodyBytes and puts it back for the
+// handler.
+func readBody(r *http.Request, maxBodyBytes int64) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrBodyTooLarge
+		}
+		return nil, ErrUnreadableBody
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ValidateResponse checks that the status is documented for the operation and
+// the body matches its schema.
+func (d *Document) ValidateResponse(op *Operation, status int, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+	media, ok := resp.Content[contentTypeJSON]
+	if !ok {
+		if len(bytes.TrimSpace(body)) != 0 {
+			return fmt.Errorf("status %d has no body in the document", status)
+		}
+		return nil
+	}
+	value, err := decode(body)
+	if err != nil {
+		return err
+	}
+	return d.validate(media.Schema, value, "")
+}
+
+func decode(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (d *Document) validateParameter(param *Parameter, value string) error {
+	schema, err := d.schema(param.Schema)
+	if err != nil {
+		return err
+	}
+	if schema.Type == "integer" || schema.Type == "number" {
+		return d.validate(schema, json.Number(value), param.Name)
+	}
+	return d.validate(schema, value, param.Name)
+}
+
+func (d *Document) validate(schema *Schema, value any, field string) error {
+	schema, err := d.schema(schema)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return &ValidationError{Field: field, Reason: "must not be null"}
+	}
+	if len(schema.OneOf) > 0 {
+		return d.validateOneOf(schema, value, field)
+	}
+	switch schema.Type {
+	case "object":
+		return d.validateObject(schema, value, field)
+	case "array":
+		return d.validateArray(schema, value, field)
+	case "integer", "number":
+		return validateNumber(schema, value, field)
+	case "string":
+		return validateString(schema, value, field)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return &ValidationError{Field: field, Reason: "must be boolean"}
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateOneOf(schema *Schema, value any, field string) error {
+	matched := 0
+	for _, variant := range schema.OneOf {
+		if d.validate(variant, value, field) == nil {
+			matched++
+		}
+	}
+	if matched != 1 {
+		return &ValidationError{Field: field, Reason: "must match exactly one schema"}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, value any, field string) error {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be object"}
+	}
+	for _, name := range schema.Required {
+		if _, ok = object[name]; !ok {
+			return &ValidationError{Field: join(field, name), Reason: "is required"}
+		}
+	}
+	for name, property := range schema.Properties {
+		propertyValue, ok := object[name]
+		if !ok {
+			continue
+		}
+		if err := d.validate(property, propertyValue, join(field, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateArray(schema *Schema, value any, field string) error {
+	items, ok := value.([]any)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be array"}
+	}
+	if schema.MinItems != nil && len(items) < *schema.MinItems {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must contain at least %d items", *schema.MinItems)}
+	}
+	if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must contain at most %d items", *schema.MaxItems)}
+	}
+	if schema.Items == nil {
+		return nil
+	}
+	for i, item := range items {
+		if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, value any, field string) error {
+	number, ok := value.(json.Number)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be " + schema.Type}
+	}
+	if schema.Type == "number" {
+		if _, err := number.Float64(); err != nil {
+			return &ValidationError{Field: field, Reason: "must be number"}
+		}
+		return nil
+	}
+	integer, err := number.Int64()
+	if err != nil {
+		return &ValidationError{Field: field, Reason: "must be integer"}
+	}
+	if schema.Minimum != nil && integer < *schema.Minimum {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at least %d", *schema.Minimum)}
+	}
+	if schema.Maximum != nil && integer > *schema.Maximum {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d", *schema.Maximum)}
+	}
+	return nil
+}
+
+func validateString(schema *Schema, value any, field string) error {
+	str, ok := value.(string)
+	if !ok {
+		return &ValidationError{Field: field, Reason: "must be string"}
+	}
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, str) {
+		return &ValidationError{Field: field, Reason: "must be one of " + fmt.Sprint(schema.Enum)}
+	}
+	if schema.MaxLength != nil && len(str) > *schema.MaxLength {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d characters", *schema.MaxLength)}
+	}
+	switch schema.Format {
+	case "uuid":
+		if _, err := uuid.Parse(str); err != nil {
+			return &ValidationError{Field: field, Reason: "must be uuid"}
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return &ValidationError{Field: field, Reason: "must be date-time"}
+		}
+	}
+	return nil
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import "time"
+
+const (
+	defaultPollPeriod = time.Second
+	defaultBatchSize  = 100
+	defaultLease      = 30 * time.Second
+	defaultRetryMin   = time.Second
+	defaultRetryMax   = 10 * time.Minute
+
+	defaultRetention            = 7 * 24 * time.Hour
+	defaultUndeliveredRetention = 30 * 24 * time.Hour
+	defaultCleanupPeriod        = time.Hour
+
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkMemory  = "memory"
+)
+
+// Config of the relay. Lease is how long a claimed event is hidden from other
+// relays, an event not acknowledged in time is delivered again. Delivered
+// events are kept for Retention, the ones never delivered (all of them while
+// the relay is disabled) for UndeliveredRetention, expired events are looked
+// for every CleanupPeriod.
+type Config struct {
+	Enabled              bool          `yaml:"enabled"`
+	PollPeriod           time.Duration `yaml:"poll_period"`
+	BatchSize            int           `yaml:"batch_size"`
+	Lease                time.Duration `yaml:"lease"`
+	RetryMin             time.Duration `yaml:"retry_min"`
+	RetryMax             time.Duration `yaml:"retry_max"`
+	Retention            time.Duration `yaml:"retention"`
+	UndeliveredRetention time.Duration `yaml:"undelivered_retention"`
+	CleanupPeriod        time.Duration `yaml:"cleanup_period"`
+	Sink                 SinkConfig    `yaml:"sink"`
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.PollPeriod <= 0 {
+		cfg.PollPeriod = defaultPollPeriod
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultLease
+	}
+	if cfg.RetryMin <= 0 {
+		cfg.RetryMin = defaultRetryMin
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = defaultRetryMax
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	if cfg.UndeliveredRetention <= 0 {
+		cfg.UndeliveredRetention = defaultUndeliveredRetention
+	}
+	if cfg.CleanupPeriod <= 0 {
+		cfg.CleanupPeriod = defaultCleanupPeriod
+	}
+	return cfg
+}
+
+type SinkConfig struct {
+	Type       string        `yaml:"type"`
+	File       string        `yaml:"file"`
+	WebhookURL string        `yaml:"webhook_url"`
+	Timeout    time.Duration `yaml:"timeout"`
+	Topic      string        `yaml:"topic"`
+}
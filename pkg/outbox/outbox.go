@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"wallet-api/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Event is a committed change waiting to be published. Payload is json.
+type Event struct {
+	ID          int64
+	Type        string
+	AggregateID uuid.UUID
+	Payload     []byte
+	Created     time.Time
+	Attempts    int
+}
+
+// Store is the outbox table. Events are written to it in the same database
+// transaction as the change they describe.
+type Store interface {
+	// Claim returns up to limit events due for delivery and hides them from
+	// other relays for lease.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	MarkDelivered(ctx context.Context, ids []int64) error
+	MarkFailed(ctx context.Context, id int64, reason string, retryIn time.Duration) error
+	// DeleteExpired removes the events delivered more than retention ago and
+	// the undelivered ones created more than undeliveredRetention ago.
+	DeleteExpired(ctx context.Context, retention, undeliveredRetention time.Duration) (int64, error)
+}
+
+// Sink publishes an event to the outside world. It may be called more than
+// once for the same event, consumers deduplicate by Event.ID.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Relay moves events from the store to the sink with at-least-once delivery:
+// an event is marked delivered only after the sink accepted it, failed events
+// are retried with exponential backoff.
+type Relay struct {
+	logger zerolog.Logger
+	store  Store
+	sink   Sink
+	config Config
+}
+
+func NewRelay(log zerolog.Logger, store Store, sink Sink, cfg Config) *Relay {
+	return &Relay{logger: logger.WithModule(log, "outbox_relay"), store: store, sink: sink, config: cfg.withDefaults()}
+}
+
+// Run relays events until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a full batch means more events are probably waiting
+			for {
+				relayed, err := r.RelayBatch(ctx)
+				if err != nil {
+					r.logger.Error().Err(err).Msg("relay outbox events")
+					break
+				}
+				if relayed < r.config.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// RelayBatch publishes one batch of due events and returns how many were
+// claimed.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	events, err := r.store.Claim(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(events, func(a, b Event) int { return cmp.Compare(a.ID, b.ID) })
+	delivered := make([]int64, 0, len(events))
+	for _, event := range events {
+		if err = r.sink.Publish(ctx, event); err != nil {
+			retryIn := r.backoff(event.Attempts)
+			r.logger.Warn().Err(err).Int64("event", event.ID).Dur("retry_in", retryIn).Msg("publish outbox event")
+			if err = r.store.MarkFailed(ctx, event.ID, err.Error(), retryIn); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		delivered = append(delivered, event.ID)
+	}
+	if len(delivered) > 0 {
+		if err = r.store.MarkDelivered(ctx, delivered); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), nil
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.RetryMin
+	for i := 0; i < attempts && delay < r.config.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.RetryMax)
+}
+
+// Cleaner deletes expired events. It runs whether the relay is enabled or not:
+// events are written with every change either way and read by the event
+// stream.
+type Cleaner struct {
+	logger zerolog.Logger
+	store  Store
+	config Config
+}
+
+func NewCleaner(log zerolog.Logger, store Store, cfg Config) *Cleaner {
+	return &Cleaner{logger: logger.WithModule(log, "outbox_cleaner"), store: store, config: cfg.withDefaults()}
+}
+
+// Run deletes expired events every CleanupPeriod until ctx is done.
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.CleanupPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := c.Clean(ctx)
+			if err != nil {
+				c.logger.Error().Err(err).Msg("delete expired outbox events")
+				continue
+			}
+			c.logger.Debug().Int64("deleted", deleted).Msg("expired outbox events deleted")
+		}
+	}
+}
+
+// Clean deletes the expired events once and returns how many were deleted.
+func (c *Cleaner) Clean(ctx context.Context) (int64, error) {
+	return c.store.DeleteExpired(ctx, c.config.Retention, c.config.UndeliveredRetention)
+}
@@ -0,0 +1,262 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wallet-api/pkg/outbox"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStoreRecord struct {
+	event       outbox.Event
+	delivered   bool
+	deliveredAt time.Time
+	retryIn     time.Duration
+	lastError   string
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records []*memoryStoreRecord
+}
+
+func (s *memoryStore) add(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, &memoryStoreRecord{event: outbox.Event{
+		ID:          int64(len(s.records) + 1),
+		Type:        eventType,
+		AggregateID: uuid.New(),
+		Payload:     []byte(`{"amount":100}`),
+		Created:     time.Now(),
+	}})
+}
+
+// Claim returns every pending event that is not waiting for a retry.
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]outbox.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []outbox.Event
+	for _, record := range s.records {
+		if !record.delivered && record.retryIn == 0 && len(events) < limit {
+			events = append(events, record.event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryStore) MarkDelivered(_ context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.records[id-1].delivered, s.records[id-1].deliveredAt = true, time.Now()
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id int64, reason string, retryIn time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[id-1]
+	record.event.Attempts++
+	record.retryIn, record.lastError = retryIn, reason
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(_ context.Context, retention, undeliveredRetention time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	kept := s.records[:0]
+	for _, record := range s.records {
+		if record.delivered && now.Sub(record.deliveredAt) > retention || !record.delivered && now.Sub(record.event.Created) > undeliveredRetention {
+			continue
+		}
+		kept = append(kept, record)
+	}
+	deleted := int64(len(s.records) - len(kept))
+	s.records = kept
+	return deleted, nil
+}
+
+func (s *memoryStore) ids() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.records))
+	for _, record := range s.records {
+		ids = append(ids, record.event.ID)
+	}
+	return ids
+}
+
+// flakySink fails the first failures publishes and then accepts everything.
+type flakySink struct {
+	failures  int
+	published []int64
+}
+
+func (s *flakySink) Publish(_ context.Context, event outbox.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func TestRelayBatch_Delivered(t *testing.T) {
+	store := &memoryStore{}
+	store.add("balance.changed")
+	store.add("balance.changed")
+	broker := outbox.NewMemoryBroker()
+	relay := outbox.NewRelay(zerolog.Nop(), store, outbox.NewBrokerSink(broker, "wallet-events"), outbox.Config{})
+
+	relayed, err := relay.RelayBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, relayed)
+	assert.True(t, store.records[0].delivered)
+	assert.True(t, store.records[1].delivered)
+	messages := broker.Messages("wallet-events")
+	assert.Len(t, messages, 2)
+	var message map[string]any
+	assert.NoError(t, json.Unmarshal(messages[0], &message))
+	assert.Equal(t, float64(1), message["id"])
+	assert.Equal(t, "balance.changed", message["type"])
+	assert.Equal(t, map[string]any{"amount": float64(100)}, message["payload"])
+
+	relayed, err = relay.RelayBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, relayed)
+	assert.Len(t, broker.Messages("wallet-events"), 2)
+}
+
+func TestRelayBatch_FailedIsRetriedWithBackoff(t *testing.T) {
+	store := &memoryStore{}
+	store.add("balance.changed")
+	store.add("balance.changed")
+	sink := &flakySink{failures: 1}
+	relay := outbox.NewRelay(zerolog.Nop(), store, sink, outbox.Config{RetryMin: time.Second, RetryMax: 5 * time.Second})
+
+	_, err := relay.RelayBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, sink.published)
+	assert.False(t, store.records[0].delivered)
+	assert.Equal(t, time.Second, store.records[0].retryIn)
+	assert.Equal(t, "sink unavailable", store.records[0].lastError)
+	assert.True(t, store.records[1].delivered)
+
+	// the next failures back off exponentially up to RetryMax
+	record := store.records[0]
+	for _, expected := range []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		record.retryIn = 0
+		sink.failures = 1
+		_, err = relay.RelayBatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, expected, record.retryIn)
+	}
+
+	record.retryIn = 0
+	_, err = relay.RelayBatch(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, record.delivered)
+	assert.Equal(t, []int64{2, 1}, sink.published)
+}
+
+func TestRelayRun_StopsWithContext(t *testing.T) {
+	store := &memoryStore{}
+	store.add("balance.changed")
+	sink := &flakySink{}
+	relay := outbox.NewRelay(zerolog.Nop(), store, sink, outbox.Config{PollPeriod: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.records[0].delivered
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
+
+func TestCleanerClean(t *testing.T) {
+	store := &memoryStore{}
+	for range 4 {
+		store.add("balance.changed")
+	}
+	now := time.Now()
+	// delivered long ago, delivered recently, never delivered and too old,
+	// never delivered and still kept
+	store.records[0].delivered, store.records[0].deliveredAt = true, now.Add(-2*time.Hour)
+	store.records[1].delivered, store.records[1].deliveredAt = true, now.Add(-time.Minute)
+	store.records[2].event.Created = now.Add(-25 * time.Hour)
+	store.records[3].event.Created = now.Add(-23 * time.Hour)
+	cleaner := outbox.NewCleaner(zerolog.Nop(), store, outbox.Config{Retention: time.Hour, UndeliveredRetention: 24 * time.Hour})
+
+	deleted, err := cleaner.Clean(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.Equal(t, []int64{2, 4}, store.ids())
+}
+
+func TestCleanerRun_DeletesUntilContextDone(t *testing.T) {
+	store := &memoryStore{}
+	store.add("balance.changed")
+	store.records[0].event.Created = time.Now().Add(-time.Hour)
+	cleaner := outbox.NewCleaner(zerolog.Nop(), store, outbox.Config{UndeliveredRetention: time.Minute, CleanupPeriod: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		cleaner.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return len(store.ids()) == 0 }, time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cleaner did not stop")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var headers http.Header
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sink := outbox.NewWebhookSink(server.URL, time.Second)
+	event := outbox.Event{ID: 7, Type: "balance.changed", AggregateID: uuid.New(), Payload: []byte(`{}`)}
+
+	assert.NoError(t, sink.Publish(context.Background(), event))
+	assert.Equal(t, "7", headers.Get(outbox.EventIDHeader))
+	assert.Equal(t, "balance.changed", headers.Get(outbox.EventTypeHeader))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, sink.Publish(context.Background(), event))
+}
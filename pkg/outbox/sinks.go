@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	EventIDHeader   = "X-Event-Id"
+	EventTypeHeader = "X-Event-Type"
+)
+
+// message is how an event is seen by the sinks that write json.
+type message struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregateId"`
+	Created     time.Time       `json:"created"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Encode returns the json the sinks publish for the event.
+func Encode(event Event) ([]byte, error) {
+	return json.Marshal(message{
+		ID:          event.ID,
+		Type:        event.Type,
+		AggregateID: event.AggregateID.String(),
+		Created:     event.Created,
+		Payload:     event.Payload,
+	})
+}
+
+// WriterSink writes every event as a json line, e.g. to stdout or a file.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink appends the events to the file at path.
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink(file), nil
+}
+
+func (s *WriterSink) Publish(_ context.Context, event Event) error {
+	body, err := Encode(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(body, '\n'))
+	return err
+}
+
+// WebhookSink posts every event to a fixed url, any status but 2xx is a
+// failed delivery.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	body, err := Encode(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(EventTypeHeader, event.Type)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Publisher is the part of a message broker client (NATS, Kafka) the relay
+// needs. Key keeps the events of one aggregate in order where the broker
+// supports it.
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, value []byte) error
+}
+
+// BrokerSink publishes every event to one topic of a broker.
+type BrokerSink struct {
+	publisher Publisher
+	topic     string
+}
+
+func NewBrokerSink(publisher Publisher, topic string) *BrokerSink {
+	return &BrokerSink{publisher: publisher, topic: topic}
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event Event) error {
+	body, err := Encode(event)
+	if err != nil {
+		return err
+	}
+	return s.publisher.Publish(ctx, s.topic, event.AggregateID.String(), body)
+}
+
+// MemoryBroker is an in-process Publisher for tests and local runs.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages map[string][][]byte
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{messages: map[string][][]byte{}}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic, _ string, value []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages[topic] = append(b.messages[topic], value)
+	return nil
+}
+
+// Messages returns a copy of everything published to topic.
+func (b *MemoryBroker) Messages(topic string) [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]byte(nil), b.messages[topic]...)
+}
+
+// MultiSink publishes every event to all of the sinks. An event is retried
+// as a whole when any of them fails, so the others may see it twice.
+type MultiSink []Sink
+
+func (s MultiSink) Publish(ctx context.Context, event Event) error {
+	for _, sink := range s {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSink builds the sink named by cfg.Type, stdout when it is empty.
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "", SinkStdout:
+		return NewWriterSink(os.Stdout), nil
+	case SinkFile:
+		return NewFileSink(cfg.File)
+	case SinkWebhook:
+		if cfg.WebhookURL == "" {
+			return nil, errors.New("outbox webhook url is empty")
+		}
+		return NewWebhookSink(cfg.WebhookURL, cfg.Timeout), nil
+	case SinkMemory:
+		return NewBrokerSink(NewMemoryBroker(), cfg.Topic), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Type)
+	}
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import "time"
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	defaultSampleRatio   = 1
+	defaultExportTimeout = 10 * time.Second
+)
+
+// Config of the tracing. The spans are sent to Endpoint by OTLP over http,
+// e.g. localhost:4318, or written to stdout. SampleRatio of the traces
+// started here are kept, the traces continued from a traceparent follow the
+// decision of the caller.
+type Config struct {
+	Enabled     bool          `yaml:"enabled"`
+	Exporter    string        `yaml:"exporter"`
+	Endpoint    string        `yaml:"endpoint"`
+	Insecure    bool          `yaml:"insecure"`
+	Timeout     time.Duration `yaml:"timeout"`
+	SampleRatio float64       `yaml:"sample_ratio"`
+	ServiceName string        `yaml:"service_name"`
+}
@@ -0,0 +1,98 @@
+// Package tracing sets up OpenTelemetry: the W3C trace context propagation
+// and the export of the spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation = "wallet-api"
+
+// Tracer of the api, it follows the provider installed by Setup.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Start starts a span of the api.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End ends span, recording err when it is set.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Setup installs the propagator and, when the tracing is enabled, the
+// provider exporting the spans. The returned function flushes the spans left
+// and stops the export.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := NewExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	provider := NewTracerProvider(cfg, exporter)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func NewExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultExportTimeout
+		}
+		opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(timeout)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// NewTracerProvider batches the spans to exporter, tests pass an in-memory
+// one.
+func NewTracerProvider(cfg Config, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = defaultSampleRatio
+	}
+	name := cfg.ServiceName
+	if name == "" {
+		name = instrumentation
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String(string(semconv.ServiceNameKey), name))),
+	)
+}
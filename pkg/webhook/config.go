@@ -0,0 +1,31 @@
+package webhook
+
+import "time"
+
+const (
+	defaultPollPeriod  = time.Second
+	defaultBatchSize   = 50
+	defaultLease       = time.Minute
+	defaultRetryMin    = 5 * time.Second
+	defaultRetryMax    = time.Hour
+	defaultMaxAttempts = 10
+	defaultTimeout     = 10 * time.Second
+)
+
+// Config of the delivery worker. A delivery that failed MaxAttempts times is
+// moved to the dead-letter state and is sent again only when replayed.
+// SecretKey encrypts the subscription secrets. AllowPrivateNetworks lets the
+// subscriptions point to internal addresses, for local runs only.
+type Config struct {
+	Enabled     bool          `yaml:"enabled"`
+	PollPeriod  time.Duration `yaml:"poll_period"`
+	BatchSize   int           `yaml:"batch_size"`
+	Lease       time.Duration `yaml:"lease"`
+	RetryMin    time.Duration `yaml:"retry_min"`
+	RetryMax    time.Duration `yaml:"retry_max"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	Timeout     time.Duration `yaml:"timeout"`
+
+	SecretKey            string `yaml:"secret_key"`
+	AllowPrivateNetworks bool   `yaml:"allow_private_networks"`
+}
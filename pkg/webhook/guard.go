@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for webhook urls that point to loopback,
+// private, link-local (cloud metadata included) or other internal addresses.
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// forbiddenPrefixes are the internal ranges netip has no predicate for.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// AllowedAddr reports whether deliveries may be sent to addr.
+func AllowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL resolves the host of rawURL and returns ErrForbiddenAddress when
+// any of its addresses is not allowed. Nothing is checked with
+// AllowPrivateNetworks. The worker checks the address again when it dials,
+// the name may resolve differently by then.
+func (c Config) CheckURL(ctx context.Context, rawURL string) error {
+	if c.AllowPrivateNetworks {
+		return nil
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !AllowedAddr(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// newClient returns the client of the worker. Unless AllowPrivateNetworks,
+// it refuses to connect to the addresses that are not allowed, whatever the
+// name resolved to and wherever a redirect points. Environment proxies are
+// not used, the check would apply to the proxy instead of the receiver.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = dialControl
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}
+}
+
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !AllowedAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
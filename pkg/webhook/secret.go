@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+const secretKeyBytes = 32
+
+var ErrInvalidSecretKey = errors.New("webhook secret key must be 32 hex encoded bytes")
+
+// Secrets encrypts the subscription secrets at rest with AES-256-GCM. They
+// sign the deliveries, so they are encrypted instead of hashed.
+type Secrets struct {
+	aead cipher.AEAD
+}
+
+// NewSecrets takes the hex encoded 32 byte key.
+func NewSecrets(key string) (*Secrets, error) {
+	raw, err := hex.DecodeString(key)
+	if err != nil || len(raw) != secretKeyBytes {
+		return nil, ErrInvalidSecretKey
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Secrets{aead: aead}, nil
+}
+
+// Seal returns the base64 of the random nonce followed by the encrypted
+// secret.
+func (s *Secrets) Seal(secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (s *Secrets) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < s.aead.NonceSize() {
+		return "", errors.New("sealed webhook secret is too short")
+	}
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	DeliveryHeader  = "X-Webhook-Id"
+	EventIDHeader   = "X-Event-Id"
+	EventTypeHeader = "X-Event-Type"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrSignatureExpired = errors.New("webhook signature expired")
+)
+
+// Sign returns the signature header value for body sent at timestamp:
+// "t=<unix seconds>,v1=<hex hmac-sha256 of "<t>.<body>">". The timestamp is
+// signed too, so a captured delivery cannot be replayed later.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks the signature header of a received delivery, a signature
+// older than tolerance is refused.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	signature, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(signature, mac(secret, t, body)) {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(unix, 0)) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(timestamp))
+	hash.Write([]byte{'.'})
+	hash.Write(body)
+	return hash.Sum(nil)
+}
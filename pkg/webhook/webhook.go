@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/outbox"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Delivery is one event to be sent to one subscription.
+type Delivery struct {
+	ID        uuid.UUID
+	URL       string
+	Secret    string
+	EventID   int64
+	EventType string
+	Payload   []byte
+	Attempts  int
+}
+
+// Store keeps the subscriptions and their deliveries.
+type Store interface {
+	// Enqueue creates a delivery of the event for every active subscription
+	// to its type. Enqueuing the same event twice creates nothing new.
+	Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) error
+	// Claim returns up to limit deliveries due to be sent and hides them from
+	// other workers for lease.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, status int) error
+	MarkFailed(ctx context.Context, id uuid.UUID, status int, reason string, retryIn time.Duration) error
+	MarkDead(ctx context.Context, id uuid.UUID, status int, reason string) error
+}
+
+// Sink is the outbox sink that turns events into webhook deliveries.
+type Sink struct {
+	store Store
+}
+
+func NewSink(store Store) *Sink {
+	return &Sink{store: store}
+}
+
+func (s *Sink) Publish(ctx context.Context, event outbox.Event) error {
+	body, err := outbox.Encode(event)
+	if err != nil {
+		return err
+	}
+	return s.store.Enqueue(ctx, event.ID, event.Type, body)
+}
+
+// Worker sends the deliveries signed with the subscription secret. A failed
+// delivery is retried with exponential backoff until MaxAttempts.
+type Worker struct {
+	logger zerolog.Logger
+	store  Store
+	client *http.Client
+	config Config
+}
+
+func NewWorker(log zerolog.Logger, store Store, cfg Config) *Worker {
+	if cfg.PollPeriod <= 0 {
+		cfg.PollPeriod = defaultPollPeriod
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultLease
+	}
+	if cfg.RetryMin <= 0 {
+		cfg.RetryMin = defaultRetryMin
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = defaultRetryMax
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Worker{
+		logger: logger.WithModule(log, "webhook_worker"),
+		store:  store,
+		client: newClient(cfg),
+		config: cfg,
+	}
+}
+
+// Run sends deliveries until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				sent, err := w.DeliverBatch(ctx)
+				if err != nil {
+					w.logger.Error().Err(err).Msg("deliver webhooks")
+					break
+				}
+				if sent < w.config.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverBatch sends one batch of due deliveries and returns how many were
+// claimed.
+func (w *Worker) DeliverBatch(ctx context.Context) (int, error) {
+	deliveries, err := w.store.Claim(ctx, w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, delivery := range deliveries {
+		status, err := w.send(ctx, delivery)
+		switch {
+		case err == nil:
+			err = w.store.MarkDelivered(ctx, delivery.ID, status)
+		case delivery.Attempts+1 >= w.config.MaxAttempts:
+			w.logger.Warn().Err(err).Stringer("delivery", delivery.ID).Msg("webhook delivery is dead")
+			err = w.store.MarkDead(ctx, delivery.ID, status, err.Error())
+		default:
+			retryIn := w.backoff(delivery.Attempts)
+			w.logger.Debug().Err(err).Stringer("delivery", delivery.ID).Dur("retry_in", retryIn).Msg("webhook delivery failed")
+			err = w.store.MarkFailed(ctx, delivery.ID, status, err.Error(), retryIn)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// send posts the delivery and returns the response status, zero when there
+// was no response.
+func (w *Worker) send(ctx context.Context, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, time.Now(), delivery.Payload))
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.config.RetryMin
+	for i := 0; i < attempts && delay < w.config.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, w.config.RetryMax)
+}
@@ -0,0 +1,273 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wallet-api/pkg/outbox"
+	"wallet-api/pkg/webhook"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryDelivery struct {
+	delivery webhook.Delivery
+	state    string
+	status   int
+	reason   string
+	retryIn  time.Duration
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	url        string
+	secret     string
+	deliveries []*memoryDelivery
+}
+
+func (s *memoryStore) Enqueue(_ context.Context, eventID int64, eventType string, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.delivery.EventID == eventID {
+			return nil
+		}
+	}
+	s.deliveries = append(s.deliveries, &memoryDelivery{state: "pending", delivery: webhook.Delivery{
+		ID:        uuid.New(),
+		URL:       s.url,
+		Secret:    s.secret,
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   payload,
+	}})
+	return nil
+}
+
+// Claim returns every pending delivery that is not waiting for a retry.
+func (s *memoryStore) Claim(_ context.Context, limit int, _ time.Duration) ([]webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []webhook.Delivery
+	for _, d := range s.deliveries {
+		if d.state == "pending" && d.retryIn == 0 && len(deliveries) < limit {
+			deliveries = append(deliveries, d.delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) find(id uuid.UUID) *memoryDelivery {
+	for _, d := range s.deliveries {
+		if d.delivery.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkDelivered(_ context.Context, id uuid.UUID, status int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.find(id)
+	d.delivery.Attempts++
+	d.state, d.status = "delivered", status
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id uuid.UUID, status int, reason string, retryIn time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.find(id)
+	d.delivery.Attempts++
+	d.status, d.reason, d.retryIn = status, reason, retryIn
+	return nil
+}
+
+func (s *memoryStore) MarkDead(_ context.Context, id uuid.UUID, status int, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.find(id)
+	d.delivery.Attempts++
+	d.state, d.status = "dead", status
+	return nil
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+// start serves the statuses in order, the last one is repeated.
+func (rc *receiver) start(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.requests = append(rc.requests, r)
+		rc.bodies = append(rc.bodies, body)
+		status := rc.statuses[0]
+		if len(rc.statuses) > 1 {
+			rc.statuses = rc.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func enqueue(t *testing.T, store *memoryStore, id int64) {
+	event := outbox.Event{ID: id, Type: "balance.changed", AggregateID: uuid.New(), Payload: []byte(`{"amount":100}`)}
+	assert.NoError(t, webhook.NewSink(store).Publish(context.Background(), event))
+}
+
+func TestWorker_DeliversSigned(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusNoContent}}
+	store := &memoryStore{url: rc.start(t).URL, secret: "secret"}
+	enqueue(t, store, 1)
+	enqueue(t, store, 1)
+	worker := webhook.NewWorker(zerolog.Nop(), store, webhook.Config{AllowPrivateNetworks: true})
+
+	sent, err := worker.DeliverBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, rc.requests, 1)
+	req, body := rc.requests[0], rc.bodies[0]
+	assert.Equal(t, store.deliveries[0].delivery.ID.String(), req.Header.Get(webhook.DeliveryHeader))
+	assert.Equal(t, "1", req.Header.Get(webhook.EventIDHeader))
+	assert.Equal(t, "balance.changed", req.Header.Get(webhook.EventTypeHeader))
+	assert.NoError(t, webhook.Verify("secret", req.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()))
+	assert.ErrorIs(t, webhook.Verify("other", req.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()), webhook.ErrInvalidSignature)
+	assert.Equal(t, "delivered", store.deliveries[0].state)
+	assert.Equal(t, http.StatusNoContent, store.deliveries[0].status)
+}
+
+func TestWorker_BackoffAndDeadLetter(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	store := &memoryStore{url: rc.start(t).URL, secret: "secret"}
+	enqueue(t, store, 1)
+	worker := webhook.NewWorker(zerolog.Nop(), store, webhook.Config{AllowPrivateNetworks: true, RetryMin: time.Second, RetryMax: 3 * time.Second, MaxAttempts: 4})
+	d := store.deliveries[0]
+
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		d.retryIn = 0
+		_, err := worker.DeliverBatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", d.state)
+		assert.Equal(t, expected, d.retryIn)
+		assert.Equal(t, http.StatusInternalServerError, d.status)
+	}
+
+	d.retryIn = 0
+	_, err := worker.DeliverBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "dead", d.state)
+	assert.Equal(t, 4, d.delivery.Attempts)
+	assert.Len(t, rc.requests, 4)
+
+	sent, err := worker.DeliverBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestWorker_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	store := &memoryStore{url: server.URL, secret: "secret"}
+	server.Close()
+	enqueue(t, store, 1)
+	worker := webhook.NewWorker(zerolog.Nop(), store, webhook.Config{AllowPrivateNetworks: true})
+
+	_, err := worker.DeliverBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", store.deliveries[0].state)
+	assert.Equal(t, 0, store.deliveries[0].status)
+	assert.Equal(t, 1, store.deliveries[0].delivery.Attempts)
+}
+
+func TestWorker_ForbiddenAddress(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusNoContent}}
+	store := &memoryStore{url: rc.start(t).URL, secret: "secret"}
+	enqueue(t, store, 1)
+	worker := webhook.NewWorker(zerolog.Nop(), store, webhook.Config{})
+
+	_, err := worker.DeliverBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, rc.requests)
+	assert.Equal(t, "pending", store.deliveries[0].state)
+	assert.Contains(t, store.deliveries[0].reason, webhook.ErrForbiddenAddress.Error())
+}
+
+func TestAllowedAddr(t *testing.T) {
+	for addr, allowed := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"224.0.0.1":              false,
+	} {
+		assert.Equal(t, allowed, webhook.AllowedAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestConfig_CheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, webhook.Config{}.CheckURL(ctx, "http://127.0.0.1:8080/hook"), webhook.ErrForbiddenAddress)
+	assert.ErrorIs(t, webhook.Config{}.CheckURL(ctx, "http://[::1]/hook"), webhook.ErrForbiddenAddress)
+	assert.ErrorIs(t, webhook.Config{}.CheckURL(ctx, "http://169.254.169.254/latest/meta-data"), webhook.ErrForbiddenAddress)
+	assert.NoError(t, webhook.Config{}.CheckURL(ctx, "https://93.184.216.34/hook"))
+	assert.NoError(t, webhook.Config{AllowPrivateNetworks: true}.CheckURL(ctx, "http://127.0.0.1:8080/hook"))
+}
+
+func TestSecrets(t *testing.T) {
+	secrets, err := webhook.NewSecrets(strings.Repeat("ab", 32))
+	assert.NoError(t, err)
+
+	sealed, err := secrets.Seal("s3cret")
+	assert.NoError(t, err)
+	assert.NotContains(t, sealed, "s3cret")
+	opened, err := secrets.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", opened)
+
+	other, err := webhook.NewSecrets(strings.Repeat("cd", 32))
+	assert.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = webhook.NewSecrets("short")
+	assert.ErrorIs(t, err, webhook.ErrInvalidSecretKey)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	sent := time.Unix(1700000000, 0)
+	header := webhook.Sign("secret", sent, body)
+
+	assert.NoError(t, webhook.Verify("secret", header, body, time.Minute, sent.Add(30*time.Second)))
+	assert.ErrorIs(t, webhook.Verify("secret", header, body, time.Minute, sent.Add(2*time.Minute)), webhook.ErrSignatureExpired)
+	assert.ErrorIs(t, webhook.Verify("secret", header, []byte(`{"id":2}`), time.Minute, sent), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", "garbage", body, time.Minute, sent), webhook.ErrInvalidSignature)
+}
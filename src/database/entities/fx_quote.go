@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FXQuote struct {
+	ID              uuid.UUID
+	FromWalletID    uuid.UUID
+	ToWalletID      uuid.UUID
+	FromCurrency    string
+	ToCurrency      string
+	Amount          int64
+	ConvertedAmount int64
+	Rate            string
+	Rounding        string
+	Created         time.Time
+	Expires         time.Time
+	Executed        *time.Time
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Hold_status_active   = "active"
+	Hold_status_captured = "captured"
+	Hold_status_voided   = "voided"
+	Hold_status_expired  = "expired"
+)
+
+// Hold reserves Amount of the wallet balance until it is captured, voided or
+// expires. Expired is never stored, an active hold past Expires reads as it.
+type Hold struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	Amount         int64
+	CapturedAmount *int64
+	Status         string
+	TransactionID  *uuid.UUID
+	Created        time.Time
+	Expires        time.Time
+	Updated        time.Time
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Event_type_wallet_created  = "wallet.created"
+	Event_type_balance_changed = "balance.changed"
+	Event_type_hold_expired    = "hold.expired"
+)
+
+// WalletCreated is the outbox payload of a new wallet.
+type WalletCreated struct {
+	WalletID uuid.UUID `json:"walletId"`
+	Currency string    `json:"currency"`
+	Created  time.Time `json:"created"`
+}
+
+// WalletEvents_channel is the channel notified of every outbox event, see
+// notify_wallet_event.
+const WalletEvents_channel = "wallet_events"
+
+// WalletEvent is an outbox event of a wallet as it is sent to the event
+// stream. Payload is json.
+type WalletEvent struct {
+	ID       int64
+	Type     string
+	WalletID uuid.UUID
+	Created  time.Time
+	Payload  []byte
+}
+
+// BalanceChanged is the outbox payload written with every ledger entry.
+type BalanceChanged struct {
+	TransactionID        uuid.UUID  `json:"transactionId"`
+	WalletID             uuid.UUID  `json:"walletId"`
+	Type                 string     `json:"type"`
+	Amount               int64      `json:"amount"`
+	BalanceAfter         int64      `json:"balanceAfter"`
+	CounterpartyWalletID *uuid.UUID `json:"counterpartyWalletId,omitempty"`
+	ReversalOf           *uuid.UUID `json:"reversalOf,omitempty"`
+	Created              time.Time  `json:"created"`
+}
+
+// HoldExpired is the outbox payload of a hold that passed Expires without
+// being captured or voided.
+type HoldExpired struct {
+	HoldID   uuid.UUID `json:"holdId"`
+	WalletID uuid.UUID `json:"walletId"`
+	Amount   int64     `json:"amount"`
+	Expires  time.Time `json:"expires"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SpendingLimits restrict withdrawals, outgoing transfers and hold captures
+// of the wallet. Nil limits are not applied, Updated is nil until the limits
+// are set for the first time.
+type SpendingLimits struct {
+	WalletID             uuid.UUID
+	MaxSingleWithdrawal  *int64
+	MaxDailyWithdrawal   *int64
+	MaxMonthlyWithdrawal *int64
+	MaxHourlyOperations  *int64
+	Updated              *time.Time
+}
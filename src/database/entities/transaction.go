@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Transaction_type_deposit      = "deposit"
+	Transaction_type_withdraw     = "withdraw"
+	Transaction_type_transfer_out = "transfer_out"
+	Transaction_type_transfer_in  = "transfer_in"
+	Transaction_type_capture      = "capture"
+	Transaction_type_reversal     = "reversal"
+)
+
+type Transaction struct {
+	ID                   uuid.UUID
+	WalletID             uuid.UUID
+	Type                 string
+	Amount               int64
+	BalanceAfter         int64
+	CounterpartyWalletID *uuid.UUID
+	FXQuoteID            *uuid.UUID
+	FXRate               *string
+	FXRounding           *string
+	ReversalOf           *uuid.UUID
+	Created              time.Time
+}
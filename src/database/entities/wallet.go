@@ -1,8 +1,60 @@
 package entities
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/google/uuid"
+)
+
+const (
+	Wallet_status_active = "active"
+	Wallet_status_frozen = "frozen"
+	Wallet_status_closed = "closed"
+)
+
+const Wallet_audit_action_limits = "change_limits"
+
+// Wallet balance may go down to MinBalance (zero when it is not set) minus
+// CreditLimit, active holds are counted as already spent. Owner is the
+// principal that created the wallet, it is empty for the wallets created
+// without authentication.
 type Wallet struct {
-	ID      uuid.UUID
-	Balance int64
+	ID          uuid.UUID
+	Balance     int64
+	Held        int64
+	CreditLimit int64
+	MinBalance  *int64
+	Currency    string
+	Status      string
+	Owner       *string
+	Created     time.Time
+	Updated     time.Time
+}
+
+// Available is the amount that can still be withdrawn from the wallet.
+func (w Wallet) Available() int64 {
+	var minBalance int64
+	if w.MinBalance != nil {
+		minBalance = *w.MinBalance
+	}
+	return w.Balance - w.Held - (minBalance - w.CreditLimit)
+}
+
+// WalletLimits are the per-wallet overdraft settings changed by admins.
+type WalletLimits struct {
+	CreditLimit int64  `json:"creditLimit"`
+	MinBalance  *int64 `json:"minBalance"`
+}
+
+// WalletAudit records who changed a wallet setting and why, OldValue and
+// NewValue are stored as json.
+type WalletAudit struct {
+	ID       uuid.UUID
+	WalletID uuid.UUID
+	Action   string
+	Actor    string
+	Reason   string
+	OldValue any
+	NewValue any
+	Created  time.Time
 }
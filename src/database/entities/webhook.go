@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Webhook_delivery_pending   = "pending"
+	Webhook_delivery_delivered = "delivered"
+	Webhook_delivery_dead      = "dead"
+)
+
+// WebhookSubscription has the Secret only when it is created, the secret is
+// stored encrypted and never read back.
+type WebhookSubscription struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes []string
+	Secret     string
+	Active     bool
+	Created    time.Time
+	Updated    time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int
+	NextAttempt    time.Time
+	LastStatus     *int
+	LastError      *string
+	Created        time.Time
+	Delivered      *time.Time
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+create table if not exists transactions (
+    id uuid default gen_random_uuid() primary key,
+    wallet_id uuid not null references wallet (id),
+    type text not null,
+    amount bigint not null,
+    balance_after bigint not null,
+    created timestamp not null default now()
+);
+
+create index if not exists transactions_wallet_id_created_idx on transactions (wallet_id, created);
\ No newline at end of file
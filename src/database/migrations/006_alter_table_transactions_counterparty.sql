@@ -0,0 +1,2 @@
+-- +goose Up
+alter table transactions add column if not exists counterparty_wallet_id uuid references wallet (id);
\ No newline at end of file
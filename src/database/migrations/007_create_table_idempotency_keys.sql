@@ -0,0 +1,11 @@
+-- +goose Up
+create table if not exists idempotency_keys (
+    key text primary key,
+    request_hash text not null,
+    status int not null default 0,
+    body bytea,
+    created timestamp not null default now(),
+    expires timestamp not null
+);
+
+create index if not exists idempotency_keys_expires_idx on idempotency_keys (expires);
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- +goose Up
+alter table wallet add column if not exists status text not null default 'active';
+alter table wallet add constraint wallet_status_check check (status in ('active', 'frozen', 'closed'));
\ No newline at end of file
@@ -0,0 +1,58 @@
+-- +goose up
+-- +goose statementbegin
+create or replace function withdraw_balance(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_balance bigint;
+    current_status text;
+begin
+    select balance, status into current_balance, current_status from wallet where id = w_id;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    if current_status = 'frozen' then
+        raise exception 'wallet with id % is frozen', w_id
+            using errcode = 'WA002';
+    end if;
+
+    if current_balance - w_amount < 0 then 
+        raise exception 'not enough balance';
+    end if;
+
+    update wallet set balance = balance - w_amount, updated = now(), last_operation = 'withdraw' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
+
+-- +goose statementbegin
+create or replace function deposit_balance(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_status text;
+begin
+    select status into current_status from wallet where id = w_id for update;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    update wallet set balance = balance + w_amount, updated = now(), last_operation = 'deposit' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- +goose Up
+create index if not exists wallet_created_id_idx on wallet (created, id);
+create index if not exists wallet_updated_id_idx on wallet (updated, id);
+create index if not exists wallet_balance_id_idx on wallet (balance, id);
\ No newline at end of file
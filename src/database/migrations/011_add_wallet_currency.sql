@@ -0,0 +1,3 @@
+-- +goose Up
+alter table wallet add column if not exists currency char(3) not null default 'RUB';
+alter table wallet add constraint wallet_currency_check check (currency ~ '^[A-Z]{3}$');
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+create table if not exists fx_quotes (
+    id uuid default gen_random_uuid() primary key,
+    from_wallet_id uuid not null references wallet (id),
+    to_wallet_id uuid not null references wallet (id),
+    from_currency char(3) not null,
+    to_currency char(3) not null,
+    amount bigint not null,
+    converted_amount bigint not null,
+    rate text not null,
+    rounding text not null,
+    created timestamp not null default now(),
+    expires timestamp not null,
+    executed timestamp
+);
+
+alter table transactions add column if not exists fx_quote_id uuid references fx_quotes (id);
+alter table transactions add column if not exists fx_rate text;
+alter table transactions add column if not exists fx_rounding text;
\ No newline at end of file
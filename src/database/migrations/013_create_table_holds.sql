@@ -0,0 +1,56 @@
+-- +goose Up
+create table if not exists holds (
+    id uuid default gen_random_uuid() primary key,
+    wallet_id uuid not null references wallet (id),
+    amount bigint not null check (amount > 0),
+    captured_amount bigint,
+    status text not null default 'active' check (status in ('active', 'captured', 'voided')),
+    transaction_id uuid references transactions (id),
+    created timestamp not null default now(),
+    expires timestamp not null,
+    updated timestamp not null default now()
+);
+
+create index if not exists holds_wallet_id_active_idx on holds (wallet_id, expires) where status = 'active';
+
+-- +goose statementbegin
+create or replace function wallet_held(w_id uuid)
+returns bigint as $$
+    select coalesce(sum(amount), 0)::bigint from holds
+    where wallet_id = w_id and status = 'active' and expires > now();
+$$ language sql stable;
+-- +goose statementend
+
+-- +goose statementbegin
+create or replace function withdraw_balance(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_balance bigint;
+    current_status text;
+begin
+    select balance, status into current_balance, current_status from wallet where id = w_id;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    if current_status = 'frozen' then
+        raise exception 'wallet with id % is frozen', w_id
+            using errcode = 'WA002';
+    end if;
+
+    if current_balance - wallet_held(w_id) - w_amount < 0 then 
+        raise exception 'not enough balance';
+    end if;
+
+    update wallet set balance = balance - w_amount, updated = now(), last_operation = 'withdraw' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
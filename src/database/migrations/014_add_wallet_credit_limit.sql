@@ -0,0 +1,55 @@
+-- +goose Up
+alter table wallet add column if not exists credit_limit bigint not null default 0;
+alter table wallet add column if not exists min_balance bigint;
+alter table wallet add constraint wallet_credit_limit_check check (credit_limit >= 0);
+
+create table if not exists wallet_audit (
+    id uuid default gen_random_uuid() primary key,
+    wallet_id uuid not null references wallet (id),
+    action text not null,
+    actor text not null,
+    reason text not null default '',
+    old_value jsonb not null,
+    new_value jsonb not null,
+    created timestamp not null default now()
+);
+
+create index if not exists wallet_audit_wallet_id_created_idx on wallet_audit (wallet_id, created);
+
+-- +goose statementbegin
+create or replace function withdraw_balance(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_balance bigint;
+    current_status text;
+    current_credit_limit bigint;
+    current_min_balance bigint;
+begin
+    select balance, status, credit_limit, min_balance
+    into current_balance, current_status, current_credit_limit, current_min_balance
+    from wallet where id = w_id;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    if current_status = 'frozen' then
+        raise exception 'wallet with id % is frozen', w_id
+            using errcode = 'WA002';
+    end if;
+
+    if current_balance - wallet_held(w_id) - w_amount < coalesce(current_min_balance, 0) - current_credit_limit then 
+        raise exception 'not enough balance';
+    end if;
+
+    update wallet set balance = balance - w_amount, updated = now(), last_operation = 'withdraw' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
@@ -0,0 +1,101 @@
+-- +goose Up
+create table if not exists wallet_spending_limits (
+    wallet_id uuid primary key references wallet (id),
+    max_single_withdrawal bigint check (max_single_withdrawal > 0),
+    max_daily_withdrawal bigint check (max_daily_withdrawal > 0),
+    max_monthly_withdrawal bigint check (max_monthly_withdrawal > 0),
+    max_hourly_operations bigint check (max_hourly_operations > 0),
+    updated timestamp not null default now()
+);
+
+-- +goose statementbegin
+create or replace function check_spending_limits(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    limits wallet_spending_limits%rowtype;
+    withdrawn bigint;
+    operations bigint;
+begin
+    select * into limits from wallet_spending_limits where wallet_id = w_id;
+
+    if not found then
+        return;
+    end if;
+
+    if w_amount > limits.max_single_withdrawal then
+        raise exception 'single withdrawal limit exceeded'
+            using errcode = 'WA003', detail = 'single';
+    end if;
+
+    if limits.max_daily_withdrawal is not null then
+        select coalesce(sum(amount), 0) into withdrawn from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created >= date_trunc('day', now());
+
+        if withdrawn + w_amount > limits.max_daily_withdrawal then
+            raise exception 'daily withdrawal limit exceeded'
+                using errcode = 'WA003', detail = 'daily';
+        end if;
+    end if;
+
+    if limits.max_monthly_withdrawal is not null then
+        select coalesce(sum(amount), 0) into withdrawn from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created >= date_trunc('month', now());
+
+        if withdrawn + w_amount > limits.max_monthly_withdrawal then
+            raise exception 'monthly withdrawal limit exceeded'
+                using errcode = 'WA003', detail = 'monthly';
+        end if;
+    end if;
+
+    if limits.max_hourly_operations is not null then
+        select count(*) into operations from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created > now() - interval '1 hour';
+
+        if operations >= limits.max_hourly_operations then
+            raise exception 'hourly operations limit exceeded'
+                using errcode = 'WA003', detail = 'hourly';
+        end if;
+    end if;
+end;
+$$ language plpgsql;
+-- +goose statementend
+
+-- +goose statementbegin
+create or replace function withdraw_balance(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_balance bigint;
+    current_status text;
+    current_credit_limit bigint;
+    current_min_balance bigint;
+begin
+    select balance, status, credit_limit, min_balance
+    into current_balance, current_status, current_credit_limit, current_min_balance
+    from wallet where id = w_id;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    if current_status = 'frozen' then
+        raise exception 'wallet with id % is frozen', w_id
+            using errcode = 'WA002';
+    end if;
+
+    perform check_spending_limits(w_id, w_amount);
+
+    if current_balance - wallet_held(w_id) - w_amount < coalesce(current_min_balance, 0) - current_credit_limit then 
+        raise exception 'not enough balance';
+    end if;
+
+    update wallet set balance = balance - w_amount, updated = now(), last_operation = 'withdraw' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
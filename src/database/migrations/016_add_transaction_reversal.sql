@@ -0,0 +1,42 @@
+-- +goose Up
+alter table transactions add column if not exists reversal_of uuid references transactions (id);
+
+create unique index if not exists transactions_reversal_of_idx on transactions (reversal_of) where reversal_of is not null;
+
+-- +goose statementbegin
+create or replace function debit_reversal(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    current_balance bigint;
+    current_status text;
+    current_credit_limit bigint;
+    current_min_balance bigint;
+begin
+    select balance, status, credit_limit, min_balance
+    into current_balance, current_status, current_credit_limit, current_min_balance
+    from wallet where id = w_id for update;
+
+    if not found then
+        raise exception 'wallet with id % not found', w_id
+            using errcode = 'P0002';
+    end if;
+
+    if current_status = 'closed' then
+        raise exception 'wallet with id % is closed', w_id
+            using errcode = 'WA001';
+    end if;
+
+    if current_status = 'frozen' then
+        raise exception 'wallet with id % is frozen', w_id
+            using errcode = 'WA002';
+    end if;
+
+    if current_balance - wallet_held(w_id) - w_amount < coalesce(current_min_balance, 0) - current_credit_limit then 
+        raise exception 'not enough balance';
+    end if;
+
+    update wallet set balance = balance - w_amount, updated = now(), last_operation = 'reversal' where id = w_id;
+    return;
+end;
+$$ language plpgsql;
+-- +goose statementend
@@ -0,0 +1,14 @@
+-- +goose Up
+create table if not exists outbox (
+    id bigserial primary key,
+    event_type text not null,
+    aggregate_id uuid not null,
+    payload jsonb not null,
+    created timestamp not null default now(),
+    attempts int not null default 0,
+    next_attempt timestamp not null default now(),
+    delivered timestamp,
+    last_error text
+);
+
+create index if not exists outbox_pending_idx on outbox (next_attempt) where delivered is null;
@@ -0,0 +1,34 @@
+-- +goose Up
+create table if not exists webhook_subscriptions (
+    id uuid default gen_random_uuid() primary key,
+    url text not null,
+    event_types text[] not null,
+    secret text not null,
+    active boolean not null default true,
+    created timestamp not null default now(),
+    updated timestamp not null default now()
+);
+
+create table if not exists webhook_deliveries (
+    id uuid default gen_random_uuid() primary key,
+    subscription_id uuid not null references webhook_subscriptions (id),
+    event_id bigint not null references outbox (id),
+    event_type text not null,
+    payload jsonb not null,
+    status text not null default 'pending' check (status in ('pending', 'delivered', 'dead')),
+    attempts int not null default 0,
+    next_attempt timestamp not null default now(),
+    last_status int,
+    last_error text,
+    created timestamp not null default now(),
+    delivered timestamp,
+    unique (subscription_id, event_id)
+);
+
+create index if not exists webhook_deliveries_pending_idx on webhook_deliveries (next_attempt) where status = 'pending';
+
+create index if not exists webhook_deliveries_subscription_idx on webhook_deliveries (subscription_id, created);
+
+alter table holds add column if not exists expiry_notified timestamp;
+
+create index if not exists holds_expiry_notified_idx on holds (expires) where status = 'active' and expiry_notified is null;
@@ -0,0 +1,23 @@
+-- +goose Up
+create index if not exists outbox_aggregate_id_idx on outbox (aggregate_id, id);
+
+-- +goose statementbegin
+create or replace function notify_wallet_event()
+returns trigger as $$
+begin
+    perform pg_notify('wallet_events', json_build_object(
+        'id', new.id,
+        'type', new.event_type,
+        'walletId', new.aggregate_id,
+        'created', new.created at time zone 'UTC',
+        'payload', new.payload
+    )::text);
+    return new;
+end;
+$$ language plpgsql;
+-- +goose statementend
+
+drop trigger if exists outbox_notify_wallet_event on outbox;
+
+create trigger outbox_notify_wallet_event after insert on outbox
+for each row execute function notify_wallet_event();
@@ -0,0 +1,13 @@
+-- +goose Up
+create table if not exists api_keys (
+    id uuid default gen_random_uuid() primary key,
+    principal text not null,
+    key_hash text not null unique,
+    scopes text[] not null default '{}',
+    created timestamp not null default now(),
+    revoked timestamp
+);
+
+alter table wallet add column if not exists owner text;
+
+create index if not exists wallet_owner_idx on wallet (owner);
@@ -0,0 +1,9 @@
+-- +goose Up
+create unlogged table if not exists rate_limit_buckets (
+    key text primary key,
+    tokens double precision not null,
+    allowed boolean not null,
+    updated timestamp not null default now()
+);
+
+create index if not exists rate_limit_buckets_updated_idx on rate_limit_buckets (updated);
@@ -0,0 +1,58 @@
+-- +goose Up
+-- pgx reads timestamps as UTC, the default has to write them so in any session
+alter table transactions alter column created set default (now() at time zone 'utc');
+
+-- +goose statementbegin
+create or replace function check_spending_limits(w_id uuid, w_amount bigint)
+returns void as $$
+declare
+    limits wallet_spending_limits%rowtype;
+    withdrawn bigint;
+    operations bigint;
+    -- created is a timestamp in UTC, days and months start at midnight UTC
+    -- whatever the time zone of the session is
+    utc_now timestamp := now() at time zone 'utc';
+begin
+    select * into limits from wallet_spending_limits where wallet_id = w_id;
+
+    if not found then
+        return;
+    end if;
+
+    if w_amount > limits.max_single_withdrawal then
+        raise exception 'single withdrawal limit exceeded'
+            using errcode = 'WA003', detail = 'single';
+    end if;
+
+    if limits.max_daily_withdrawal is not null then
+        select coalesce(sum(amount), 0) into withdrawn from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created >= date_trunc('day', utc_now);
+
+        if withdrawn + w_amount > limits.max_daily_withdrawal then
+            raise exception 'daily withdrawal limit exceeded'
+                using errcode = 'WA003', detail = 'daily';
+        end if;
+    end if;
+
+    if limits.max_monthly_withdrawal is not null then
+        select coalesce(sum(amount), 0) into withdrawn from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created >= date_trunc('month', utc_now);
+
+        if withdrawn + w_amount > limits.max_monthly_withdrawal then
+            raise exception 'monthly withdrawal limit exceeded'
+                using errcode = 'WA003', detail = 'monthly';
+        end if;
+    end if;
+
+    if limits.max_hourly_operations is not null then
+        select count(*) into operations from transactions
+        where wallet_id = w_id and type in ('withdraw', 'transfer_out', 'capture') and created > utc_now - interval '1 hour';
+
+        if operations >= limits.max_hourly_operations then
+            raise exception 'hourly operations limit exceeded'
+                using errcode = 'WA003', detail = 'hourly';
+        end if;
+    end if;
+end;
+$$ language plpgsql;
+-- +goose statementend
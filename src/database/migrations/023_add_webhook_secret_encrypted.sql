@@ -0,0 +1,3 @@
+-- +goose Up
+-- secrets created before stay in plain text until their subscription is recreated
+alter table webhook_subscriptions add column if not exists secret_encrypted boolean not null default false;
\ No newline at end of file
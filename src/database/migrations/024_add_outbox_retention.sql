@@ -0,0 +1,5 @@
+-- +goose Up
+-- webhook deliveries keep their own payload, delivered events may be deleted
+alter table webhook_deliveries drop constraint if exists webhook_deliveries_event_id_fkey;
+
+create index if not exists outbox_delivered_idx on outbox (delivered) where delivered is not null;
\ No newline at end of file
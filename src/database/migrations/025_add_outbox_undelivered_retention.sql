@@ -0,0 +1,2 @@
+-- +goose Up
+create index if not exists outbox_undelivered_created_idx on outbox (created) where delivered is null;
\ No newline at end of file
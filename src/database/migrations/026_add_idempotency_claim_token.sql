@@ -0,0 +1,3 @@
+-- +goose Up
+-- a request outliving its lease saves nothing once another one took the key
+alter table idempotency_keys add column if not exists claim_token text;
\ No newline at end of file
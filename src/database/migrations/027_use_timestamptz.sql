@@ -0,0 +1,21 @@
+-- +goose Up
+-- only transactions.created is written in UTC, the other tables follow the
+-- session time zone; timestamptz keeps them comparable in any session. The
+-- existing values are read in the time zone of the migration session, the
+-- one they were written in
+alter table holds
+    alter column created type timestamptz,
+    alter column expires type timestamptz,
+    alter column updated type timestamptz,
+    alter column expiry_notified type timestamptz;
+
+alter table wallet_audit alter column created type timestamptz;
+
+alter table outbox
+    alter column created type timestamptz,
+    alter column next_attempt type timestamptz,
+    alter column delivered type timestamptz;
+
+alter table idempotency_keys
+    alter column created type timestamptz,
+    alter column expires type timestamptz;
\ No newline at end of file
@@ -1,10 +1,14 @@
 package migrations
 
 import (
+	"context"
 	"embed"
 	"fmt"
+	"io/fs"
 	"wallet-api/config"
+	"wallet-api/pkg/database"
 	"wallet-api/pkg/logger"
+	"wallet-api/src/database/queries"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -12,6 +16,8 @@ import (
 	glog "go.finelli.dev/gooseloggers/zerolog"
 )
 
+const tableName = "vehicles_api_migrations"
+
 //go:embed *.sql
 var embedMigrations embed.FS
 
@@ -37,7 +43,7 @@ func (m *Migrator) migrate() error {
 		return fmt.Errorf("failed to open db: %w", err)
 	}
 
-	goose.SetTableName("vehicles_api_migrations")
+	goose.SetTableName(tableName)
 	goose.SetBaseFS(embedMigrations)
 	log := logger.WithModule(m.logger, "migrations")
 	goose.SetLogger(glog.GooseZerologLogger(&log))
@@ -49,3 +55,42 @@ func (m *Migrator) migrate() error {
 
 	return nil
 }
+
+// LatestVersion is the version of the last embedded migration.
+func LatestVersion() (int64, error) {
+	names, err := fs.Glob(embedMigrations, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, name := range names {
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
+
+// CheckVersion fails while the database is not migrated to LatestVersion,
+// e.g. when another instance is still migrating it.
+func CheckVersion(ctx context.Context, pool database.ConnectionPool) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	connection, err := pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	var version int64
+	if err = connection.QueryRow(ctx, queries.FindMigrationVersion).Scan(&version); err != nil {
+		return err
+	}
+	if version != latest {
+		return fmt.Errorf("migration version %d, expected %d", version, latest)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package migrations_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"wallet-api/config"
+	"wallet-api/pkg/database"
+	"wallet-api/src/database/migrations"
+	"wallet-api/src/database/queries"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// envTestDB is the connection string of a disposable database, the tests
+// needing postgres are skipped without it.
+const envTestDB = "PG_WALLET_TEST"
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testDB(t *testing.T) string {
+	connectionString := os.Getenv(envTestDB)
+	if connectionString == "" {
+		t.Skip(envTestDB + " is not set")
+	}
+	migrations.NewMigrator(zerolog.Nop(), config.DB{WalletDB: database.ConnectionConfig{ConnectionString: connectionString}}).Migrate()
+	return connectionString
+}
+
+func TestSpendingLimitsUTCDay(t *testing.T) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, testDB(t))
+	must(t, err)
+	defer conn.Close(ctx)
+	tx, err := conn.Begin(ctx)
+	must(t, err)
+	defer tx.Rollback(ctx)
+
+	// the local day of UTC+14 starts 14 hours before the UTC one
+	_, err = tx.Exec(ctx, "set local time zone 'Pacific/Kiritimati'")
+	must(t, err)
+	var walletID string
+	must(t, tx.QueryRow(ctx, "insert into wallet (balance, currency) values (1000, 'RUB') returning id").Scan(&walletID))
+	_, err = tx.Exec(ctx, "insert into wallet_spending_limits (wallet_id, max_daily_withdrawal) values ($1, 100)", walletID)
+	must(t, err)
+
+	withdraw := func(created string) {
+		_, err := tx.Exec(ctx, "insert into transactions (wallet_id, type, amount, balance_after, created) values ($1, 'withdraw', 80, 0, "+created+")", walletID)
+		must(t, err)
+	}
+	check := func() error {
+		_, err := tx.Exec(ctx, "savepoint check_limits")
+		must(t, err)
+		_, checkErr := tx.Exec(ctx, "select check_spending_limits($1, 50)", walletID)
+		_, err = tx.Exec(ctx, "rollback to savepoint check_limits")
+		must(t, err)
+		return checkErr
+	}
+
+	withdraw("date_trunc('day', now() at time zone 'utc') - interval '1 minute'")
+	assert.NoError(t, check(), "the day before in UTC")
+
+	withdraw("date_trunc('day', now() at time zone 'utc') + interval '1 minute'")
+	assert.ErrorContains(t, check(), "daily withdrawal limit exceeded")
+
+	var defaultIsUTC bool
+	must(t, tx.QueryRow(ctx, "insert into transactions (wallet_id, type, amount, balance_after) values ($1, 'deposit', 1, 0) returning abs(extract(epoch from created - now() at time zone 'utc')) < 60", walletID).Scan(&defaultIsUTC))
+	assert.True(t, defaultIsUTC, "created is written in UTC")
+}
+
+func TestDeleteExpiredOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, testDB(t))
+	must(t, err)
+	defer conn.Close(ctx)
+	tx, err := conn.Begin(ctx)
+	must(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "delete from outbox")
+	must(t, err)
+
+	insert := func(created, delivered string) (id int64) {
+		must(t, tx.QueryRow(ctx, "insert into outbox (event_type, aggregate_id, payload, created, delivered) values ('balance.changed', gen_random_uuid(), '{}', "+created+", "+delivered+") returning id").Scan(&id))
+		return id
+	}
+	insert("now() - interval '3 hours'", "now() - interval '2 hours'")
+	deliveredRecently := insert("now() - interval '3 hours'", "now() - interval '1 minute'")
+	insert("now() - interval '25 hours'", "null")
+	undelivered := insert("now() - interval '23 hours'", "null")
+
+	tag, err := tx.Exec(ctx, queries.DeleteExpiredOutboxEvents, time.Hour.Seconds(), (24 * time.Hour).Seconds())
+	must(t, err)
+	assert.Equal(t, int64(2), tag.RowsAffected())
+
+	rows, err := tx.Query(ctx, "select id from outbox order by id")
+	must(t, err)
+	kept, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	must(t, err)
+	assert.Equal(t, []int64{deliveredRecently, undelivered}, kept)
+}
+
+func TestTimestampsKeepTimeZone(t *testing.T) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, testDB(t))
+	must(t, err)
+	defer conn.Close(ctx)
+
+	rows, err := conn.Query(ctx, `select table_name || '.' || column_name from information_schema.columns
+		where table_name in ('holds', 'wallet_audit', 'outbox', 'idempotency_keys') and data_type = 'timestamp without time zone'
+		order by 1`)
+	must(t, err)
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	must(t, err)
+	assert.Empty(t, columns, "timestamps follow the session time zone")
+}
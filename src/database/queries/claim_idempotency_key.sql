@@ -0,0 +1,6 @@
+insert into idempotency_keys (key, claim_token, request_hash, expires)
+values ($1, $2, $3, now() + make_interval(secs => $4))
+on conflict (key) do update
+set claim_token = excluded.claim_token, request_hash = excluded.request_hash, status = 0, body = null, created = now(), expires = excluded.expires
+where idempotency_keys.expires < now()
+   or (idempotency_keys.status = 0 and idempotency_keys.created < now() - make_interval(secs => $5));
\ No newline at end of file
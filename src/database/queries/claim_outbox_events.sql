@@ -0,0 +1,9 @@
+update outbox set next_attempt = now() + make_interval(secs => $2)
+where id in (
+    select id from outbox
+    where delivered is null and next_attempt <= now()
+    order by id
+    limit $1
+    for update skip locked
+)
+returning id, event_type, aggregate_id, payload, created, attempts;
\ No newline at end of file
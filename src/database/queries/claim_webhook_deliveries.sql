@@ -0,0 +1,11 @@
+update webhook_deliveries d set next_attempt = now() + make_interval(secs => $2)
+from webhook_subscriptions s
+where s.id = d.subscription_id and d.id in (
+    select wd.id from webhook_deliveries wd
+    join webhook_subscriptions ws on ws.id = wd.subscription_id
+    where wd.status = 'pending' and wd.next_attempt <= now() and ws.active
+    order by wd.next_attempt
+    limit $1
+    for update of wd skip locked
+)
+returning d.id, s.url, s.secret, s.secret_encrypted, d.event_id, d.event_type, d.payload, d.attempts;
\ No newline at end of file
@@ -0,0 +1 @@
+update idempotency_keys set status = $3, body = $4 where key = $1 and claim_token = $2 and status = 0;
\ No newline at end of file
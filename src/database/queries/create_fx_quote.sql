@@ -0,0 +1,3 @@
+insert into fx_quotes (from_wallet_id, to_wallet_id, from_currency, to_currency, amount, converted_amount, rate, rounding, expires)
+values ($1, $2, $3, $4, $5, $6, $7, $8, now() + make_interval(secs => $9))
+returning id, from_wallet_id, to_wallet_id, from_currency, to_currency, amount, converted_amount, rate, rounding, created, expires, executed;
\ No newline at end of file
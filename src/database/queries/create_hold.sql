@@ -0,0 +1,3 @@
+insert into holds (wallet_id, amount, expires)
+values ($1, $2, now() + make_interval(secs => $3))
+returning id, wallet_id, amount, captured_amount, case when status = 'active' and expires <= now() then 'expired' else status end, transaction_id, created, expires, updated;
\ No newline at end of file
@@ -0,0 +1 @@
+insert into wallet (balance, currency, owner) values (0, $1, $2) returning id, balance, 0::bigint, credit_limit, min_balance, currency, status, owner, created, updated;
\ No newline at end of file
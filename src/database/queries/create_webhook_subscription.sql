@@ -0,0 +1,3 @@
+insert into webhook_subscriptions (url, event_types, secret, secret_encrypted)
+values ($1, $2, $3, true)
+returning id, url, event_types, active, created, updated;
\ No newline at end of file
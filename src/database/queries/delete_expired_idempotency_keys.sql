@@ -0,0 +1 @@
+delete from idempotency_keys where expires < now();
\ No newline at end of file
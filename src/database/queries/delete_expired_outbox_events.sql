@@ -0,0 +1 @@
+delete from outbox where delivered < now() - make_interval(secs => $1) or (delivered is null and created < now() - make_interval(secs => $2));
\ No newline at end of file
@@ -0,0 +1 @@
+delete from idempotency_keys where key = $1 and claim_token = $2;
\ No newline at end of file
@@ -0,0 +1 @@
+delete from rate_limit_buckets where updated < now() - make_interval(secs => $1);
\ No newline at end of file
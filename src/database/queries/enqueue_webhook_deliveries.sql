@@ -0,0 +1,4 @@
+insert into webhook_deliveries (subscription_id, event_id, event_type, payload)
+select id, $1, $2, $3 from webhook_subscriptions
+where active and $2 = any(event_types)
+on conflict (subscription_id, event_id) do nothing;
\ No newline at end of file
@@ -0,0 +1 @@
+update fx_quotes set executed = now() where id = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+select principal, scopes from api_keys where key_hash = $1 and revoked is null;
\ No newline at end of file
@@ -0,0 +1,2 @@
+select id, from_wallet_id, to_wallet_id, from_currency, to_currency, amount, converted_amount, rate, rounding, created, expires, executed
+from fx_quotes where id = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+select from_wallet_id from fx_quotes where id = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+select request_hash, status, body from idempotency_keys where key = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+select coalesce(max(version_id), 0) from vehicles_api_migrations where is_applied;
\ No newline at end of file
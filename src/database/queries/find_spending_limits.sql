@@ -0,0 +1,3 @@
+select w.id, l.max_single_withdrawal, l.max_daily_withdrawal, l.max_monthly_withdrawal, l.max_hourly_operations, l.updated
+from wallet w left join wallet_spending_limits l on l.wallet_id = w.id
+where w.id = $1;
\ No newline at end of file
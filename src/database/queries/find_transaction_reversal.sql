@@ -0,0 +1 @@
+select exists (select 1 from transactions where reversal_of = any($1));
\ No newline at end of file
@@ -1 +1 @@
-select balance from wallet where id = $1;
\ No newline at end of file
+select id, balance, wallet_held(id), credit_limit, min_balance, currency, status, owner, created, updated from wallet where id = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+select id, event_type, aggregate_id, created, payload from outbox
+where aggregate_id = $1 and id > $2
+order by id
+limit $3;
\ No newline at end of file
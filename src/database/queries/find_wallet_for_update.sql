@@ -0,0 +1 @@
+select id, balance, wallet_held(id), credit_limit, min_balance, currency, status, owner, created, updated from wallet where id = $1 for update;
\ No newline at end of file
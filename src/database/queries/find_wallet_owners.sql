@@ -0,0 +1 @@
+select id, owner from wallet where id = any($1);
\ No newline at end of file
@@ -0,0 +1,4 @@
+select id, subscription_id, event_id, event_type, status, attempts, next_attempt, last_status, last_error, created, delivered from webhook_deliveries
+where subscription_id = $1 and ($2::text is null or status = $2)
+order by created desc, id
+limit $3;
\ No newline at end of file
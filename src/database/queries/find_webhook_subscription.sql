@@ -0,0 +1 @@
+select id, url, event_types, active, created, updated from webhook_subscriptions where id = $1;
\ No newline at end of file
@@ -0,0 +1 @@
+select id, url, event_types, active, created, updated from webhook_subscriptions order by created, id;
\ No newline at end of file
@@ -0,0 +1,8 @@
+select id, wallet_id, type, amount, balance_after, counterparty_wallet_id, fx_quote_id, fx_rate, fx_rounding, reversal_of, created from transactions
+where wallet_id = $1
+  and ($2::timestamp is null or created >= $2)
+  and ($3::timestamp is null or created < $3)
+  and ($4::text[] is null or type = any($4))
+  and ($5::text is null or (created, id) %[1]s ($5::timestamp, $6::uuid))
+order by created %[2]s, id %[2]s
+limit $7;
\ No newline at end of file
@@ -1 +1,7 @@
-select id from wallet;
\ No newline at end of file
+select id, balance, wallet_held(id), credit_limit, min_balance, currency, status, owner, created, updated from wallet
+where ($1::text is null or status = $1)
+  and ($2::bigint is null or balance >= $2)
+  and ($3::bigint is null or balance <= $3)
+  and ($4::text is null or (%[1]s, id) %[3]s ($4::%[2]s, $5::uuid))
+order by %[1]s %[4]s, id %[4]s
+limit $6;
\ No newline at end of file
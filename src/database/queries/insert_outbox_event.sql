@@ -0,0 +1,2 @@
+insert into outbox (event_type, aggregate_id, payload)
+values ($1, $2, $3);
\ No newline at end of file
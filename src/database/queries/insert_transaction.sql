@@ -0,0 +1,3 @@
+insert into transactions (wallet_id, type, amount, balance_after, counterparty_wallet_id, fx_quote_id, fx_rate, fx_rounding, reversal_of)
+select id, $2::text, $3::bigint, balance, $4::uuid, $5::uuid, $6::text, $7::text, $8::uuid from wallet where id = $1
+returning id, wallet_id, type, amount, balance_after, counterparty_wallet_id, fx_quote_id, fx_rate, fx_rounding, reversal_of, created;
\ No newline at end of file
@@ -0,0 +1,2 @@
+insert into wallet_audit (wallet_id, action, actor, reason, old_value, new_value)
+values ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
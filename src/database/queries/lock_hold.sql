@@ -0,0 +1 @@
+select id, wallet_id, amount, captured_amount, case when status = 'active' and expires <= now() then 'expired' else status end, transaction_id, created, expires, updated from holds where id = $1 for update;
\ No newline at end of file
@@ -0,0 +1 @@
+select id, wallet_id, type, amount, balance_after, counterparty_wallet_id, fx_quote_id, fx_rate, fx_rounding, reversal_of, created from transactions where id = $1 for update;
\ No newline at end of file
@@ -0,0 +1,3 @@
+select id, wallet_id, type, amount, balance_after, counterparty_wallet_id, fx_quote_id, fx_rate, fx_rounding, reversal_of, created from transactions
+where wallet_id = $1 and counterparty_wallet_id = $2 and type = $3 and created = $4 and fx_quote_id is not distinct from $5
+limit 1 for update;
\ No newline at end of file
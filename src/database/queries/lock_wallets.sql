@@ -0,0 +1 @@
+select id, currency from wallet where id in ($1, $2) order by id for update;
\ No newline at end of file
@@ -0,0 +1 @@
+select id, currency from wallet where id = any($1) order by id for update;
\ No newline at end of file
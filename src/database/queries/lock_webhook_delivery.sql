@@ -0,0 +1 @@
+select id, subscription_id, event_id, event_type, status, attempts, next_attempt, last_status, last_error, created, delivered from webhook_deliveries where id = $1 for update;
\ No newline at end of file
@@ -0,0 +1,2 @@
+update outbox set delivered = now(), last_error = null
+where id = any($1);
\ No newline at end of file
@@ -0,0 +1,2 @@
+update outbox set attempts = attempts + 1, last_error = $2, next_attempt = now() + make_interval(secs => $3)
+where id = $1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+update webhook_deliveries set status = 'delivered', attempts = attempts + 1, last_status = $2, last_error = null, delivered = now()
+where id = $1;
\ No newline at end of file
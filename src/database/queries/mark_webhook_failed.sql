@@ -0,0 +1,2 @@
+update webhook_deliveries set attempts = attempts + 1, last_status = $2, last_error = $3, next_attempt = now() + make_interval(secs => $4)
+where id = $1;
\ No newline at end of file
@@ -0,0 +1,9 @@
+update holds set expiry_notified = now()
+where id in (
+    select id from holds
+    where status = 'active' and expires <= now() and expiry_notified is null
+    order by expires
+    limit $1
+    for update skip locked
+)
+returning id, wallet_id, amount, captured_amount, 'expired'::text, transaction_id, created, expires, updated;
\ No newline at end of file
@@ -11,12 +11,174 @@ var UpdateDepositWallet string
 //go:embed update_withdraw_wallet.sql
 var UpdateWithdrawWallet string
 
+//go:embed lock_wallets.sql
+var LockWallets string
+
+//go:embed lock_wallets_batch.sql
+var LockWalletsBatch string
+
 //go:embed find_wallet.sql
 var FindWallet string
 
+//go:embed find_wallet_for_update.sql
+var FindWalletForUpdate string
+
+//go:embed create_wallet.sql
+var CreateWallet string
+
+//go:embed update_wallet_status.sql
+var UpdateWalletStatus string
+
+//go:embed update_wallet_limits.sql
+var UpdateWalletLimits string
+
+//go:embed insert_wallet_audit.sql
+var InsertWalletAudit string
+
+//go:embed find_spending_limits.sql
+var FindSpendingLimits string
+
+//go:embed upsert_spending_limits.sql
+var UpsertSpendingLimits string
+
 //go:embed get_wallets.sql
 var GetWallets string
 
+//go:embed insert_transaction.sql
+var InsertTransaction string
+
+//go:embed find_transaction.sql
+var FindTransaction string
+
+//go:embed get_wallet_transactions.sql
+var GetWalletTransactions string
+
+//go:embed lock_transaction.sql
+var LockTransaction string
+
+//go:embed lock_transfer_leg.sql
+var LockTransferLeg string
+
+//go:embed find_transaction_reversal.sql
+var FindTransactionReversal string
+
+//go:embed update_reversal_debit_wallet.sql
+var UpdateReversalDebitWallet string
+
+//go:embed claim_idempotency_key.sql
+var ClaimIdempotencyKey string
+
+//go:embed find_idempotency_key.sql
+var FindIdempotencyKey string
+
+//go:embed complete_idempotency_key.sql
+var CompleteIdempotencyKey string
+
+//go:embed delete_idempotency_key.sql
+var DeleteIdempotencyKey string
+
+//go:embed delete_expired_idempotency_keys.sql
+var DeleteExpiredIdempotencyKeys string
+
+//go:embed create_fx_quote.sql
+var CreateFXQuote string
+
+//go:embed find_fx_quote.sql
+var FindFXQuote string
+
+//go:embed lock_fx_quote.sql
+var LockFXQuote string
+
+//go:embed execute_fx_quote.sql
+var ExecuteFXQuote string
+
+//go:embed create_hold.sql
+var CreateHold string
+
+//go:embed find_hold.sql
+var FindHold string
+
+//go:embed lock_hold.sql
+var LockHold string
+
+//go:embed update_hold_status.sql
+var UpdateHoldStatus string
+
+//go:embed insert_outbox_event.sql
+var InsertOutboxEvent string
+
+//go:embed claim_outbox_events.sql
+var ClaimOutboxEvents string
+
+//go:embed mark_outbox_delivered.sql
+var MarkOutboxDelivered string
+
+//go:embed mark_outbox_failed.sql
+var MarkOutboxFailed string
+
+//go:embed delete_expired_outbox_events.sql
+var DeleteExpiredOutboxEvents string
+
+//go:embed create_webhook_subscription.sql
+var CreateWebhookSubscription string
+
+//go:embed find_webhook_subscription.sql
+var FindWebhookSubscription string
+
+//go:embed find_webhook_subscriptions.sql
+var FindWebhookSubscriptions string
+
+//go:embed update_webhook_subscription_active.sql
+var UpdateWebhookSubscriptionActive string
+
+//go:embed enqueue_webhook_deliveries.sql
+var EnqueueWebhookDeliveries string
+
+//go:embed claim_webhook_deliveries.sql
+var ClaimWebhookDeliveries string
+
+//go:embed mark_webhook_delivered.sql
+var MarkWebhookDelivered string
+
+//go:embed mark_webhook_failed.sql
+var MarkWebhookFailed string
+
+//go:embed mark_webhook_dead.sql
+var MarkWebhookDead string
+
+//go:embed find_webhook_deliveries.sql
+var FindWebhookDeliveries string
+
+//go:embed lock_webhook_delivery.sql
+var LockWebhookDelivery string
+
+//go:embed replay_webhook_delivery.sql
+var ReplayWebhookDelivery string
+
+//go:embed notify_expired_holds.sql
+var NotifyExpiredHolds string
+
+//go:embed find_wallet_events.sql
+var FindWalletEvents string
+
+//go:embed find_api_key.sql
+var FindAPIKey string
+
+//go:embed find_wallet_owners.sql
+var FindWalletOwners string
+
+//go:embed find_fx_quote_wallet.sql
+var FindFXQuoteWallet string
+
+//go:embed take_rate_limit_token.sql
+var TakeRateLimitToken string
+
+//go:embed delete_idle_rate_limit_buckets.sql
+var DeleteIdleRateLimitBuckets string
+
+//go:embed find_migration_version.sql
+var FindMigrationVersion string
+
 func ToExpectQuery(query string) string {
 	query = strings.ReplaceAll(query, "$", "[$]")
 	query = strings.ReplaceAll(query, "(", "\\(")
@@ -0,0 +1,3 @@
+update webhook_deliveries set status = 'pending', attempts = 0, next_attempt = now(), delivered = null
+where id = $1
+returning id, subscription_id, event_id, event_type, status, attempts, next_attempt, last_status, last_error, created, delivered;
\ No newline at end of file
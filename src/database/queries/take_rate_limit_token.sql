@@ -0,0 +1,9 @@
+insert into rate_limit_buckets as bucket (key, tokens, allowed, updated)
+select distinct key, $3::double precision - 1, true, now() from unnest($1::text[]) as key
+order by key
+on conflict (key) do update
+set tokens = least($3::double precision, bucket.tokens + extract(epoch from now() - bucket.updated)::double precision * $2::double precision)
+        - case when least($3::double precision, bucket.tokens + extract(epoch from now() - bucket.updated)::double precision * $2::double precision) >= 1 then 1 else 0 end,
+    allowed = least($3::double precision, bucket.tokens + extract(epoch from now() - bucket.updated)::double precision * $2::double precision) >= 1,
+    updated = now()
+returning allowed, tokens;
\ No newline at end of file
@@ -1,2 +1 @@
-select balance from wallet where id = $1 for update;
-update wallet set balance = balance + $2, updated = now(), last_operation = 'deposit' where id = $1;
\ No newline at end of file
+select deposit_balance($1, $2);
\ No newline at end of file
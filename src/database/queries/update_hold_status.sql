@@ -0,0 +1,3 @@
+update holds set status = $2, captured_amount = $3, transaction_id = $4, updated = now()
+where id = $1
+returning id, wallet_id, amount, captured_amount, case when status = 'active' and expires <= now() then 'expired' else status end, transaction_id, created, expires, updated;
\ No newline at end of file
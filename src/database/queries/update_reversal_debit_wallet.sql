@@ -0,0 +1 @@
+select debit_reversal($1, $2);
\ No newline at end of file
@@ -0,0 +1,2 @@
+update wallet set credit_limit = $2, min_balance = $3, updated = now() where id = $1
+returning id, balance, wallet_held(id), credit_limit, min_balance, currency, status, owner, created, updated;
\ No newline at end of file
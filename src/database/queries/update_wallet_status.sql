@@ -0,0 +1 @@
+update wallet set status = $2, updated = now() where id = $1 returning id, balance, wallet_held(id), credit_limit, min_balance, currency, status, owner, created, updated;
\ No newline at end of file
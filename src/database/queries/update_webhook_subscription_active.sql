@@ -0,0 +1,3 @@
+update webhook_subscriptions set active = $2, updated = now()
+where id = $1
+returning id, url, event_types, active, created, updated;
\ No newline at end of file
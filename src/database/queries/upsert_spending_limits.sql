@@ -0,0 +1,9 @@
+insert into wallet_spending_limits (wallet_id, max_single_withdrawal, max_daily_withdrawal, max_monthly_withdrawal, max_hourly_operations)
+values ($1, $2, $3, $4, $5)
+on conflict (wallet_id) do update
+set max_single_withdrawal = excluded.max_single_withdrawal,
+    max_daily_withdrawal = excluded.max_daily_withdrawal,
+    max_monthly_withdrawal = excluded.max_monthly_withdrawal,
+    max_hourly_operations = excluded.max_hourly_operations,
+    updated = now()
+returning wallet_id, max_single_withdrawal, max_daily_withdrawal, max_monthly_withdrawal, max_hourly_operations, updated;
\ No newline at end of file
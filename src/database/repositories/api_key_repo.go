@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/queries"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	apiKeyModule = "repo_api_key"
+)
+
+type apiKeyRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewAPIKeyRepo(pool database.ConnectionPool, log zerolog.Logger) auth.APIKeyStore {
+	return &apiKeyRepository{pool: pool, log: logger.WithModule(log, apiKeyModule)}
+}
+
+func (r *apiKeyRepository) FindAPIKey(ctx context.Context, hash string) (auth.Principal, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	defer connection.Release()
+	var principal auth.Principal
+	if err = connection.QueryRow(ctx, queries.FindAPIKey, hash).Scan(&principal.ID, &principal.Scopes); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.Principal{}, auth.ErrAPIKeyNotFound
+		}
+		return auth.Principal{}, err
+	}
+	return principal, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	fxQuoteModule      = "repo_fx_quote"
+	ErrFXQuoteNotFound = errors.New("fx quote not found")
+	ErrFXQuoteExpired  = errors.New("fx quote expired")
+	ErrFXQuoteExecuted = errors.New("fx quote already executed")
+)
+
+type FXQuoteRepo interface {
+	Create(ctx context.Context, quote entities.FXQuote, ttl time.Duration) (entities.FXQuote, error)
+	FindByID(ctx context.Context, id uuid.UUID) (entities.FXQuote, error)
+}
+
+type fxQuoteRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewFXQuoteRepo(pool database.ConnectionPool, log zerolog.Logger) FXQuoteRepo {
+	return &fxQuoteRepository{pool: pool, log: logger.WithModule(log, fxQuoteModule)}
+}
+
+// Create stores the quote, it can be executed until ttl passes.
+func (r *fxQuoteRepository) Create(ctx context.Context, quote entities.FXQuote, ttl time.Duration) (entities.FXQuote, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.FXQuote{}, err
+	}
+	defer connection.Release()
+	return scanFXQuote(connection.QueryRow(ctx, queries.CreateFXQuote,
+		quote.FromWalletID,
+		quote.ToWalletID,
+		quote.FromCurrency,
+		quote.ToCurrency,
+		quote.Amount,
+		quote.ConvertedAmount,
+		quote.Rate,
+		quote.Rounding,
+		ttl.Seconds(),
+	))
+}
+
+func (r *fxQuoteRepository) FindByID(ctx context.Context, id uuid.UUID) (entities.FXQuote, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.FXQuote{}, err
+	}
+	defer connection.Release()
+	quote, err := scanFXQuote(connection.QueryRow(ctx, queries.FindFXQuote, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.FXQuote{}, ErrFXQuoteNotFound
+		}
+		return entities.FXQuote{}, err
+	}
+	return quote, nil
+}
+
+// lockFXQuote locks the quote for execution inside tx and checks it can
+// still be executed.
+func lockFXQuote(ctx context.Context, tx pgx.Tx, id uuid.UUID) (entities.FXQuote, error) {
+	var (
+		quote   entities.FXQuote
+		expired bool
+	)
+	err := tx.QueryRow(ctx, queries.LockFXQuote, id).Scan(
+		&quote.ID,
+		&quote.FromWalletID,
+		&quote.ToWalletID,
+		&quote.FromCurrency,
+		&quote.ToCurrency,
+		&quote.Amount,
+		&quote.ConvertedAmount,
+		&quote.Rate,
+		&quote.Rounding,
+		&quote.Created,
+		&quote.Expires,
+		&quote.Executed,
+		&expired,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.FXQuote{}, ErrFXQuoteNotFound
+		}
+		return entities.FXQuote{}, err
+	}
+	if quote.Executed != nil {
+		return entities.FXQuote{}, ErrFXQuoteExecuted
+	}
+	if expired {
+		return entities.FXQuote{}, ErrFXQuoteExpired
+	}
+	return quote, nil
+}
+
+func scanFXQuote(row pgx.Row) (entities.FXQuote, error) {
+	var quote entities.FXQuote
+	err := row.Scan(
+		&quote.ID,
+		&quote.FromWalletID,
+		&quote.ToWalletID,
+		&quote.FromCurrency,
+		&quote.ToCurrency,
+		&quote.Amount,
+		&quote.ConvertedAmount,
+		&quote.Rate,
+		&quote.Rounding,
+		&quote.Created,
+		&quote.Expires,
+		&quote.Executed,
+	)
+	return quote, err
+}
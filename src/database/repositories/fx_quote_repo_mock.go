@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type FXQuoteRepoMock struct {
+	mock.Mock
+}
+
+func NewFXQuoteRepoMock() *FXQuoteRepoMock {
+	return &FXQuoteRepoMock{}
+}
+
+func (m *FXQuoteRepoMock) Create(ctx context.Context, quote entities.FXQuote, ttl time.Duration) (entities.FXQuote, error) {
+	args := m.Called(ctx, quote, ttl)
+	return args.Get(0).(entities.FXQuote), args.Error(1)
+}
+
+func (m *FXQuoteRepoMock) FindByID(ctx context.Context, id uuid.UUID) (entities.FXQuote, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.FXQuote), args.Error(1)
+}
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	holdModule                = "repo_hold"
+	ErrHoldNotFound           = errors.New("hold not found")
+	ErrHoldExpired            = errors.New("hold expired")
+	ErrHoldNotActive          = errors.New("hold is already captured or voided")
+	ErrHoldCaptureExceedsHold = errors.New("capture amount exceeds hold amount")
+)
+
+type HoldRepo interface {
+	Create(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (entities.Hold, error)
+	FindByID(ctx context.Context, id uuid.UUID) (entities.Hold, error)
+	Capture(ctx context.Context, id uuid.UUID, amount *int64) (entities.Hold, error)
+	Void(ctx context.Context, id uuid.UUID) (entities.Hold, error)
+	NotifyExpired(ctx context.Context, limit int) (int, error)
+}
+
+type holdRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewHoldRepo(pool database.ConnectionPool, log zerolog.Logger) HoldRepo {
+	return &holdRepository{pool: pool, log: logger.WithModule(log, holdModule)}
+}
+
+// Create reserves amount of the available balance of the wallet until ttl
+// passes.
+func (r *holdRepository) Create(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (entities.Hold, error) {
+	var hold entities.Hold
+	err := retrySerializable(logger.FromContext(ctx, r.log), "create hold", func() error {
+		var err error
+		hold, err = r.createTx(ctx, walletID, amount, ttl)
+		return err
+	})
+	return hold, err
+}
+
+func (r *holdRepository) FindByID(ctx context.Context, id uuid.UUID) (entities.Hold, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer connection.Release()
+	hold, err := scanHold(connection.QueryRow(ctx, queries.FindHold, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Hold{}, ErrHoldNotFound
+		}
+		return entities.Hold{}, err
+	}
+	return hold, nil
+}
+
+// Capture debits amount, or the whole hold when amount is nil, from the
+// wallet. The rest of a partially captured hold is released.
+func (r *holdRepository) Capture(ctx context.Context, id uuid.UUID, amount *int64) (entities.Hold, error) {
+	var hold entities.Hold
+	err := retrySerializable(logger.FromContext(ctx, r.log), "capture hold", func() error {
+		var err error
+		hold, err = r.captureTx(ctx, id, amount)
+		return err
+	})
+	return hold, err
+}
+
+func (r *holdRepository) Void(ctx context.Context, id uuid.UUID) (entities.Hold, error) {
+	var hold entities.Hold
+	err := retrySerializable(logger.FromContext(ctx, r.log), "void hold", func() error {
+		var err error
+		hold, err = r.voidTx(ctx, id)
+		return err
+	})
+	return hold, err
+}
+
+// NotifyExpired queues a hold.expired event for up to limit holds that
+// expired since the last call and returns how many there were.
+func (r *holdRepository) NotifyExpired(ctx context.Context, limit int) (int, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, queries.NotifyExpiredHolds, limit)
+	if err != nil {
+		return 0, err
+	}
+	var holds []entities.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		holds = append(holds, hold)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, hold := range holds {
+		event := entities.HoldExpired{HoldID: hold.ID, WalletID: hold.WalletID, Amount: hold.Amount, Expires: hold.Expires}
+		if err = insertOutboxEvent(ctx, tx, entities.Event_type_hold_expired, hold.WalletID, event); err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(holds), nil
+}
+
+func (r *holdRepository) createTx(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (entities.Hold, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer tx.Rollback(ctx)
+	wallet, err := scanWallet(tx.QueryRow(ctx, queries.FindWalletForUpdate, walletID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Hold{}, ErrWalletNotFound
+		}
+		return entities.Hold{}, err
+	}
+	switch {
+	case wallet.Status == entities.Wallet_status_closed:
+		return entities.Hold{}, ErrWalletClosed
+	case wallet.Status == entities.Wallet_status_frozen:
+		return entities.Hold{}, ErrWalletFrozen
+	case wallet.Available() < amount:
+		return entities.Hold{}, ErrWalletNotEnoughBalance
+	}
+	hold, err := scanHold(tx.QueryRow(ctx, queries.CreateHold, walletID, amount, ttl.Seconds()))
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Hold{}, err
+	}
+	return hold, nil
+}
+
+func (r *holdRepository) captureTx(ctx context.Context, id uuid.UUID, amount *int64) (entities.Hold, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer tx.Rollback(ctx)
+	hold, err := lockHold(ctx, tx, id)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	captured := hold.Amount
+	if amount != nil {
+		captured = *amount
+	}
+	if captured > hold.Amount {
+		return entities.Hold{}, ErrHoldCaptureExceedsHold
+	}
+	// the hold stops reserving the balance first, otherwise withdraw_balance
+	// would count it against the amount being debited
+	if _, err = tx.Exec(ctx, queries.UpdateHoldStatus, id, entities.Hold_status_captured, captured, nil); err != nil {
+		return entities.Hold{}, err
+	}
+	if _, err = tx.Exec(ctx, queries.UpdateWithdrawWallet, hold.WalletID, captured); err != nil {
+		return entities.Hold{}, mapBalanceError(err)
+	}
+	entry, err := insertTransaction(ctx, tx, entities.Transaction{
+		WalletID: hold.WalletID,
+		Type:     entities.Transaction_type_capture,
+		Amount:   captured,
+	})
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	hold, err = scanHold(tx.QueryRow(ctx, queries.UpdateHoldStatus, id, entities.Hold_status_captured, captured, entry.ID))
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Hold{}, err
+	}
+	return hold, nil
+}
+
+func (r *holdRepository) voidTx(ctx context.Context, id uuid.UUID) (entities.Hold, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err = lockHold(ctx, tx, id); err != nil {
+		return entities.Hold{}, err
+	}
+	hold, err := scanHold(tx.QueryRow(ctx, queries.UpdateHoldStatus, id, entities.Hold_status_voided, nil, nil))
+	if err != nil {
+		return entities.Hold{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Hold{}, err
+	}
+	return hold, nil
+}
+
+// lockHold locks the hold inside tx and checks it still reserves the balance.
+func lockHold(ctx context.Context, tx pgx.Tx, id uuid.UUID) (entities.Hold, error) {
+	hold, err := scanHold(tx.QueryRow(ctx, queries.LockHold, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Hold{}, ErrHoldNotFound
+		}
+		return entities.Hold{}, err
+	}
+	switch hold.Status {
+	case entities.Hold_status_active:
+		return hold, nil
+	case entities.Hold_status_expired:
+		return entities.Hold{}, ErrHoldExpired
+	default:
+		return entities.Hold{}, ErrHoldNotActive
+	}
+}
+
+func scanHold(row pgx.Row) (entities.Hold, error) {
+	var hold entities.Hold
+	err := row.Scan(
+		&hold.ID,
+		&hold.WalletID,
+		&hold.Amount,
+		&hold.CapturedAmount,
+		&hold.Status,
+		&hold.TransactionID,
+		&hold.Created,
+		&hold.Expires,
+		&hold.Updated,
+	)
+	return hold, err
+}
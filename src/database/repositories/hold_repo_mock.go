@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldRepoMock struct {
+	mock.Mock
+}
+
+func NewHoldRepoMock() *HoldRepoMock {
+	return &HoldRepoMock{}
+}
+
+func (m *HoldRepoMock) Create(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (entities.Hold, error) {
+	args := m.Called(ctx, walletID, amount, ttl)
+	return args.Get(0).(entities.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) FindByID(ctx context.Context, id uuid.UUID) (entities.Hold, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) Capture(ctx context.Context, id uuid.UUID, amount *int64) (entities.Hold, error) {
+	args := m.Called(ctx, id, amount)
+	return args.Get(0).(entities.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) Void(ctx context.Context, id uuid.UUID) (entities.Hold, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) NotifyExpired(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/queries"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	idempotencyModule         = "repo_idempotency"
+	ErrIdempotencyKeyNotFound = httpserver.ErrIdempotencyKeyNotFound
+)
+
+type IdempotencyRepo interface {
+	Claim(ctx context.Context, key, token, requestHash string, ttl, lease time.Duration) (bool, error)
+	Find(ctx context.Context, key string) (string, int, []byte, error)
+	Complete(ctx context.Context, key, token string, status int, body []byte) error
+	Release(ctx context.Context, key, token string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewIdempotencyRepo(pool database.ConnectionPool, log zerolog.Logger) IdempotencyRepo {
+	return &idempotencyRepository{pool: pool, log: logger.WithModule(log, idempotencyModule)}
+}
+
+// Claim reserves the key for a new request under token. It returns false when
+// the key is already taken by a request that has not expired yet, and is not
+// in progress for longer than lease.
+func (r *idempotencyRepository) Claim(ctx context.Context, key, token, requestHash string, ttl, lease time.Duration) (bool, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer connection.Release()
+	tag, err := connection.Exec(ctx, queries.ClaimIdempotencyKey, key, token, requestHash, ttl.Seconds(), lease.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Find returns the request hash, the stored response status and body for the
+// key. Status is zero while the original request is still in progress.
+func (r *idempotencyRepository) Find(ctx context.Context, key string) (string, int, []byte, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer connection.Release()
+	var (
+		requestHash string
+		status      int
+		body        []byte
+	)
+	err = connection.QueryRow(ctx, queries.FindIdempotencyKey, key).Scan(
+		&requestHash,
+		&status,
+		&body,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", 0, nil, ErrIdempotencyKeyNotFound
+		}
+		return "", 0, nil, err
+	}
+	return requestHash, status, body, nil
+}
+
+// Complete stores the response unless the claim of token was taken over.
+func (r *idempotencyRepository) Complete(ctx context.Context, key, token string, status int, body []byte) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	_, err = connection.Exec(ctx, queries.CompleteIdempotencyKey, key, token, status, body)
+	return err
+}
+
+// Release frees the key unless the claim of token was taken over.
+func (r *idempotencyRepository) Release(ctx context.Context, key, token string) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	_, err = connection.Exec(ctx, queries.DeleteIdempotencyKey, key, token)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Release()
+	tag, err := connection.Exec(ctx, queries.DeleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,310 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ledgerModule                  = "repo_ledger"
+	ErrTransactionNotFound        = errors.New("transaction not found")
+	ErrTransactionNotReversible   = errors.New("transaction can not be reversed")
+	ErrTransactionAlreadyReversed = errors.New("transaction is already reversed")
+	ErrReversalExceedsAmount      = errors.New("reversal amount exceeds transaction amount")
+	ErrPartialFXReversal          = errors.New("fx transfer can be reversed only in full")
+)
+
+type LedgerRepo interface {
+	FindByID(ctx context.Context, id uuid.UUID) (entities.Transaction, error)
+	FindByWalletID(ctx context.Context, filter TransactionsFilter) ([]entities.Transaction, error)
+	Reverse(ctx context.Context, id uuid.UUID, amount *int64) ([]entities.Transaction, error)
+}
+
+// TransactionsFilter selects a page of the wallet ledger in chronological
+// order. The page starts right after the entry identified by AfterValue (its
+// created time) and AfterID. Nil fields are not applied, To is exclusive.
+type TransactionsFilter struct {
+	WalletID   uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	Types      []string
+	Descending bool
+	AfterValue *string
+	AfterID    *uuid.UUID
+	Limit      int
+}
+
+type ledgerRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewLedgerRepo(pool database.ConnectionPool, log zerolog.Logger) LedgerRepo {
+	return &ledgerRepository{pool: pool, log: logger.WithModule(log, ledgerModule)}
+}
+
+func (r *ledgerRepository) FindByID(ctx context.Context, id uuid.UUID) (entities.Transaction, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Transaction{}, err
+	}
+	defer connection.Release()
+	transaction, err := scanTransaction(connection.QueryRow(ctx, queries.FindTransaction, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Transaction{}, ErrTransactionNotFound
+		}
+		return entities.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+func (r *ledgerRepository) FindByWalletID(ctx context.Context, filter TransactionsFilter) ([]entities.Transaction, error) {
+	var err error
+	comparison, direction := ">", "asc"
+	if filter.Descending {
+		comparison, direction = "<", "desc"
+	}
+	query := fmt.Sprintf(queries.GetWalletTransactions, comparison, direction)
+
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, query,
+		filter.WalletID,
+		filter.From,
+		filter.To,
+		filter.Types,
+		filter.AfterValue,
+		filter.AfterID,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var transactions []entities.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// Reverse writes compensating entries for the transaction, amount nil
+// reverses it in full. Both legs of a transfer are reversed together, so the
+// money returns to the sender. A transaction can be reversed only once.
+func (r *ledgerRepository) Reverse(ctx context.Context, id uuid.UUID, amount *int64) ([]entities.Transaction, error) {
+	var reversals []entities.Transaction
+	err := retrySerializable(logger.FromContext(ctx, r.log), "reverse transaction", func() error {
+		var err error
+		reversals, err = r.reverseTx(ctx, id, amount)
+		return err
+	})
+	return reversals, err
+}
+
+func (r *ledgerRepository) reverseTx(ctx context.Context, id uuid.UUID, amount *int64) ([]entities.Transaction, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	original, err := scanTransaction(tx.QueryRow(ctx, queries.LockTransaction, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	reversed := original.Amount
+	if amount != nil {
+		reversed = *amount
+	}
+	if reversed > original.Amount {
+		return nil, ErrReversalExceedsAmount
+	}
+
+	// the wallet of debit pays the reversal, the wallet of credit receives it
+	var debit, credit *entities.Transaction
+	debitAmount, creditAmount := reversed, reversed
+	switch original.Type {
+	case entities.Transaction_type_deposit:
+		debit = &original
+	case entities.Transaction_type_withdraw, entities.Transaction_type_capture:
+		credit = &original
+	case entities.Transaction_type_transfer_out, entities.Transaction_type_transfer_in:
+		counterpart, err := lockTransferLeg(ctx, tx, original)
+		if err != nil {
+			return nil, err
+		}
+		// legs of an fx transfer differ in amount, so it is reversed in full
+		counterpartAmount := reversed
+		if original.FXQuoteID != nil {
+			if reversed != original.Amount {
+				return nil, ErrPartialFXReversal
+			}
+			counterpartAmount = counterpart.Amount
+		}
+		if original.Type == entities.Transaction_type_transfer_out {
+			debit, credit = &counterpart, &original
+			debitAmount = counterpartAmount
+		} else {
+			debit, credit = &original, &counterpart
+			creditAmount = counterpartAmount
+		}
+		if _, err = lockWallets(ctx, tx, debit.WalletID, credit.WalletID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrTransactionNotReversible
+	}
+
+	legs := make([]uuid.UUID, 0, 2)
+	for _, leg := range []*entities.Transaction{debit, credit} {
+		if leg != nil {
+			legs = append(legs, leg.ID)
+		}
+	}
+	var alreadyReversed bool
+	if err = tx.QueryRow(ctx, queries.FindTransactionReversal, legs).Scan(&alreadyReversed); err != nil {
+		return nil, err
+	}
+	if alreadyReversed {
+		return nil, ErrTransactionAlreadyReversed
+	}
+
+	var reversals []entities.Transaction
+	if debit != nil {
+		if _, err = tx.Exec(ctx, queries.UpdateReversalDebitWallet, debit.WalletID, debitAmount); err != nil {
+			return nil, mapBalanceError(err)
+		}
+		entry, err := insertTransaction(ctx, tx, reversalEntry(*debit, debitAmount))
+		if err != nil {
+			return nil, err
+		}
+		reversals = append(reversals, entry)
+	}
+	if credit != nil {
+		if _, err = tx.Exec(ctx, queries.UpdateDepositWallet, credit.WalletID, creditAmount); err != nil {
+			return nil, mapBalanceError(err)
+		}
+		entry, err := insertTransaction(ctx, tx, reversalEntry(*credit, creditAmount))
+		if err != nil {
+			return nil, err
+		}
+		reversals = append(reversals, entry)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return reversals, nil
+}
+
+// lockTransferLeg finds the other ledger entry written by the same transfer.
+func lockTransferLeg(ctx context.Context, tx pgx.Tx, leg entities.Transaction) (entities.Transaction, error) {
+	if leg.CounterpartyWalletID == nil {
+		return entities.Transaction{}, ErrTransactionNotReversible
+	}
+	counterpartType := entities.Transaction_type_transfer_in
+	if leg.Type == entities.Transaction_type_transfer_in {
+		counterpartType = entities.Transaction_type_transfer_out
+	}
+	counterpart, err := scanTransaction(tx.QueryRow(ctx, queries.LockTransferLeg,
+		*leg.CounterpartyWalletID,
+		leg.WalletID,
+		counterpartType,
+		leg.Created,
+		leg.FXQuoteID,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Transaction{}, ErrTransactionNotReversible
+		}
+		return entities.Transaction{}, err
+	}
+	return counterpart, nil
+}
+
+func reversalEntry(original entities.Transaction, amount int64) entities.Transaction {
+	return entities.Transaction{
+		WalletID:             original.WalletID,
+		Type:                 entities.Transaction_type_reversal,
+		Amount:               amount,
+		CounterpartyWalletID: original.CounterpartyWalletID,
+		FXQuoteID:            original.FXQuoteID,
+		FXRate:               original.FXRate,
+		FXRounding:           original.FXRounding,
+		ReversalOf:           &original.ID,
+	}
+}
+
+// insertTransaction writes a ledger entry for the wallet inside the caller's
+// transaction, so the entry and the balance change are committed together.
+// balance_after is taken from the wallet row as it is seen by tx. The entry is
+// also queued to the outbox as a balance.changed event.
+func insertTransaction(ctx context.Context, tx pgx.Tx, entry entities.Transaction) (entities.Transaction, error) {
+	transaction, err := scanTransaction(tx.QueryRow(ctx, queries.InsertTransaction,
+		entry.WalletID,
+		entry.Type,
+		entry.Amount,
+		entry.CounterpartyWalletID,
+		entry.FXQuoteID,
+		entry.FXRate,
+		entry.FXRounding,
+		entry.ReversalOf,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Transaction{}, ErrNoRowsForUpdate
+		}
+		return entities.Transaction{}, err
+	}
+	if err = insertOutboxEvent(ctx, tx, entities.Event_type_balance_changed, transaction.WalletID, balanceChangedEvent(transaction)); err != nil {
+		return entities.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+func scanTransaction(row pgx.Row) (entities.Transaction, error) {
+	var transaction entities.Transaction
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.WalletID,
+		&transaction.Type,
+		&transaction.Amount,
+		&transaction.BalanceAfter,
+		&transaction.CounterpartyWalletID,
+		&transaction.FXQuoteID,
+		&transaction.FXRate,
+		&transaction.FXRounding,
+		&transaction.ReversalOf,
+		&transaction.Created,
+	)
+	return transaction, err
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerRepoMock struct {
+	mock.Mock
+}
+
+func NewLedgerRepoMock() *LedgerRepoMock {
+	return &LedgerRepoMock{}
+}
+
+func (m *LedgerRepoMock) FindByID(ctx context.Context, id uuid.UUID) (entities.Transaction, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Transaction), args.Error(1)
+}
+
+func (m *LedgerRepoMock) FindByWalletID(ctx context.Context, filter TransactionsFilter) ([]entities.Transaction, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]entities.Transaction), args.Error(1)
+}
+
+func (m *LedgerRepoMock) Reverse(ctx context.Context, id uuid.UUID, amount *int64) ([]entities.Transaction, error) {
+	args := m.Called(ctx, id, amount)
+	return args.Get(0).([]entities.Transaction), args.Error(1)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	balanceOutcomeSuccess           = "success"
+	balanceOutcomeNotFound          = "not_found"
+	balanceOutcomeInsufficientFunds = "insufficient_funds"
+	balanceOutcomeRejected          = "rejected"
+	balanceOutcomeError             = "error"
+)
+
+var (
+	balanceOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_balance_operations_total",
+		Help: "Deposits and withdrawals by their outcome.",
+	}, []string{"operation", "outcome"})
+	serializationRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_serialization_retries_total",
+		Help: "Transactions repeated after a serialization failure.",
+	}, []string{"operation"})
+	serializationBackoff = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_serialization_backoff_seconds_total",
+		Help: "Time slept before repeating transactions after a serialization failure.",
+	}, []string{"operation"})
+)
+
+// observeBalanceOperation counts the outcome of a deposit or a withdrawal.
+// Closed and frozen wallets, spending limits and overflows are rejected.
+func observeBalanceOperation(operation string, err error) {
+	outcome := balanceOutcomeError
+	switch {
+	case err == nil:
+		outcome = balanceOutcomeSuccess
+	case errors.Is(err, ErrNoRowsForUpdate), errors.Is(err, ErrWalletNotFound):
+		outcome = balanceOutcomeNotFound
+	case errors.Is(err, ErrWalletNotEnoughBalance):
+		outcome = balanceOutcomeInsufficientFunds
+	case errors.Is(err, ErrWalletClosed), errors.Is(err, ErrWalletFrozen),
+		errors.Is(err, ErrSpendingLimitExceeded), errors.Is(err, ErrBalanceOverflow):
+		outcome = balanceOutcomeRejected
+	}
+	balanceOperations.WithLabelValues(operation, outcome).Inc()
+}
+
+func observeSerializationRetry(operation string, backoff time.Duration) {
+	serializationRetries.WithLabelValues(operation).Inc()
+	serializationBackoff.WithLabelValues(operation).Add(backoff.Seconds())
+}
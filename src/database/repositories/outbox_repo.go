@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/outbox"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	outboxModule = "repo_outbox"
+)
+
+type outboxRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewOutboxRepo(pool database.ConnectionPool, log zerolog.Logger) outbox.Store {
+	return &outboxRepository{pool: pool, log: logger.WithModule(log, outboxModule)}
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.ClaimOutboxEvents, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []outbox.Event
+	for rows.Next() {
+		var event outbox.Event
+		if err = rows.Scan(&event.ID, &event.Type, &event.AggregateID, &event.Payload, &event.Created, &event.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, ids []int64) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	_, err = connection.Exec(ctx, queries.MarkOutboxDelivered, ids)
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryIn time.Duration) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	_, err = connection.Exec(ctx, queries.MarkOutboxFailed, id, reason, retryIn.Seconds())
+	return err
+}
+
+func (r *outboxRepository) DeleteExpired(ctx context.Context, retention, undeliveredRetention time.Duration) (int64, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Release()
+	tag, err := connection.Exec(ctx, queries.DeleteExpiredOutboxEvents, retention.Seconds(), undeliveredRetention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// insertOutboxEvent queues an event inside the caller's transaction, it is
+// published only if the transaction commits.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, aggregateID uuid.UUID, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, queries.InsertOutboxEvent, eventType, aggregateID, body)
+	return err
+}
+
+func balanceChangedEvent(transaction entities.Transaction) entities.BalanceChanged {
+	return entities.BalanceChanged{
+		TransactionID:        transaction.ID,
+		WalletID:             transaction.WalletID,
+		Type:                 transaction.Type,
+		Amount:               transaction.Amount,
+		BalanceAfter:         transaction.BalanceAfter,
+		CounterpartyWalletID: transaction.CounterpartyWalletID,
+		ReversalOf:           transaction.ReversalOf,
+		Created:              transaction.Created,
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/queries"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	rateLimitModule = "repo_rate_limit"
+)
+
+type rateLimitRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+// NewRateLimitRepo keeps the buckets in Postgres, so that the limits are
+// shared by all the instances.
+func NewRateLimitRepo(pool database.ConnectionPool, log zerolog.Logger) httpserver.RateLimitStore {
+	return &rateLimitRepository{pool: pool, log: logger.WithModule(log, rateLimitModule)}
+}
+
+// Take updates all the buckets with one statement in key order, a batch for
+// thousands of wallets is one round trip and concurrent ones do not deadlock.
+func (r *rateLimitRepository) Take(ctx context.Context, keys []string, rate float64, burst int) (time.Duration, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.TakeRateLimitToken, keys, rate, burst)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var wait time.Duration
+	for rows.Next() {
+		var (
+			allowed bool
+			tokens  float64
+		)
+		if err = rows.Scan(&allowed, &tokens); err != nil {
+			return 0, err
+		}
+		if !allowed {
+			wait = max(wait, time.Duration((1-tokens)/rate*float64(time.Second)))
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+func (r *rateLimitRepository) DeleteIdle(ctx context.Context, idle time.Duration) (int64, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Release()
+	tag, err := connection.Exec(ctx, queries.DeleteIdleRateLimitBuckets, idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	walletEventModule = "repo_wallet_event"
+)
+
+type WalletEventRepo interface {
+	FindAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]entities.WalletEvent, error)
+}
+
+type walletEventRepository struct {
+	pool database.ConnectionPool
+	log  zerolog.Logger
+}
+
+func NewWalletEventRepo(pool database.ConnectionPool, log zerolog.Logger) WalletEventRepo {
+	return &walletEventRepository{pool: pool, log: logger.WithModule(log, walletEventModule)}
+}
+
+// FindAfter returns up to limit events of the wallet written after the event
+// afterID, oldest first.
+func (r *walletEventRepository) FindAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]entities.WalletEvent, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.FindWalletEvents, walletID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []entities.WalletEvent
+	for rows.Next() {
+		var event entities.WalletEvent
+		if err = rows.Scan(&event.ID, &event.Type, &event.WalletID, &event.Created, &event.Payload); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletEventRepoMock struct {
+	mock.Mock
+}
+
+func NewWalletEventRepoMock() *WalletEventRepoMock {
+	return &WalletEventRepoMock{}
+}
+
+func (m *WalletEventRepoMock) FindAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]entities.WalletEvent, error) {
+	args := m.Called(ctx, walletID, afterID, limit)
+	return args.Get(0).([]entities.WalletEvent), args.Error(1)
+}
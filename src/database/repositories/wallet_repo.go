@@ -2,7 +2,10 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 	"wallet-api/pkg/database"
@@ -17,8 +20,13 @@ import (
 )
 
 const (
-	maxRetries       = 20
-	notEnoughBalance = "not enough balance"
+	maxRetries         = 20
+	notEnoughBalance   = "not enough balance"
+	pgCodeWalletClosed = "WA001"
+	pgCodeWalletFrozen = "WA002"
+	pgCodeWalletLimit  = "WA003"
+	pgCodeOutOfRange   = "22003"
+	pgCodeForeignKey   = "23503"
 )
 
 var (
@@ -26,13 +34,76 @@ var (
 	ErrNoRowsForUpdate        = errors.New("row not found for affect")
 	ErrWalletNotFound         = errors.New("wallet not found")
 	ErrWalletNotEnoughBalance = errors.New("not enough balance")
+	ErrWalletClosed           = errors.New("wallet is closed")
+	ErrWalletFrozen           = errors.New("wallet is frozen")
+	ErrWalletStatusTransition = errors.New("wallet status transition is not allowed")
+	ErrWalletBalanceNotZero   = errors.New("wallet balance is not zero")
+	ErrUnknownSortColumn      = errors.New("unknown sort column")
+	ErrCurrencyMismatch       = errors.New("wallet currencies do not match")
+	ErrBalanceOverflow        = errors.New("balance overflow")
+	ErrSpendingLimitExceeded  = errors.New("spending limit exceeded")
 )
 
+// walletsSortColumns maps the columns wallets can be sorted by to their sql
+// type, the cursor value is cast to it.
+var walletsSortColumns = map[string]string{
+	"created": "timestamp",
+	"updated": "timestamp",
+	"balance": "bigint",
+}
+
+// walletStatusTransitions lists the statuses a wallet may move to from its
+// current one. Closed wallets can not be reopened.
+var walletStatusTransitions = map[string][]string{
+	entities.Wallet_status_active: {entities.Wallet_status_frozen, entities.Wallet_status_closed},
+	entities.Wallet_status_frozen: {entities.Wallet_status_active, entities.Wallet_status_closed},
+}
+
 type WalletRepo interface {
 	FindByID(ctx context.Context, id uuid.UUID) (entities.Wallet, error)
+	Create(ctx context.Context, currency string, owner *string) (entities.Wallet, error)
+	ChangeStatus(ctx context.Context, id uuid.UUID, status string) (entities.Wallet, error)
+	ChangeLimits(ctx context.Context, id uuid.UUID, limits entities.WalletLimits, audit entities.WalletAudit) (entities.Wallet, error)
 	WithdrawUpdate(ctx context.Context, id uuid.UUID, amount int64) error
 	DepositUpdate(ctx context.Context, id uuid.UUID, amount int64) error
-	GetWallets(ctx context.Context) ([]entities.Wallet, error)
+	Transfer(ctx context.Context, from, to uuid.UUID, amount int64) error
+	ExecuteFXQuote(ctx context.Context, quoteID uuid.UUID) error
+	GetWallets(ctx context.Context, filter WalletsFilter) ([]entities.Wallet, error)
+	ApplyBatch(ctx context.Context, changes []BalanceChange, atomic bool) ([]BalanceChangeResult, error)
+	FindSpendingLimits(ctx context.Context, id uuid.UUID) (entities.SpendingLimits, error)
+	SetSpendingLimits(ctx context.Context, limits entities.SpendingLimits) (entities.SpendingLimits, error)
+	FindOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*string, error)
+	FindFXQuoteWallet(ctx context.Context, quoteID uuid.UUID) (uuid.UUID, error)
+}
+
+// WalletsFilter selects a page of wallets ordered by SortBy and id. The page
+// starts right after the wallet identified by AfterValue and AfterID, where
+// AfterValue is the text form of its SortBy column. Nil fields are not applied.
+type WalletsFilter struct {
+	Status     *string
+	MinBalance *int64
+	MaxBalance *int64
+	SortBy     string
+	Descending bool
+	AfterValue *string
+	AfterID    *uuid.UUID
+	Limit      int
+}
+
+// BalanceChange is one item of a batch, Type is Transaction_type_deposit or
+// Transaction_type_withdraw. An empty Currency is the one of the wallet.
+type BalanceChange struct {
+	WalletID uuid.UUID
+	Type     string
+	Amount   int64
+	Currency string
+}
+
+// BalanceChangeResult holds the ledger entry written for the item or the
+// reason it was refused.
+type BalanceChangeResult struct {
+	Transaction entities.Transaction
+	Err         error
 }
 
 type walletRepository struct {
@@ -51,10 +122,7 @@ func (r *walletRepository) FindByID(ctx context.Context, id uuid.UUID) (entities
 		return entities.Wallet{}, err
 	}
 	defer connection.Release()
-	var wallet entities.Wallet
-	err = connection.QueryRow(ctx, queries.FindWallet, id).Scan(
-		&wallet.Balance,
-	)
+	wallet, err := scanWallet(connection.QueryRow(ctx, queries.FindWallet, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return entities.Wallet{}, ErrWalletNotFound
@@ -64,64 +132,298 @@ func (r *walletRepository) FindByID(ctx context.Context, id uuid.UUID) (entities
 	return wallet, nil
 }
 
-func (r *walletRepository) GetWallets(ctx context.Context) ([]entities.Wallet, error) {
+func (r *walletRepository) Create(ctx context.Context, currency string, owner *string) (entities.Wallet, error) {
 	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer tx.Rollback(ctx)
+	wallet, err := scanWallet(tx.QueryRow(ctx, queries.CreateWallet, currency, owner))
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	event := entities.WalletCreated{WalletID: wallet.ID, Currency: wallet.Currency, Created: wallet.Created}
+	if err = insertOutboxEvent(ctx, tx, entities.Event_type_wallet_created, wallet.ID, event); err != nil {
+		return entities.Wallet{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (r *walletRepository) ChangeStatus(ctx context.Context, id uuid.UUID, status string) (entities.Wallet, error) {
+	var wallet entities.Wallet
+	err := retrySerializable(logger.FromContext(ctx, r.log), "change wallet status", func() error {
+		var err error
+		wallet, err = r.changeStatusTx(ctx, id, status)
+		return err
+	})
+	return wallet, err
+}
+
+// ChangeLimits sets the credit limit and minimum balance of the wallet and
+// writes the audit record with the previous values in the same transaction.
+func (r *walletRepository) ChangeLimits(ctx context.Context, id uuid.UUID, limits entities.WalletLimits, audit entities.WalletAudit) (entities.Wallet, error) {
+	var wallet entities.Wallet
+	err := retrySerializable(logger.FromContext(ctx, r.log), "change wallet limits", func() error {
+		var err error
+		wallet, err = r.changeLimitsTx(ctx, id, limits, audit)
+		return err
+	})
+	return wallet, err
+}
+
+func (r *walletRepository) GetWallets(ctx context.Context, filter WalletsFilter) ([]entities.Wallet, error) {
+	var err error
+	castType, ok := walletsSortColumns[filter.SortBy]
+	if !ok {
+		return nil, ErrUnknownSortColumn
+	}
+	comparison, direction := ">", "asc"
+	if filter.Descending {
+		comparison, direction = "<", "desc"
+	}
+	query := fmt.Sprintf(queries.GetWallets, filter.SortBy, castType, comparison, direction)
+
 	connection, err := r.pool.GetConnection(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer connection.Release()
-	var wallets []entities.Wallet
-	rows, err := connection.Query(ctx, queries.GetWallets)
+	rows, err := connection.Query(ctx, query,
+		filter.Status,
+		filter.MinBalance,
+		filter.MaxBalance,
+		filter.AfterValue,
+		filter.AfterID,
+		filter.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	var wallets []entities.Wallet
 	for rows.Next() {
-		var wallet entities.Wallet
-		err := rows.Scan(
-			&wallet.ID,
-		)
+		wallet, err := scanWallet(rows)
 		if err != nil {
-			continue
+			return nil, err
 		}
 		wallets = append(wallets, wallet)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 	return wallets, nil
 }
 
+func (r *walletRepository) FindSpendingLimits(ctx context.Context, id uuid.UUID) (entities.SpendingLimits, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.SpendingLimits{}, err
+	}
+	defer connection.Release()
+	limits, err := scanSpendingLimits(connection.QueryRow(ctx, queries.FindSpendingLimits, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.SpendingLimits{}, ErrWalletNotFound
+		}
+		return entities.SpendingLimits{}, err
+	}
+	return limits, nil
+}
+
+// SetSpendingLimits replaces all limits of the wallet, nil limits are removed.
+func (r *walletRepository) SetSpendingLimits(ctx context.Context, limits entities.SpendingLimits) (entities.SpendingLimits, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.SpendingLimits{}, err
+	}
+	defer connection.Release()
+	limits, err = scanSpendingLimits(connection.QueryRow(ctx, queries.UpsertSpendingLimits,
+		limits.WalletID,
+		limits.MaxSingleWithdrawal,
+		limits.MaxDailyWithdrawal,
+		limits.MaxMonthlyWithdrawal,
+		limits.MaxHourlyOperations,
+	))
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgCodeForeignKey {
+			return entities.SpendingLimits{}, ErrWalletNotFound
+		}
+		return entities.SpendingLimits{}, err
+	}
+	return limits, nil
+}
+
 func (r *walletRepository) DepositUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
-	var attempts int = 0
+	err := retrySerializable(logger.FromContext(ctx, r.log), "deposit balance", func() error {
+		return r.changeBalanceTx(ctx, id, amount, queries.UpdateDepositWallet, entities.Transaction_type_deposit)
+	})
+	observeBalanceOperation(entities.Transaction_type_deposit, err)
+	return err
+}
+
+func (r *walletRepository) WithdrawUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
+	err := retrySerializable(logger.FromContext(ctx, r.log), "withdraw balance", func() error {
+		return r.changeBalanceTx(ctx, id, amount, queries.UpdateWithdrawWallet, entities.Transaction_type_withdraw)
+	})
+	observeBalanceOperation(entities.Transaction_type_withdraw, err)
+	return err
+}
+
+func (r *walletRepository) Transfer(ctx context.Context, from, to uuid.UUID, amount int64) error {
+	return retrySerializable(logger.FromContext(ctx, r.log), "transfer balance", func() error {
+		return r.transferTx(ctx, from, to, amount)
+	})
+}
+
+func (r *walletRepository) ExecuteFXQuote(ctx context.Context, quoteID uuid.UUID) error {
+	return retrySerializable(logger.FromContext(ctx, r.log), "fx transfer balance", func() error {
+		return r.executeFXQuoteTx(ctx, quoteID)
+	})
+}
+
+// ApplyBatch applies all changes over one connection. The wallets are locked
+// in id order before the first change, so the batch neither deadlocks nor
+// has to be retried and runs read committed. In atomic mode the first refused
+// change rolls everything back and the rest are not tried, otherwise every
+// change runs in its own savepoint and only the refused ones are undone.
+func (r *walletRepository) ApplyBatch(ctx context.Context, changes []BalanceChange, atomic bool) ([]BalanceChangeResult, error) {
 	var err error
-	for {
-		r.log.Debug().Msg("operation start deposit balance")
-		if err = r.changeBalanceTx(ctx, id, amount, queries.UpdateDepositWallet); err == nil {
-			r.log.Debug().Msg("operation deposit balance success")
-			return nil
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	currencies, err := lockWalletsBatch(ctx, tx, changes)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BalanceChangeResult, len(changes))
+	for i, change := range changes {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
 		}
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "40001" {
-			if attempts < maxRetries {
-				time.Sleep(time.Duration(attempts*100) * time.Millisecond)
-				attempts++
-				continue
+		results[i].Transaction, err = applyBalanceChange(ctx, savepoint, change, currencies)
+		if err == nil {
+			err = savepoint.Commit(ctx)
+			if err != nil {
+				return nil, err
 			}
+			continue
+		}
+		if !isBalanceChangeError(err) {
+			return nil, err
+		}
+		results[i].Err = err
+		if atomic {
+			return results[:i+1], nil
+		}
+		if err = savepoint.Rollback(ctx); err != nil {
+			return nil, err
 		}
-		return err
 	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (r *walletRepository) WithdrawUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
+func applyBalanceChange(ctx context.Context, tx pgx.Tx, change BalanceChange, currencies map[uuid.UUID]string) (entities.Transaction, error) {
+	currency, ok := currencies[change.WalletID]
+	if !ok {
+		return entities.Transaction{}, ErrNoRowsForUpdate
+	}
+	if change.Currency != "" && currency != change.Currency {
+		return entities.Transaction{}, ErrCurrencyMismatch
+	}
+	query := queries.UpdateDepositWallet
+	if change.Type == entities.Transaction_type_withdraw {
+		query = queries.UpdateWithdrawWallet
+	}
+	if _, err := tx.Exec(ctx, query, change.WalletID, change.Amount); err != nil {
+		return entities.Transaction{}, mapBalanceError(err)
+	}
+	return insertTransaction(ctx, tx, entities.Transaction{WalletID: change.WalletID, Type: change.Type, Amount: change.Amount})
+}
+
+// isBalanceChangeError tells the errors refusing a single change from the
+// ones that break the whole batch.
+func isBalanceChangeError(err error) bool {
+	for _, target := range []error{
+		ErrNoRowsForUpdate,
+		ErrWalletNotEnoughBalance,
+		ErrWalletClosed,
+		ErrWalletFrozen,
+		ErrCurrencyMismatch,
+		ErrBalanceOverflow,
+		ErrSpendingLimitExceeded,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockWalletsBatch locks the wallets of all changes in id order and returns
+// their currencies. Missing wallets are left out of the map.
+func lockWalletsBatch(ctx context.Context, tx pgx.Tx, changes []BalanceChange) (map[uuid.UUID]string, error) {
+	ids := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.WalletID)
+	}
+	rows, err := tx.Query(ctx, queries.LockWalletsBatch, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	currencies := make(map[uuid.UUID]string, len(ids))
+	for rows.Next() {
+		var (
+			id       uuid.UUID
+			currency string
+		)
+		if err = rows.Scan(&id, &currency); err != nil {
+			return nil, err
+		}
+		currencies[id] = currency
+	}
+	return currencies, rows.Err()
+}
+
+// retrySerializable repeats op while postgres aborts it with a serialization
+// failure (40001), backing off a little more on every attempt.
+func retrySerializable(log *zerolog.Logger, operation string, op func() error) error {
 	var attempts int = 0
 	var err error
 	for {
-		r.log.Debug().Msg("operation start withdraw balance")
-		if err = r.changeBalanceTx(ctx, id, amount, queries.UpdateWithdrawWallet); err == nil {
-			r.log.Debug().Msg("operation withdraw balance success")
+		log.Debug().Msgf("operation start %s", operation)
+		if err = op(); err == nil {
+			log.Debug().Msgf("operation %s success", operation)
 			return nil
 		}
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "40001" {
 			if attempts < maxRetries {
-				time.Sleep(time.Duration(attempts*100) * time.Millisecond)
+				backoff := time.Duration(attempts*100) * time.Millisecond
+				log.Debug().Int("attempt", attempts+1).Dur("backoff", backoff).Msgf("operation %s serialization failure, retrying", operation)
+				observeSerializationRetry(operation, backoff)
+				time.Sleep(backoff)
 				attempts++
 				continue
 			}
@@ -130,7 +432,7 @@ func (r *walletRepository) WithdrawUpdate(ctx context.Context, id uuid.UUID, amo
 	}
 }
 
-func (r *walletRepository) changeBalanceTx(ctx context.Context, id uuid.UUID, amount int64, query string) error {
+func (r *walletRepository) changeBalanceTx(ctx context.Context, id uuid.UUID, amount int64, query, txType string) error {
 	var err error
 	connection, err := r.pool.GetConnection(ctx)
 	if err != nil {
@@ -142,21 +444,345 @@ func (r *walletRepository) changeBalanceTx(ctx context.Context, id uuid.UUID, am
 		return err
 	}
 	defer tx.Rollback(ctx)
-	tag, err := connection.Exec(ctx, query, id, amount)
+	tag, err := tx.Exec(ctx, query, id, amount)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "P0002" {
-			return ErrNoRowsForUpdate
-		}
-		if strings.Contains(err.Error(), notEnoughBalance) {
-			return ErrWalletNotEnoughBalance
-		}
-		return err
+		return mapBalanceError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return ErrNoRowsForUpdate
 	}
+	if _, err = insertTransaction(ctx, tx, entities.Transaction{WalletID: id, Type: txType, Amount: amount}); err != nil {
+		return err
+	}
 	if err = tx.Commit(ctx); err != nil {
 		return err
 	}
 	return nil
 }
+
+// transferTx debits from and credits to in one transaction. Both wallet rows
+// are locked in id order first, so two opposite transfers between the same
+// wallets can not deadlock each other.
+func (r *walletRepository) transferTx(ctx context.Context, from, to uuid.UUID, amount int64) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	currencies, err := lockWallets(ctx, tx, from, to)
+	if err != nil {
+		return err
+	}
+	if currencies[from] != currencies[to] {
+		return ErrCurrencyMismatch
+	}
+	err = moveBalance(ctx, tx,
+		entities.Transaction{WalletID: from, Amount: amount},
+		entities.Transaction{WalletID: to, Amount: amount},
+	)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// executeFXQuoteTx is transferTx for wallets in different currencies: the
+// amounts and the rate come from the quote, which can be executed only once.
+func (r *walletRepository) executeFXQuoteTx(ctx context.Context, quoteID uuid.UUID) error {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	quote, err := lockFXQuote(ctx, tx, quoteID)
+	if err != nil {
+		return err
+	}
+	currencies, err := lockWallets(ctx, tx, quote.FromWalletID, quote.ToWalletID)
+	if err != nil {
+		return err
+	}
+	if currencies[quote.FromWalletID] != quote.FromCurrency || currencies[quote.ToWalletID] != quote.ToCurrency {
+		return ErrCurrencyMismatch
+	}
+	err = moveBalance(ctx, tx,
+		entities.Transaction{
+			WalletID:   quote.FromWalletID,
+			Amount:     quote.Amount,
+			FXQuoteID:  &quote.ID,
+			FXRate:     &quote.Rate,
+			FXRounding: &quote.Rounding,
+		},
+		entities.Transaction{
+			WalletID:   quote.ToWalletID,
+			Amount:     quote.ConvertedAmount,
+			FXQuoteID:  &quote.ID,
+			FXRate:     &quote.Rate,
+			FXRounding: &quote.Rounding,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, queries.ExecuteFXQuote, quote.ID); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// moveBalance withdraws debit.Amount from the debit wallet, deposits
+// credit.Amount to the credit wallet and writes both ledger legs. The wallet
+// rows must already be locked by the caller.
+func moveBalance(ctx context.Context, tx pgx.Tx, debit, credit entities.Transaction) error {
+	var err error
+	if _, err = tx.Exec(ctx, queries.UpdateWithdrawWallet, debit.WalletID, debit.Amount); err != nil {
+		return mapBalanceError(err)
+	}
+	if _, err = tx.Exec(ctx, queries.UpdateDepositWallet, credit.WalletID, credit.Amount); err != nil {
+		return mapBalanceError(err)
+	}
+	debit.Type, debit.CounterpartyWalletID = entities.Transaction_type_transfer_out, &credit.WalletID
+	if _, err = insertTransaction(ctx, tx, debit); err != nil {
+		return err
+	}
+	credit.Type, credit.CounterpartyWalletID = entities.Transaction_type_transfer_in, &debit.WalletID
+	if _, err = insertTransaction(ctx, tx, credit); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *walletRepository) changeStatusTx(ctx context.Context, id uuid.UUID, status string) (entities.Wallet, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer tx.Rollback(ctx)
+	wallet, err := scanWallet(tx.QueryRow(ctx, queries.FindWalletForUpdate, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Wallet{}, ErrWalletNotFound
+		}
+		return entities.Wallet{}, err
+	}
+	if !slices.Contains(walletStatusTransitions[wallet.Status], status) {
+		return entities.Wallet{}, ErrWalletStatusTransition
+	}
+	if status == entities.Wallet_status_closed && wallet.Balance != 0 {
+		return entities.Wallet{}, ErrWalletBalanceNotZero
+	}
+	wallet, err = scanWallet(tx.QueryRow(ctx, queries.UpdateWalletStatus, id, status))
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (r *walletRepository) changeLimitsTx(ctx context.Context, id uuid.UUID, limits entities.WalletLimits, audit entities.WalletAudit) (entities.Wallet, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return entities.Wallet{}, err
+	}
+	defer tx.Rollback(ctx)
+	wallet, err := scanWallet(tx.QueryRow(ctx, queries.FindWalletForUpdate, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Wallet{}, ErrWalletNotFound
+		}
+		return entities.Wallet{}, err
+	}
+	if wallet.Status == entities.Wallet_status_closed {
+		return entities.Wallet{}, ErrWalletClosed
+	}
+	audit.WalletID, audit.Action = id, entities.Wallet_audit_action_limits
+	audit.OldValue = entities.WalletLimits{CreditLimit: wallet.CreditLimit, MinBalance: wallet.MinBalance}
+	audit.NewValue = limits
+	if err = insertWalletAudit(ctx, tx, audit); err != nil {
+		return entities.Wallet{}, err
+	}
+	wallet, err = scanWallet(tx.QueryRow(ctx, queries.UpdateWalletLimits, id, limits.CreditLimit, limits.MinBalance))
+	if err != nil {
+		return entities.Wallet{}, mapBalanceError(err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func insertWalletAudit(ctx context.Context, tx pgx.Tx, audit entities.WalletAudit) error {
+	oldValue, err := json.Marshal(audit.OldValue)
+	if err != nil {
+		return err
+	}
+	newValue, err := json.Marshal(audit.NewValue)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, queries.InsertWalletAudit,
+		audit.WalletID,
+		audit.Action,
+		audit.Actor,
+		audit.Reason,
+		oldValue,
+		newValue,
+	)
+	return err
+}
+
+// lockWallets locks both wallet rows in id order and returns their currencies.
+func lockWallets(ctx context.Context, tx pgx.Tx, first, second uuid.UUID) (map[uuid.UUID]string, error) {
+	rows, err := tx.Query(ctx, queries.LockWallets, first, second)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	currencies := make(map[uuid.UUID]string, 2)
+	for rows.Next() {
+		var (
+			id       uuid.UUID
+			currency string
+		)
+		if err = rows.Scan(&id, &currency); err != nil {
+			return nil, err
+		}
+		currencies[id] = currency
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(currencies) != 2 {
+		return nil, ErrNoRowsForUpdate
+	}
+	return currencies, nil
+}
+
+// FindOwners returns the owners of the wallets found, the wallets without an
+// owner are mapped to nil.
+func (r *walletRepository) FindOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*string, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.FindWalletOwners, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	owners := make(map[uuid.UUID]*string, len(ids))
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			owner *string
+		)
+		if err = rows.Scan(&id, &owner); err != nil {
+			return nil, err
+		}
+		owners[id] = owner
+	}
+	return owners, rows.Err()
+}
+
+// FindFXQuoteWallet returns the wallet a quote is paid from.
+func (r *walletRepository) FindFXQuoteWallet(ctx context.Context, quoteID uuid.UUID) (uuid.UUID, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer connection.Release()
+	var walletID uuid.UUID
+	if err = connection.QueryRow(ctx, queries.FindFXQuoteWallet, quoteID).Scan(&walletID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrFXQuoteNotFound
+		}
+		return uuid.Nil, err
+	}
+	return walletID, nil
+}
+
+func scanWallet(row pgx.Row) (entities.Wallet, error) {
+	var wallet entities.Wallet
+	err := row.Scan(
+		&wallet.ID,
+		&wallet.Balance,
+		&wallet.Held,
+		&wallet.CreditLimit,
+		&wallet.MinBalance,
+		&wallet.Currency,
+		&wallet.Status,
+		&wallet.Owner,
+		&wallet.Created,
+		&wallet.Updated,
+	)
+	return wallet, err
+}
+
+func scanSpendingLimits(row pgx.Row) (entities.SpendingLimits, error) {
+	var limits entities.SpendingLimits
+	err := row.Scan(
+		&limits.WalletID,
+		&limits.MaxSingleWithdrawal,
+		&limits.MaxDailyWithdrawal,
+		&limits.MaxMonthlyWithdrawal,
+		&limits.MaxHourlyOperations,
+		&limits.Updated,
+	)
+	return limits, err
+}
+
+func mapBalanceError(err error) error {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		switch pgErr.Code {
+		case "P0002":
+			return ErrNoRowsForUpdate
+		case pgCodeWalletClosed:
+			return ErrWalletClosed
+		case pgCodeWalletFrozen:
+			return ErrWalletFrozen
+		case pgCodeWalletLimit:
+			return fmt.Errorf("%w: %s", ErrSpendingLimitExceeded, pgErr.Detail)
+		case pgCodeOutOfRange:
+			return ErrBalanceOverflow
+		}
+	}
+	if strings.Contains(err.Error(), notEnoughBalance) {
+		return ErrWalletNotEnoughBalance
+	}
+	return err
+}
@@ -21,6 +21,21 @@ func (m *WalletRepoMock) FindByID(ctx context.Context, id uuid.UUID) (entities.W
 	return args.Get(0).(entities.Wallet), args.Error(1)
 }
 
+func (m *WalletRepoMock) Create(ctx context.Context, currency string, owner *string) (entities.Wallet, error) {
+	args := m.Called(ctx, currency, owner)
+	return args.Get(0).(entities.Wallet), args.Error(1)
+}
+
+func (m *WalletRepoMock) ChangeStatus(ctx context.Context, id uuid.UUID, status string) (entities.Wallet, error) {
+	args := m.Called(ctx, id, status)
+	return args.Get(0).(entities.Wallet), args.Error(1)
+}
+
+func (m *WalletRepoMock) ChangeLimits(ctx context.Context, id uuid.UUID, limits entities.WalletLimits, audit entities.WalletAudit) (entities.Wallet, error) {
+	args := m.Called(ctx, id, limits, audit)
+	return args.Get(0).(entities.Wallet), args.Error(1)
+}
+
 func (m *WalletRepoMock) DepositUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
 	args := m.Called(ctx, id, amount)
 	return args.Error(0)
@@ -31,7 +46,42 @@ func (m *WalletRepoMock) WithdrawUpdate(ctx context.Context, id uuid.UUID, amoun
 	return args.Error(0)
 }
 
-func (m *WalletRepoMock) GetWallets(ctx context.Context) ([]entities.Wallet, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]entities.Wallet), args.Error(0)
+func (m *WalletRepoMock) Transfer(ctx context.Context, from, to uuid.UUID, amount int64) error {
+	args := m.Called(ctx, from, to, amount)
+	return args.Error(0)
+}
+
+func (m *WalletRepoMock) ExecuteFXQuote(ctx context.Context, quoteID uuid.UUID) error {
+	args := m.Called(ctx, quoteID)
+	return args.Error(0)
+}
+
+func (m *WalletRepoMock) GetWallets(ctx context.Context, filter WalletsFilter) ([]entities.Wallet, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]entities.Wallet), args.Error(1)
+}
+
+func (m *WalletRepoMock) FindSpendingLimits(ctx context.Context, id uuid.UUID) (entities.SpendingLimits, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.SpendingLimits), args.Error(1)
+}
+
+func (m *WalletRepoMock) SetSpendingLimits(ctx context.Context, limits entities.SpendingLimits) (entities.SpendingLimits, error) {
+	args := m.Called(ctx, limits)
+	return args.Get(0).(entities.SpendingLimits), args.Error(1)
+}
+
+func (m *WalletRepoMock) ApplyBatch(ctx context.Context, changes []BalanceChange, atomic bool) ([]BalanceChangeResult, error) {
+	args := m.Called(ctx, changes, atomic)
+	return args.Get(0).([]BalanceChangeResult), args.Error(1)
+}
+
+func (m *WalletRepoMock) FindOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*string, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(map[uuid.UUID]*string), args.Error(1)
+}
+
+func (m *WalletRepoMock) FindFXQuoteWallet(ctx context.Context, quoteID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, quoteID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
 }
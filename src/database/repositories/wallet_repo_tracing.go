@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"wallet-api/pkg/tracing"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const walletIDAttribute = attribute.Key("wallet.id")
+
+type tracedWalletRepository struct {
+	next WalletRepo
+}
+
+// TraceWalletRepo makes a span of every call of next, the statements it sends
+// are the children of the span.
+func TraceWalletRepo(next WalletRepo) WalletRepo {
+	return &tracedWalletRepository{next: next}
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "WalletRepo."+name, trace.WithAttributes(attrs...))
+}
+
+func (r *tracedWalletRepository) FindByID(ctx context.Context, id uuid.UUID) (entities.Wallet, error) {
+	ctx, span := startSpan(ctx, "FindByID", walletIDAttribute.String(id.String()))
+	wallet, err := r.next.FindByID(ctx, id)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (r *tracedWalletRepository) Create(ctx context.Context, currency string, owner *string) (entities.Wallet, error) {
+	ctx, span := startSpan(ctx, "Create")
+	wallet, err := r.next.Create(ctx, currency, owner)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (r *tracedWalletRepository) ChangeStatus(ctx context.Context, id uuid.UUID, status string) (entities.Wallet, error) {
+	ctx, span := startSpan(ctx, "ChangeStatus", walletIDAttribute.String(id.String()))
+	wallet, err := r.next.ChangeStatus(ctx, id, status)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (r *tracedWalletRepository) ChangeLimits(ctx context.Context, id uuid.UUID, limits entities.WalletLimits, audit entities.WalletAudit) (entities.Wallet, error) {
+	ctx, span := startSpan(ctx, "ChangeLimits", walletIDAttribute.String(id.String()))
+	wallet, err := r.next.ChangeLimits(ctx, id, limits, audit)
+	tracing.End(span, err)
+	return wallet, err
+}
+
+func (r *tracedWalletRepository) WithdrawUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
+	ctx, span := startSpan(ctx, "WithdrawUpdate", walletIDAttribute.String(id.String()))
+	err := r.next.WithdrawUpdate(ctx, id, amount)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedWalletRepository) DepositUpdate(ctx context.Context, id uuid.UUID, amount int64) error {
+	ctx, span := startSpan(ctx, "DepositUpdate", walletIDAttribute.String(id.String()))
+	err := r.next.DepositUpdate(ctx, id, amount)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedWalletRepository) Transfer(ctx context.Context, from, to uuid.UUID, amount int64) error {
+	ctx, span := startSpan(ctx, "Transfer",
+		attribute.String("wallet.from_id", from.String()),
+		attribute.String("wallet.to_id", to.String()),
+	)
+	err := r.next.Transfer(ctx, from, to, amount)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedWalletRepository) ExecuteFXQuote(ctx context.Context, quoteID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "ExecuteFXQuote", attribute.String("fx.quote_id", quoteID.String()))
+	err := r.next.ExecuteFXQuote(ctx, quoteID)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tracedWalletRepository) GetWallets(ctx context.Context, filter WalletsFilter) ([]entities.Wallet, error) {
+	ctx, span := startSpan(ctx, "GetWallets")
+	wallets, err := r.next.GetWallets(ctx, filter)
+	tracing.End(span, err)
+	return wallets, err
+}
+
+func (r *tracedWalletRepository) ApplyBatch(ctx context.Context, changes []BalanceChange, atomic bool) ([]BalanceChangeResult, error) {
+	ctx, span := startSpan(ctx, "ApplyBatch", attribute.Int("wallet.batch.items", len(changes)))
+	results, err := r.next.ApplyBatch(ctx, changes, atomic)
+	tracing.End(span, err)
+	return results, err
+}
+
+func (r *tracedWalletRepository) FindSpendingLimits(ctx context.Context, id uuid.UUID) (entities.SpendingLimits, error) {
+	ctx, span := startSpan(ctx, "FindSpendingLimits", walletIDAttribute.String(id.String()))
+	limits, err := r.next.FindSpendingLimits(ctx, id)
+	tracing.End(span, err)
+	return limits, err
+}
+
+func (r *tracedWalletRepository) SetSpendingLimits(ctx context.Context, limits entities.SpendingLimits) (entities.SpendingLimits, error) {
+	ctx, span := startSpan(ctx, "SetSpendingLimits", walletIDAttribute.String(limits.WalletID.String()))
+	limits, err := r.next.SetSpendingLimits(ctx, limits)
+	tracing.End(span, err)
+	return limits, err
+}
+
+func (r *tracedWalletRepository) FindOwners(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*string, error) {
+	ctx, span := startSpan(ctx, "FindOwners", attribute.Int("wallet.count", len(ids)))
+	owners, err := r.next.FindOwners(ctx, ids)
+	tracing.End(span, err)
+	return owners, err
+}
+
+func (r *tracedWalletRepository) FindFXQuoteWallet(ctx context.Context, quoteID uuid.UUID) (uuid.UUID, error) {
+	ctx, span := startSpan(ctx, "FindFXQuoteWallet", attribute.String("fx.quote_id", quoteID.String()))
+	id, err := r.next.FindFXQuoteWallet(ctx, quoteID)
+	tracing.End(span, err)
+	return id, err
+}
@@ -0,0 +1,306 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-api/pkg/database"
+	"wallet-api/pkg/logger"
+	"wallet-api/pkg/webhook"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/queries"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+var (
+	webhookModule              = "repo_webhook"
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryPending  = errors.New("webhook delivery is already pending")
+)
+
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (entities.WebhookSubscription, error)
+	FindSubscription(ctx context.Context, id uuid.UUID) (entities.WebhookSubscription, error)
+	FindSubscriptions(ctx context.Context) ([]entities.WebhookSubscription, error)
+	SetSubscriptionActive(ctx context.Context, id uuid.UUID, active bool) (entities.WebhookSubscription, error)
+	FindDeliveries(ctx context.Context, subscriptionID uuid.UUID, status *string, limit int) ([]entities.WebhookDelivery, error)
+	Replay(ctx context.Context, deliveryID uuid.UUID) (entities.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	pool    database.ConnectionPool
+	secrets *webhook.Secrets
+	log     zerolog.Logger
+}
+
+// NewWebhookRepo stores the subscription secrets encrypted with secrets.
+func NewWebhookRepo(pool database.ConnectionPool, secrets *webhook.Secrets, log zerolog.Logger) WebhookRepo {
+	return &webhookRepository{pool: pool, secrets: secrets, log: logger.WithModule(log, webhookModule)}
+}
+
+// CreateSubscription returns the only subscription with the secret, it is
+// never read back.
+func (r *webhookRepository) CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (entities.WebhookSubscription, error) {
+	sealed, err := r.secrets.Seal(secret)
+	if err != nil {
+		return entities.WebhookSubscription{}, err
+	}
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.WebhookSubscription{}, err
+	}
+	defer connection.Release()
+	subscription, err := scanWebhookSubscription(connection.QueryRow(ctx, queries.CreateWebhookSubscription, url, eventTypes, sealed))
+	if err != nil {
+		return entities.WebhookSubscription{}, err
+	}
+	subscription.Secret = secret
+	return subscription, nil
+}
+
+func (r *webhookRepository) FindSubscription(ctx context.Context, id uuid.UUID) (entities.WebhookSubscription, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.WebhookSubscription{}, err
+	}
+	defer connection.Release()
+	subscription, err := scanWebhookSubscription(connection.QueryRow(ctx, queries.FindWebhookSubscription, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.WebhookSubscription{}, ErrWebhookNotFound
+		}
+		return entities.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (r *webhookRepository) FindSubscriptions(ctx context.Context) ([]entities.WebhookSubscription, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.FindWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subscriptions []entities.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) SetSubscriptionActive(ctx context.Context, id uuid.UUID, active bool) (entities.WebhookSubscription, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.WebhookSubscription{}, err
+	}
+	defer connection.Release()
+	subscription, err := scanWebhookSubscription(connection.QueryRow(ctx, queries.UpdateWebhookSubscriptionActive, id, active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.WebhookSubscription{}, ErrWebhookNotFound
+		}
+		return entities.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+// FindDeliveries returns the latest deliveries of the subscription, only the
+// ones in status when it is set.
+func (r *webhookRepository) FindDeliveries(ctx context.Context, subscriptionID uuid.UUID, status *string, limit int) ([]entities.WebhookDelivery, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.FindWebhookDeliveries, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deliveries []entities.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Replay queues a delivered or dead delivery to be sent again with a fresh
+// attempt budget.
+func (r *webhookRepository) Replay(ctx context.Context, deliveryID uuid.UUID) (entities.WebhookDelivery, error) {
+	var err error
+	connection, err := r.pool.GetConnection(ctx)
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	defer connection.Release()
+	tx, err := connection.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	defer tx.Rollback(ctx)
+	delivery, err := scanWebhookDelivery(tx.QueryRow(ctx, queries.LockWebhookDelivery, deliveryID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+		}
+		return entities.WebhookDelivery{}, err
+	}
+	if delivery.Status == entities.Webhook_delivery_pending {
+		return entities.WebhookDelivery{}, ErrWebhookDeliveryPending
+	}
+	delivery, err = scanWebhookDelivery(tx.QueryRow(ctx, queries.ReplayWebhookDelivery, deliveryID))
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+type webhookDeliveryStore struct {
+	pool    database.ConnectionPool
+	secrets *webhook.Secrets
+	log     zerolog.Logger
+}
+
+// NewWebhookDeliveryStore is the store of the webhook delivery worker, it
+// decrypts the subscription secrets with secrets.
+func NewWebhookDeliveryStore(pool database.ConnectionPool, secrets *webhook.Secrets, log zerolog.Logger) webhook.Store {
+	return &webhookDeliveryStore{pool: pool, secrets: secrets, log: logger.WithModule(log, webhookModule)}
+}
+
+func (s *webhookDeliveryStore) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) error {
+	return s.exec(ctx, queries.EnqueueWebhookDeliveries, eventID, eventType, payload)
+}
+
+func (s *webhookDeliveryStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	var err error
+	connection, err := s.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Release()
+	rows, err := connection.Query(ctx, queries.ClaimWebhookDeliveries, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deliveries []webhook.Delivery
+	for rows.Next() {
+		var delivery webhook.Delivery
+		var encrypted bool
+		err = rows.Scan(
+			&delivery.ID,
+			&delivery.URL,
+			&delivery.Secret,
+			&encrypted,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Attempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		// subscriptions created before 023 keep their secrets in plain text
+		if encrypted {
+			if delivery.Secret, err = s.secrets.Open(delivery.Secret); err != nil {
+				return nil, err
+			}
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *webhookDeliveryStore) MarkDelivered(ctx context.Context, id uuid.UUID, status int) error {
+	return s.exec(ctx, queries.MarkWebhookDelivered, id, status)
+}
+
+func (s *webhookDeliveryStore) MarkFailed(ctx context.Context, id uuid.UUID, status int, reason string, retryIn time.Duration) error {
+	return s.exec(ctx, queries.MarkWebhookFailed, id, nullStatus(status), reason, retryIn.Seconds())
+}
+
+func (s *webhookDeliveryStore) MarkDead(ctx context.Context, id uuid.UUID, status int, reason string) error {
+	return s.exec(ctx, queries.MarkWebhookDead, id, nullStatus(status), reason)
+}
+
+func (s *webhookDeliveryStore) exec(ctx context.Context, query string, args ...any) error {
+	var err error
+	connection, err := s.pool.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+	_, err = connection.Exec(ctx, query, args...)
+	return err
+}
+
+// nullStatus stores a delivery that got no response at all without status.
+func nullStatus(status int) *int {
+	if status == 0 {
+		return nil
+	}
+	return &status
+}
+
+func scanWebhookSubscription(row pgx.Row) (entities.WebhookSubscription, error) {
+	var subscription entities.WebhookSubscription
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.EventTypes,
+		&subscription.Active,
+		&subscription.Created,
+		&subscription.Updated,
+	)
+	return subscription, err
+}
+
+func scanWebhookDelivery(row pgx.Row) (entities.WebhookDelivery, error) {
+	var delivery entities.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttempt,
+		&delivery.LastStatus,
+		&delivery.LastError,
+		&delivery.Created,
+		&delivery.Delivered,
+	)
+	return delivery, err
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"wallet-api/src/database/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepoMock struct {
+	mock.Mock
+}
+
+func NewWebhookRepoMock() *WebhookRepoMock {
+	return &WebhookRepoMock{}
+}
+
+func (m *WebhookRepoMock) CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (entities.WebhookSubscription, error) {
+	args := m.Called(ctx, url, eventTypes, secret)
+	return args.Get(0).(entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *WebhookRepoMock) FindSubscription(ctx context.Context, id uuid.UUID) (entities.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *WebhookRepoMock) FindSubscriptions(ctx context.Context) ([]entities.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *WebhookRepoMock) SetSubscriptionActive(ctx context.Context, id uuid.UUID, active bool) (entities.WebhookSubscription, error) {
+	args := m.Called(ctx, id, active)
+	return args.Get(0).(entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *WebhookRepoMock) FindDeliveries(ctx context.Context, subscriptionID uuid.UUID, status *string, limit int) ([]entities.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, status, limit)
+	return args.Get(0).([]entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookRepoMock) Replay(ctx context.Context, deliveryID uuid.UUID) (entities.WebhookDelivery, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(entities.WebhookDelivery), args.Error(1)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+// AdminUserHeader names the administrator making the change, it is written
+// to the audit record. With the authentication enabled the principal is
+// written instead.
+const AdminUserHeader = "X-Admin-User"
+
+type AdminHandler interface {
+	Register(s httpserver.Router)
+	ChangeWalletLimits(w http.ResponseWriter, r *http.Request)
+}
+
+type adminHandler struct {
+	walletService services.WalletService
+}
+
+func NewAdminHandler(walletService services.WalletService) AdminHandler {
+	return &adminHandler{walletService: walletService}
+}
+
+func (h *adminHandler) Register(s httpserver.Router) {
+	s.POST("/admin/wallets/{WALLET_UUID}/limits", h.ChangeWalletLimits)
+}
+
+// requireAdmin refuses requests without an administrator, for the routes that
+// do not write the administrator to an audit record themselves.
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := auth.FromContext(r.Context()); ok && !principal.HasScope(auth.ScopeAdmin) {
+			w.Header().Set("Content-Type", "application/json")
+			utils.RespondError(w, http.StatusForbidden, "admin scope is required")
+			return
+		}
+		if adminUser(r) == "" {
+			w.Header().Set("Content-Type", "application/json")
+			utils.RespondError(w, http.StatusUnauthorized, "admin user is required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// adminUser is the authenticated principal, or AdminUserHeader when the
+// authentication is disabled.
+func adminUser(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal.ID
+	}
+	return r.Header.Get(AdminUserHeader)
+}
+
+func (h *adminHandler) ChangeWalletLimits(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	actor := adminUser(r)
+	if actor == "" {
+		utils.RespondError(w, http.StatusUnauthorized, "admin user is required")
+		return
+	}
+	id, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	var limitsReq models.ChangeWalletLimitsRequest
+	if err = json.NewDecoder(r.Body).Decode(&limitsReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if limitsReq.CreditLimit < 0 {
+		utils.RespondError(w, http.StatusBadRequest, "credit limit must not be negative")
+		return
+	}
+	if limitsReq.MinBalance != nil && *limitsReq.MinBalance < 0 {
+		utils.RespondError(w, http.StatusBadRequest, "min balance must not be negative")
+		return
+	}
+	if limitsReq.Reason == "" {
+		utils.RespondError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+	wallet, errResp := h.walletService.ChangeWalletLimits(r.Context(), id, actor, limitsReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, wallet)
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminHandler_ChangeWalletLimits(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewAdminHandler(mockService)
+	id := uuid.New()
+
+	send := func(actor, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+id.String()+"/limits", strings.NewReader(body))
+		req.SetPathValue("WALLET_UUID", id.String())
+		if actor != "" {
+			req.Header.Set(handlers.AdminUserHeader, actor)
+		}
+		w := httptest.NewRecorder()
+		h.ChangeWalletLimits(w, req)
+		return w
+	}
+
+	t.Run("without admin user", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send("", `{"creditLimit":100,"reason":"test"}`).Code)
+	})
+
+	t.Run("negative credit limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("admin", `{"creditLimit":-1,"reason":"test"}`).Code)
+	})
+
+	t.Run("negative min balance", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("admin", `{"creditLimit":100,"minBalance":-1,"reason":"test"}`).Code)
+	})
+
+	t.Run("without reason", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("admin", `{"creditLimit":100}`).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		minBalance := int64(50)
+		req := models.ChangeWalletLimitsRequest{CreditLimit: 100, MinBalance: &minBalance, Reason: "postpaid"}
+		mockService.On("ChangeWalletLimits", mock.Anything, id, "admin", req).Return(models.Wallet{ID: id, CreditLimit: 100}, nil).Once()
+
+		w := send("admin", `{"creditLimit":100,"minBalance":50,"reason":"postpaid"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	LastEventIDHeader = "Last-Event-ID"
+
+	defaultHeartbeat = 15 * time.Second
+)
+
+type EventsHandler interface {
+	Register(s httpserver.Router)
+	StreamWalletEvents(w http.ResponseWriter, r *http.Request)
+}
+
+type eventsHandler struct {
+	walletService      services.WalletService
+	walletEventService services.WalletEventService
+	heartbeat          time.Duration
+	retry              time.Duration
+}
+
+// NewEventsHandler creates the handler. A comment is sent every heartbeat to
+// keep idle streams open, retry is the reconnection delay for the client.
+func NewEventsHandler(walletService services.WalletService, walletEventService services.WalletEventService, heartbeat, retry time.Duration) EventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return &eventsHandler{
+		walletService:      walletService,
+		walletEventService: walletEventService,
+		heartbeat:          heartbeat,
+		retry:              retry,
+	}
+}
+
+func (h *eventsHandler) Register(s httpserver.Router) {
+	s.GET("/wallets/{WALLET_UUID}/events", h.StreamWalletEvents)
+}
+
+// StreamWalletEvents sends the events of the wallet as they commit. A client
+// reconnecting with Last-Event-ID first gets the events it missed.
+func (h *eventsHandler) StreamWalletEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	lastEventID, resume, err := parseLastEventID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		utils.RespondError(w, http.StatusBadRequest, "incorrect last event id")
+		return
+	}
+	if _, errResp := h.walletService.GetWalletByID(r.Context(), id); errResp != nil {
+		w.Header().Set("Content-Type", "application/json")
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+
+	watch := h.walletEventService.Watch(id)
+	defer watch.Stop()
+	stream, err := httpserver.NewSSEWriter(w, h.retry)
+	if err != nil {
+		return
+	}
+	send := func(event models.WalletEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return stream.Event(event.ID, event.Type, data)
+	}
+	if resume {
+		// the client reconnects and resumes from the last event it got
+		if errResp, err := watch.Replay(r.Context(), lastEventID, send); errResp != nil || err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-httpserver.ShuttingDown(r.Context()):
+			return
+		case event, ok := <-watch.Events():
+			if !ok {
+				return
+			}
+			if watch.Replayed(event) {
+				continue
+			}
+			if err = send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err = stream.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseLastEventID reads Last-Event-ID, or the lastEventId query parameter
+// for clients that cannot set headers. resume is false when there is none.
+func parseLastEventID(r *http.Request) (id int64, resume bool, err error) {
+	value := r.Header.Get(LastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, ErrIncorrectLastEventID
+	}
+	return id, true, nil
+}
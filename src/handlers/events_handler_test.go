@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// readEvent returns the id and event lines of the next event in the stream,
+// comments are skipped.
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	var id, event string
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return "", ""
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && id != "":
+			return id, event
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		}
+	}
+}
+
+func TestEventsHandler_StreamWalletEvents(t *testing.T) {
+	walletService := new(services.WalletServiceMock)
+	eventService := new(services.WalletEventServiceMock)
+	mux := http.NewServeMux()
+	handlers.NewEventsHandler(walletService, eventService, time.Hour, 0).Register(muxRouter(mux))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	id := uuid.New()
+
+	t.Run("incorrect last event id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/wallets/"+id.String()+"/events", nil)
+		req.Header.Set(handlers.LastEventIDHeader, "abc")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		walletService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{}, &models.ErrorResponse{Code: http.StatusNotFound, Message: "wallet not found"}).Once()
+
+		resp, err := http.Get(server.URL + "/wallets/" + id.String() + "/events")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("resume and stream", func(t *testing.T) {
+		live := make(chan models.WalletEvent, 2)
+		unsubscribed := make(chan struct{})
+		walletService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{}, nil).Once()
+		eventService.On("Subscribe", id).Return((<-chan models.WalletEvent)(live), func() { close(unsubscribed) }).Once()
+		eventService.On("GetEventsAfter", mock.Anything, id, int64(5), mock.Anything).Return([]models.WalletEvent{
+			{ID: 6, Type: "balance.changed", WalletID: id},
+			{ID: 7, Type: "hold.expired", WalletID: id},
+		}, nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/wallets/"+id.String()+"/events", nil)
+		req.Header.Set(handlers.LastEventIDHeader, "5")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		reader := bufio.NewReader(resp.Body)
+
+		eventID, event := readEvent(t, reader)
+		assert.Equal(t, "6", eventID)
+		assert.Equal(t, "balance.changed", event)
+		eventID, event = readEvent(t, reader)
+		assert.Equal(t, "7", eventID)
+		assert.Equal(t, "hold.expired", event)
+
+		// 7 was already replayed
+		live <- models.WalletEvent{ID: 7, Type: "hold.expired", WalletID: id}
+		live <- models.WalletEvent{ID: 8, Type: "balance.changed", WalletID: id}
+		eventID, _ = readEvent(t, reader)
+		assert.Equal(t, "8", eventID)
+
+		cancel()
+		select {
+		case <-unsubscribed:
+		case <-time.After(time.Second):
+			t.Fatal("stream was not closed")
+		}
+		walletService.AssertExpectations(t)
+		eventService.AssertExpectations(t)
+	})
+}
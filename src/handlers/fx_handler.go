@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+type FXHandler interface {
+	Register(s httpserver.Router)
+	CreateQuote(w http.ResponseWriter, r *http.Request)
+}
+
+type fxHandler struct {
+	fxService services.FXService
+}
+
+func NewFXHandler(fxService services.FXService) FXHandler {
+	return &fxHandler{fxService: fxService}
+}
+
+func (h *fxHandler) Register(s httpserver.Router) {
+	s.POST("/fx/quotes", h.CreateQuote)
+}
+
+func (h *fxHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var quoteReq models.CreateFXQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&quoteReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if quoteReq.FromID == quoteReq.ToID {
+		utils.RespondError(w, http.StatusBadRequest, "unable transfer to the same wallet")
+		return
+	}
+	if !validateAmount(quoteReq.Amount) {
+		utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+		return
+	}
+	quote, errResp := h.fxService.CreateQuote(r.Context(), quoteReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, quote)
+}
@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFXHandler_CreateQuote(t *testing.T) {
+	mockService := new(services.FXServiceMock)
+	h := handlers.NewFXHandler(mockService)
+
+	validReq := models.CreateFXQuoteRequest{FromID: uuid.New(), ToID: uuid.New(), Amount: 10000}
+
+	t.Run("invalid json body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fx/quotes", strings.NewReader("{bad json}"))
+		w := httptest.NewRecorder()
+
+		h.CreateQuote(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+	})
+
+	t.Run("amount zero", func(t *testing.T) {
+		body, _ := json.Marshal(models.CreateFXQuoteRequest{FromID: validReq.FromID, ToID: validReq.ToID})
+		req := httptest.NewRequest(http.MethodPost, "/fx/quotes", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.CreateQuote(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp := models.FXQuote{ID: uuid.New(), Amount: 10000, ConvertedAmount: 110, Rate: "0.011"}
+		mockService.On("CreateQuote", mock.Anything, validReq).Return(resp, nil).Once()
+
+		body, _ := json.Marshal(validReq)
+		req := httptest.NewRequest(http.MethodPost, "/fx/quotes", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.CreateQuote(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+		var result models.FXQuote
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Equal(t, resp.ID, result.ID)
+		mockService.AssertExpectations(t)
+	})
+}
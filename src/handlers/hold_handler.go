@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+type HoldHandler interface {
+	Register(s httpserver.Router)
+	CreateHold(w http.ResponseWriter, r *http.Request)
+	FindHoldById(w http.ResponseWriter, r *http.Request)
+	CaptureHold(w http.ResponseWriter, r *http.Request)
+	VoidHold(w http.ResponseWriter, r *http.Request)
+}
+
+type holdHandler struct {
+	holdService services.HoldService
+	middlewares []httpserver.Middleware
+}
+
+// NewHoldHandler creates the handler, middlewares wrap only the routes that
+// change balances.
+func NewHoldHandler(holdService services.HoldService, middlewares ...httpserver.Middleware) HoldHandler {
+	return &holdHandler{holdService: holdService, middlewares: middlewares}
+}
+
+func (h *holdHandler) Register(s httpserver.Router) {
+	s.POST("/wallets/{WALLET_UUID}/holds", httpserver.Chain(h.CreateHold, h.middlewares...)).
+		GET("/holds/{HOLD_UUID}", h.FindHoldById).
+		POST("/holds/{HOLD_UUID}/capture", httpserver.Chain(h.CaptureHold, h.middlewares...)).
+		POST("/holds/{HOLD_UUID}/void", h.VoidHold)
+}
+
+func (h *holdHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	walletID, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	var holdReq models.CreateHoldRequest
+	if err = json.NewDecoder(r.Body).Decode(&holdReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if !validateAmount(holdReq.Amount) {
+		utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+		return
+	}
+	hold, errResp := h.holdService.CreateHold(r.Context(), walletID, holdReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, hold)
+}
+
+func (h *holdHandler) FindHoldById(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "HOLD_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect hold id")
+		return
+	}
+	hold, errResp := h.holdService.GetHoldByID(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, hold)
+}
+
+func (h *holdHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "HOLD_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect hold id")
+		return
+	}
+	var captureReq models.CaptureHoldRequest
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(&captureReq); err != nil {
+			utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+			return
+		}
+	}
+	if captureReq.Amount != nil && !validateAmount(*captureReq.Amount) {
+		utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+		return
+	}
+	hold, errResp := h.holdService.CaptureHold(r.Context(), id, captureReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, hold)
+}
+
+func (h *holdHandler) VoidHold(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "HOLD_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect hold id")
+		return
+	}
+	hold, errResp := h.holdService.VoidHold(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, hold)
+}
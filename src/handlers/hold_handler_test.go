@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoldHandler_CreateHold(t *testing.T) {
+	mockService := new(services.HoldServiceMock)
+	h := handlers.NewHoldHandler(mockService)
+	walletID := uuid.New()
+
+	send := func(walletPath, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/"+walletPath+"/holds", strings.NewReader(body))
+		req.SetPathValue("WALLET_UUID", walletPath)
+		w := httptest.NewRecorder()
+		h.CreateHold(w, req)
+		return w
+	}
+
+	t.Run("incorrect wallet id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("bad", `{"amount":100}`).Code)
+	})
+
+	t.Run("amount zero", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(walletID.String(), `{"amount":0}`).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockService.On("CreateHold", mock.Anything, walletID, models.CreateHoldRequest{Amount: 100, TTL: 60}).
+			Return(models.Hold{ID: uuid.New()}, nil).Once()
+
+		w := send(walletID.String(), `{"amount":100,"ttlSeconds":60}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHoldHandler_CaptureHold(t *testing.T) {
+	mockService := new(services.HoldServiceMock)
+	h := handlers.NewHoldHandler(mockService)
+	holdID := uuid.New()
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/holds/"+holdID.String()+"/capture", strings.NewReader(body))
+		req.SetPathValue("HOLD_UUID", holdID.String())
+		w := httptest.NewRecorder()
+		h.CaptureHold(w, req)
+		return w
+	}
+
+	t.Run("full capture without body", func(t *testing.T) {
+		mockService.On("CaptureHold", mock.Anything, holdID, models.CaptureHoldRequest{}).
+			Return(models.Hold{ID: holdID}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, send("").Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("negative amount", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`{"amount":-1}`).Code)
+	})
+
+	t.Run("hold expired", func(t *testing.T) {
+		mockService.On("CaptureHold", mock.Anything, holdID, mock.Anything).
+			Return(models.Hold{}, &models.ErrorResponse{Code: http.StatusConflict, Message: "hold expired"}).Once()
+
+		assert.Equal(t, http.StatusConflict, send(`{"amount":10}`).Code)
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+type LedgerHandler interface {
+	Register(s httpserver.Router)
+	FindTransactionById(w http.ResponseWriter, r *http.Request)
+	GetWalletTransactions(w http.ResponseWriter, r *http.Request)
+	ReverseTransaction(w http.ResponseWriter, r *http.Request)
+}
+
+type ledgerHandler struct {
+	ledgerService services.LedgerService
+	middlewares   []httpserver.Middleware
+}
+
+// NewLedgerHandler creates the handler, middlewares wrap only the routes that
+// change balances.
+func NewLedgerHandler(ledgerService services.LedgerService, middlewares ...httpserver.Middleware) LedgerHandler {
+	return &ledgerHandler{ledgerService: ledgerService, middlewares: middlewares}
+}
+
+// Register adds the ledger routes, the reversal requires AdminUserHeader.
+func (h *ledgerHandler) Register(s httpserver.Router) {
+	s.GET("/transactions/{TRANSACTION_UUID}", h.FindTransactionById).
+		GET("/wallets/{WALLET_UUID}/transactions", h.GetWalletTransactions).
+		POST("/transactions/{TRANSACTION_UUID}/reverse", requireAdmin(httpserver.Chain(h.ReverseTransaction, h.middlewares...)))
+}
+
+func (h *ledgerHandler) FindTransactionById(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractIdFromPath(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect transaction id")
+		return
+	}
+	transaction, errResp := h.ledgerService.GetTransactionByID(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, transaction)
+}
+
+func (h *ledgerHandler) GetWalletTransactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	walletID, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	req, err := parseGetTransactionsRequest(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.WalletID = walletID
+	transactions, errResp := h.ledgerService.GetWalletTransactions(r.Context(), req)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, transactions)
+}
+
+func (h *ledgerHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "TRANSACTION_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect transaction id")
+		return
+	}
+	var reverseReq models.ReverseTransactionRequest
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(&reverseReq); err != nil {
+			utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+			return
+		}
+	}
+	if reverseReq.Amount != nil && !validateAmount(*reverseReq.Amount) {
+		utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+		return
+	}
+	reversal, errResp := h.ledgerService.ReverseTransaction(r.Context(), id, reverseReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, reversal)
+}
@@ -0,0 +1,171 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedgerHandler_FindTransactionById(t *testing.T) {
+	mockService := new(services.LedgerServiceMock)
+	h := handlers.NewLedgerHandler(mockService)
+
+	transactionID := uuid.New()
+
+	t.Run("transaction found", func(t *testing.T) {
+		resp := models.Transaction{ID: transactionID, Type: "deposit", Amount: 500, BalanceAfter: 1500}
+		mockService.On("GetTransactionByID", mock.Anything, transactionID).Return(resp, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/transactions/"+transactionID.String(), nil)
+		w := httptest.NewRecorder()
+
+		h.FindTransactionById(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var result models.Transaction
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Equal(t, int64(1500), result.BalanceAfter)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("incorrect id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/not-a-uuid", nil)
+		w := httptest.NewRecorder()
+
+		h.FindTransactionById(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		errResp := &models.ErrorResponse{Code: http.StatusNotFound, Message: "transaction not found"}
+		mockService.On("GetTransactionByID", mock.Anything, transactionID).Return(models.Transaction{}, errResp).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/transactions/"+transactionID.String(), nil)
+		w := httptest.NewRecorder()
+
+		h.FindTransactionById(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestLedgerHandler_GetWalletTransactions(t *testing.T) {
+	mockService := new(services.LedgerServiceMock)
+	h := handlers.NewLedgerHandler(mockService)
+
+	walletID := uuid.New()
+
+	t.Run("incorrect query", func(t *testing.T) {
+		for _, query := range []string{"limit=-1", "order=up", "from=yesterday", "type=refund"} {
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/transactions?"+query, nil)
+			req.SetPathValue("WALLET_UUID", walletID.String())
+			w := httptest.NewRecorder()
+
+			h.GetWalletTransactions(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, query)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(24 * time.Hour)
+		expected := models.GetTransactionsRequest{
+			WalletID: walletID,
+			Limit:    20,
+			From:     &from,
+			To:       &to,
+			Types:    []string{models.Transaction_type_deposit, models.Transaction_type_transfer_in},
+		}
+		resp := models.GetTransactionsResponse{Transactions: []models.Transaction{{ID: uuid.New(), BalanceAfter: 100}}}
+		mockService.On("GetWalletTransactions", mock.Anything, expected).Return(resp, nil).Once()
+
+		url := "/wallets/" + walletID.String() + "/transactions?limit=20&from=2025-07-01T00:00:00Z&to=2025-07-02T03:00:00%2B03:00&type=deposit,transfer_in"
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.SetPathValue("WALLET_UUID", walletID.String())
+		w := httptest.NewRecorder()
+
+		h.GetWalletTransactions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var result models.GetTransactionsResponse
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Len(t, result.Transactions, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		errResp := &models.ErrorResponse{Code: http.StatusNotFound, Message: "wallet not found"}
+		mockService.On("GetWalletTransactions", mock.Anything, models.GetTransactionsRequest{WalletID: walletID}).Return(models.GetTransactionsResponse{}, errResp).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/transactions", nil)
+		req.SetPathValue("WALLET_UUID", walletID.String())
+		w := httptest.NewRecorder()
+
+		h.GetWalletTransactions(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestLedgerHandler_ReverseTransaction(t *testing.T) {
+	mockService := new(services.LedgerServiceMock)
+	mux := http.NewServeMux()
+	handlers.NewLedgerHandler(mockService).Register(muxRouter(mux))
+
+	transactionID := uuid.New()
+	send := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/"+path+"/reverse", strings.NewReader(body))
+		req.Header.Set(handlers.AdminUserHeader, "admin")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("without admin user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/transactions/"+transactionID.String()+"/reverse", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("incorrect id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("bad", "").Code)
+	})
+
+	t.Run("zero amount", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(transactionID.String(), `{"amount":0}`).Code)
+	})
+
+	t.Run("full reversal without body", func(t *testing.T) {
+		resp := models.ReverseTransactionResponse{Transactions: []models.Transaction{{ID: uuid.New(), ReversalOf: &transactionID}}}
+		mockService.On("ReverseTransaction", mock.Anything, transactionID, models.ReverseTransactionRequest{}).Return(resp, nil).Once()
+
+		w := send(transactionID.String(), "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("already reversed", func(t *testing.T) {
+		errResp := &models.ErrorResponse{Code: http.StatusConflict, Message: "transaction is already reversed"}
+		mockService.On("ReverseTransaction", mock.Anything, transactionID, models.ReverseTransactionRequest{}).Return(models.ReverseTransactionResponse{}, errResp).Once()
+
+		assert.Equal(t, http.StatusConflict, send(transactionID.String(), "").Code)
+	})
+}
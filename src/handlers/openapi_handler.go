@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/httpserver"
+)
+
+type OpenAPIHandler interface {
+	Register(s httpserver.Router)
+	GetDocument(w http.ResponseWriter, r *http.Request)
+}
+
+type openAPIHandler struct {
+	document []byte
+}
+
+func NewOpenAPIHandler(document []byte) OpenAPIHandler {
+	return &openAPIHandler{document: document}
+}
+
+func (h *openAPIHandler) Register(s httpserver.Router) {
+	s.GET("/openapi.json", h.GetDocument)
+}
+
+func (h *openAPIHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.document)
+}
@@ -0,0 +1,125 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wallet-api/api"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/openapi"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordRoutes returns a router collecting the routes a handler registers.
+func recordRoutes(routes map[string]string) httpserver.Router {
+	return httpserver.NewRouter(func(method, path string, _ http.HandlerFunc) {
+		routes[method+" "+path] = path
+	})
+}
+
+func TestOpenAPI_RoutesMatchDocument(t *testing.T) {
+	doc, err := openapi.Parse(api.OpenAPI)
+	if !assert.NoError(t, err) {
+		return
+	}
+	routes := map[string]string{}
+	handlers.NewWalletHandler(new(services.WalletServiceMock)).Register(recordRoutes(routes))
+	handlers.NewOpenAPIHandler(api.OpenAPI).Register(recordRoutes(routes))
+
+	for route, path := range routes {
+		method, _, _ := strings.Cut(route, " ")
+		op := doc.Operation(method, path)
+		if !assert.NotNil(t, op, "%s is not in the document", route) {
+			continue
+		}
+		var params []string
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, "{") {
+				params = append(params, strings.Trim(segment, "{}"))
+			}
+		}
+		assert.ElementsMatch(t, params, doc.PathParameters(op), route)
+	}
+	for path, item := range doc.Paths {
+		for method := range item {
+			route := strings.ToUpper(method) + " " + path
+			assert.Contains(t, routes, route, "%s is documented but not registered", route)
+		}
+	}
+}
+
+func TestOpenAPI_WalletResponses(t *testing.T) {
+	doc, err := openapi.Parse(api.OpenAPI)
+	if !assert.NoError(t, err) {
+		return
+	}
+	mockService := new(services.WalletServiceMock)
+	mux := http.NewServeMux()
+	validated := openapi.NewRouter(doc, muxRouter(mux), models.Max_body_bytes)
+	handlers.NewWalletHandler(mockService).Register(validated)
+	handlers.NewOpenAPIHandler(api.OpenAPI).Register(validated)
+	id := uuid.New()
+	minBalance := int64(-100)
+	limit := int64(1000)
+	now := time.Now().UTC()
+	wallet := models.Wallet{ID: id, Balance: 100, MinBalance: &minBalance, Currency: "RUB", Status: models.Wallet_status_active, Created: now, Updated: now}
+
+	mockService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{Balance: 100, Available: 100, Currency: "RUB"}, nil)
+	mockService.On("GetWallets", mock.Anything, mock.Anything).Return(models.GetWalletsResponse{Wallets: []models.Wallet{wallet}, NextCursor: "next"}, nil)
+	mockService.On("ChangeWalletBalance", mock.Anything, mock.Anything).Return(&models.ErrorResponse{Code: http.StatusNotFound, Message: "wallet not found"})
+	mockService.On("ChangeWalletBalanceBatch", mock.Anything, mock.Anything).Return(models.BatchChangeBalanceResponse{
+		Failed:  1,
+		Results: []models.BatchItemResult{{Index: 0, Status: models.Batch_item_failed, Error: &models.ErrorResponse{Code: http.StatusBadRequest, Message: "not enough balance"}}},
+	}, nil)
+	mockService.On("Transfer", mock.Anything, mock.Anything).Return(nil)
+	mockService.On("CreateWallet", mock.Anything, mock.Anything).Return(wallet, nil)
+	mockService.On("ChangeWalletStatus", mock.Anything, id, mock.Anything).Return(wallet, nil)
+	mockService.On("GetSpendingLimits", mock.Anything, id).Return(models.SpendingLimits{MaxDailyWithdrawal: &limit}, nil)
+	mockService.On("SetSpendingLimits", mock.Anything, id, mock.Anything).Return(models.SpendingLimits{MaxDailyWithdrawal: &limit, Updated: &now}, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		target string
+		body   string
+		status int
+	}{
+		{"balance", http.MethodGet, "/wallets/{WALLET_UUID}", "/wallets/" + id.String(), "", http.StatusOK},
+		{"balance incorrect id", http.MethodGet, "/wallets/{WALLET_UUID}", "/wallets/abc", "", http.StatusBadRequest},
+		{"list", http.MethodGet, "/wallets", "/wallets?limit=10&sort=balance", "", http.StatusOK},
+		{"list incorrect limit", http.MethodGet, "/wallets", "/wallets?limit=1000", "", http.StatusBadRequest},
+		{"change balance", http.MethodPost, "/wallet", "/wallet", `{"valletId": "` + id.String() + `", "operationType": "DEPOSIT", "amount": 10, "currency": "RUB"}`, http.StatusNotFound},
+		{"change balance without currency", http.MethodPost, "/wallet", "/wallet", `{"valletId": "` + id.String() + `", "operationType": "DEPOSIT", "amount": 10}`, http.StatusNotFound},
+		{"change balance incorrect amount", http.MethodPost, "/wallet", "/wallet", `{"valletId": "` + id.String() + `", "operationType": "DEPOSIT", "amount": 0, "currency": "RUB"}`, http.StatusBadRequest},
+		{"change balance unreadable body", http.MethodPost, "/wallet", "/wallet", `{"valletId"`, http.StatusUnprocessableEntity},
+		{"atomic batch rolled back", http.MethodPost, "/wallet/batch", "/wallet/batch", `{"mode": "atomic", "items": [{"valletId": "` + id.String() + `", "operationType": "WITHDRAW", "amount": 10, "currency": "RUB"}]}`, http.StatusUnprocessableEntity},
+		{"empty batch", http.MethodPost, "/wallet/batch", "/wallet/batch", `{"mode": "atomic", "items": []}`, http.StatusBadRequest},
+		{"transfer", http.MethodPost, "/transfers", "/transfers", `{"fromWalletId": "` + id.String() + `", "toWalletId": "` + uuid.NewString() + `", "amount": 10}`, http.StatusOK},
+		{"create wallet", http.MethodPost, "/wallets", "/wallets", "", http.StatusCreated},
+		{"create wallet unknown currency", http.MethodPost, "/wallets", "/wallets", `{"currency": "XXX"}`, http.StatusBadRequest},
+		{"freeze", http.MethodPost, "/wallets/{WALLET_UUID}/freeze", "/wallets/" + id.String() + "/freeze", "", http.StatusOK},
+		{"spending limits", http.MethodGet, "/wallets/{WALLET_UUID}/spending-limits", "/wallets/" + id.String() + "/spending-limits", "", http.StatusOK},
+		{"set spending limits", http.MethodPost, "/wallets/{WALLET_UUID}/spending-limits", "/wallets/" + id.String() + "/spending-limits", `{"maxDailyWithdrawal": 1000, "maxSingleWithdrawal": null}`, http.StatusOK},
+		{"set negative spending limit", http.MethodPost, "/wallets/{WALLET_UUID}/spending-limits", "/wallets/" + id.String() + "/spending-limits", `{"maxDailyWithdrawal": -1}`, http.StatusBadRequest},
+		{"document", http.MethodGet, "/openapi.json", "/openapi.json", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.NoError(t, doc.ValidateResponse(doc.Operation(tt.method, tt.path), w.Code, w.Body.Bytes()))
+		})
+	}
+}
@@ -1,16 +1,31 @@
 package handlers
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 	"wallet-api/src/models"
 
+	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrPathIsEmpty = errors.New("path is empty")
+	ErrPathIsEmpty      = errors.New("path is empty")
+	ErrIncorrectLimit   = errors.New("incorrect limit")
+	ErrIncorrectSort    = errors.New("incorrect sort")
+	ErrIncorrectOrder   = errors.New("incorrect order")
+	ErrIncorrectStatus  = errors.New("incorrect status")
+	ErrIncorrectBalance = errors.New("incorrect balance filter")
+	ErrIncorrectType    = errors.New("incorrect transaction type")
+	ErrIncorrectPeriod  = errors.New("incorrect period, expected RFC 3339 time")
+
+	ErrIncorrectLastEventID = errors.New("incorrect last event id")
 )
 
 func validateOperationType(op string) bool {
@@ -20,10 +35,147 @@ func validateOperationType(op string) bool {
 	return false
 }
 
+// validateChangeBalanceRequest returns the description of the first
+// incorrect field or an empty string.
+func validateChangeBalanceRequest(req models.ChangeBalanceRequest) string {
+	switch {
+	case !validateOperationType(req.OperationType):
+		return "incorrect operation type"
+	case !validateAmount(req.Balance):
+		return "amount must be more than zero"
+	case req.Currency != "" && !validateCurrency(req.Currency):
+		return "incorrect currency"
+	}
+	return ""
+}
+
 func validateAmount(amount int64) bool {
 	return amount > 0
 }
 
+func validateCurrency(code string) bool {
+	_, ok := models.LookupCurrency(code)
+	return ok
+}
+
+func validateSpendingLimits(limits models.SpendingLimits) bool {
+	for _, limit := range []*int64{
+		limits.MaxSingleWithdrawal,
+		limits.MaxDailyWithdrawal,
+		limits.MaxMonthlyWithdrawal,
+		limits.MaxHourlyOperations,
+	} {
+		if limit != nil && *limit <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCreateWebhookRequest returns the description of the first
+// incorrect field or an empty string.
+func validateCreateWebhookRequest(req models.CreateWebhookRequest) string {
+	target, err := url.Parse(req.URL)
+	switch {
+	case err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "":
+		return "url must be an absolute http or https url"
+	case len(req.EventTypes) == 0:
+		return "event types are required"
+	}
+	for _, eventType := range req.EventTypes {
+		if !validateWebhookEventType(eventType) {
+			return "incorrect event type " + eventType
+		}
+	}
+	return ""
+}
+
+func validateWebhookEventType(eventType string) bool {
+	switch eventType {
+	case models.Webhook_event_wallet_created,
+		models.Webhook_event_balance_changed,
+		models.Webhook_event_hold_expired:
+		return true
+	}
+	return false
+}
+
+func parseGetWebhookDeliveriesRequest(r *http.Request) (models.GetWebhookDeliveriesRequest, error) {
+	query := r.URL.Query()
+	req := models.GetWebhookDeliveriesRequest{Limit: models.Default_page_limit}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > models.Max_page_limit {
+			return models.GetWebhookDeliveriesRequest{}, ErrIncorrectLimit
+		}
+		req.Limit = value
+	}
+	switch status := query.Get("status"); status {
+	case "":
+	case models.Webhook_delivery_pending, models.Webhook_delivery_delivered, models.Webhook_delivery_dead:
+		req.Status = &status
+	default:
+		return models.GetWebhookDeliveriesRequest{}, ErrIncorrectStatus
+	}
+	return req, nil
+}
+
+// RequestWallets returns the distinct wallets a balance changing request
+// targets, for the per-wallet rate limit: the WALLET_UUID path value, or the
+// wallets of a change balance, batch or transfer body. The body is left for
+// the handler, unreadable ones and ones over Max_body_bytes are refused by it
+// later.
+func RequestWallets(r *http.Request) []string {
+	if value := r.PathValue("WALLET_UUID"); value != "" {
+		// the same wallet has one bucket however its id is written
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil
+		}
+		return []string{id.String()}
+	}
+	if r.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, models.Max_body_bytes))
+	if err != nil {
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{err: err}))
+		return nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req struct {
+		models.ChangeBalanceRequest
+		Items  []models.ChangeBalanceRequest `json:"items"`
+		FromID uuid.UUID                     `json:"fromWalletId"`
+	}
+	if err = json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	ids := []uuid.UUID{req.ID, req.FromID}
+	for _, item := range req.Items {
+		ids = append(ids, item.ID)
+	}
+	var wallets []string
+	seen := map[uuid.UUID]bool{uuid.Nil: true}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			wallets = append(wallets, id.String())
+		}
+	}
+	return wallets
+}
+
+// errorReader fails with err, for the handler to read the error the body
+// was read with.
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
 func extractIdFromPath(r *http.Request) (uuid.UUID, error) {
 	path := r.URL.Path
 	partsOfPath := strings.Split(path, "/")
@@ -36,3 +188,127 @@ func extractIdFromPath(r *http.Request) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+// extractPathValue parses the uuid from the named wildcard of the route
+// pattern, e.g. WALLET_UUID in /wallets/{WALLET_UUID}/freeze.
+func extractPathValue(r *http.Request, name string) (uuid.UUID, error) {
+	value := r.PathValue(name)
+	if value == "" {
+		return uuid.Nil, ErrPathIsEmpty
+	}
+	return uuid.Parse(value)
+}
+
+func parseGetWalletsRequest(r *http.Request) (models.GetWalletsRequest, error) {
+	query := r.URL.Query()
+	req := models.GetWalletsRequest{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Order:  query.Get("order"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > models.Max_page_limit {
+			return models.GetWalletsRequest{}, ErrIncorrectLimit
+		}
+		req.Limit = value
+	}
+	switch req.Sort {
+	case "", models.Wallets_sort_created, models.Wallets_sort_updated, models.Wallets_sort_balance:
+	default:
+		return models.GetWalletsRequest{}, ErrIncorrectSort
+	}
+	switch req.Order {
+	case "", models.Sort_order_asc, models.Sort_order_desc:
+	default:
+		return models.GetWalletsRequest{}, ErrIncorrectOrder
+	}
+	if status := query.Get("status"); status != "" {
+		switch status {
+		case models.Wallet_status_active, models.Wallet_status_frozen, models.Wallet_status_closed:
+			req.Status = &status
+		default:
+			return models.GetWalletsRequest{}, ErrIncorrectStatus
+		}
+	}
+	var err error
+	if req.MinBalance, err = parseOptionalInt64(query.Get("min_balance")); err != nil {
+		return models.GetWalletsRequest{}, ErrIncorrectBalance
+	}
+	if req.MaxBalance, err = parseOptionalInt64(query.Get("max_balance")); err != nil {
+		return models.GetWalletsRequest{}, ErrIncorrectBalance
+	}
+	return req, nil
+}
+
+func parseGetTransactionsRequest(r *http.Request) (models.GetTransactionsRequest, error) {
+	query := r.URL.Query()
+	req := models.GetTransactionsRequest{
+		Cursor: query.Get("cursor"),
+		Order:  query.Get("order"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > models.Max_page_limit {
+			return models.GetTransactionsRequest{}, ErrIncorrectLimit
+		}
+		req.Limit = value
+	}
+	switch req.Order {
+	case "", models.Sort_order_asc, models.Sort_order_desc:
+	default:
+		return models.GetTransactionsRequest{}, ErrIncorrectOrder
+	}
+	var err error
+	if req.From, err = parseOptionalTime(query.Get("from")); err != nil {
+		return models.GetTransactionsRequest{}, ErrIncorrectPeriod
+	}
+	if req.To, err = parseOptionalTime(query.Get("to")); err != nil {
+		return models.GetTransactionsRequest{}, ErrIncorrectPeriod
+	}
+	for _, value := range query["type"] {
+		for _, txType := range strings.Split(value, ",") {
+			if !validateTransactionType(txType) {
+				return models.GetTransactionsRequest{}, ErrIncorrectType
+			}
+			req.Types = append(req.Types, txType)
+		}
+	}
+	return req, nil
+}
+
+func validateTransactionType(txType string) bool {
+	switch txType {
+	case models.Transaction_type_deposit,
+		models.Transaction_type_withdraw,
+		models.Transaction_type_transfer_out,
+		models.Transaction_type_transfer_in,
+		models.Transaction_type_capture,
+		models.Transaction_type_reversal:
+		return true
+	}
+	return false
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	parsed = parsed.UTC()
+	return &parsed, nil
+}
+
+func parseOptionalInt64(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
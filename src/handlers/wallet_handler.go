@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"wallet-api/pkg/httpserver"
 	"wallet-api/pkg/httpserver/utils"
@@ -14,18 +15,40 @@ type WalletHandler interface {
 	Register(s httpserver.Router)
 	FindById(w http.ResponseWriter, r *http.Request)
 	ChangeBalance(w http.ResponseWriter, r *http.Request)
+	ChangeBalanceBatch(w http.ResponseWriter, r *http.Request)
+	GetWallets(w http.ResponseWriter, r *http.Request)
+	Transfer(w http.ResponseWriter, r *http.Request)
+	CreateWallet(w http.ResponseWriter, r *http.Request)
+	FreezeWallet(w http.ResponseWriter, r *http.Request)
+	UnfreezeWallet(w http.ResponseWriter, r *http.Request)
+	CloseWallet(w http.ResponseWriter, r *http.Request)
+	GetSpendingLimits(w http.ResponseWriter, r *http.Request)
+	SetSpendingLimits(w http.ResponseWriter, r *http.Request)
 }
 
 type walletHandler struct {
 	walletService services.WalletService
+	middlewares   []httpserver.Middleware
 }
 
-func NewWalletHandler(walletService services.WalletService) WalletHandler {
-	return &walletHandler{walletService: walletService}
+// NewWalletHandler creates the handler, middlewares wrap only the routes that
+// change balances.
+func NewWalletHandler(walletService services.WalletService, middlewares ...httpserver.Middleware) WalletHandler {
+	return &walletHandler{walletService: walletService, middlewares: middlewares}
 }
 
 func (h *walletHandler) Register(s httpserver.Router) {
-	s.POST("/wallet", h.ChangeBalance).GET("/wallets/{WALLET_UUID}", h.FindById).GET("/wallets", h.GetWallets)
+	s.POST("/wallet", httpserver.Chain(h.ChangeBalance, h.middlewares...)).
+		POST("/wallet/batch", httpserver.Chain(h.ChangeBalanceBatch, h.middlewares...)).
+		GET("/wallets/{WALLET_UUID}", h.FindById).
+		GET("/wallets", h.GetWallets).
+		POST("/transfers", httpserver.Chain(h.Transfer, h.middlewares...)).
+		POST("/wallets", httpserver.Chain(h.CreateWallet, h.middlewares...)).
+		POST("/wallets/{WALLET_UUID}/freeze", h.FreezeWallet).
+		POST("/wallets/{WALLET_UUID}/unfreeze", h.UnfreezeWallet).
+		POST("/wallets/{WALLET_UUID}/close", h.CloseWallet).
+		GET("/wallets/{WALLET_UUID}/spending-limits", h.GetSpendingLimits).
+		POST("/wallets/{WALLET_UUID}/spending-limits", h.SetSpendingLimits)
 }
 
 func (h *walletHandler) FindById(w http.ResponseWriter, r *http.Request) {
@@ -50,15 +73,71 @@ func (h *walletHandler) ChangeBalance(w http.ResponseWriter, r *http.Request) {
 		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
 		return
 	}
-	if !validateOperationType(changeBalanceReq.OperationType) {
-		utils.RespondError(w, http.StatusBadRequest, "incorrect operation type")
+	if message := validateChangeBalanceRequest(changeBalanceReq); message != "" {
+		utils.RespondError(w, http.StatusBadRequest, message)
 		return
 	}
-	if !validateAmount(changeBalanceReq.Balance) {
-		utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+	err := h.walletService.ChangeWalletBalance(r.Context(), changeBalanceReq)
+	if err != nil {
+		utils.RespondJSON(w, err.Code, err)
 		return
 	}
-	err := h.walletService.ChangeWalletBalance(r.Context(), changeBalanceReq)
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "ok"})
+}
+
+// ChangeBalanceBatch responds 200 with the result of every item. An atomic
+// batch that was rolled back gets 422 with the same body.
+func (h *walletHandler) ChangeBalanceBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var batchReq models.BatchChangeBalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if batchReq.Mode != models.Batch_mode_atomic && batchReq.Mode != models.Batch_mode_best_effort {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect batch mode")
+		return
+	}
+	if len(batchReq.Items) == 0 || len(batchReq.Items) > models.Max_batch_items {
+		utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("batch must contain from 1 to %d items", models.Max_batch_items))
+		return
+	}
+	for i, item := range batchReq.Items {
+		if message := validateChangeBalanceRequest(item); message != "" {
+			utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("item %d: %s", i, message))
+			return
+		}
+	}
+	response, errResp := h.walletService.ChangeWalletBalanceBatch(r.Context(), batchReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	status := http.StatusOK
+	if batchReq.Mode == models.Batch_mode_atomic && response.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	utils.RespondJSON(w, status, response)
+}
+
+func (h *walletHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var transferReq models.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&transferReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if transferReq.QuoteID == nil {
+		if transferReq.FromID == transferReq.ToID {
+			utils.RespondError(w, http.StatusBadRequest, "unable transfer to the same wallet")
+			return
+		}
+		if !validateAmount(transferReq.Amount) {
+			utils.RespondError(w, http.StatusBadRequest, "amount must be more than zero")
+			return
+		}
+	}
+	err := h.walletService.Transfer(r.Context(), transferReq)
 	if err != nil {
 		utils.RespondJSON(w, err.Code, err)
 		return
@@ -68,10 +147,102 @@ func (h *walletHandler) ChangeBalance(w http.ResponseWriter, r *http.Request) {
 
 func (h *walletHandler) GetWallets(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	wallets, errResp := h.walletService.GetWallets(r.Context())
+	req, err := parseGetWalletsRequest(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	wallets, errResp := h.walletService.GetWallets(r.Context(), req)
 	if errResp != nil {
 		utils.RespondJSON(w, errResp.Code, errResp)
 		return
 	}
 	utils.RespondJSON(w, http.StatusOK, wallets)
 }
+
+func (h *walletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var createReq models.CreateWalletRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+			utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+			return
+		}
+	}
+	if createReq.Currency != "" && !validateCurrency(createReq.Currency) {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect currency")
+		return
+	}
+	wallet, errResp := h.walletService.CreateWallet(r.Context(), createReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, wallet)
+}
+
+func (h *walletHandler) FreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, models.Wallet_status_frozen)
+}
+
+func (h *walletHandler) UnfreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, models.Wallet_status_active)
+}
+
+func (h *walletHandler) CloseWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, models.Wallet_status_closed)
+}
+
+func (h *walletHandler) changeWalletStatus(w http.ResponseWriter, r *http.Request, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	wallet, errResp := h.walletService.ChangeWalletStatus(r.Context(), id, status)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, wallet)
+}
+
+func (h *walletHandler) GetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	limits, errResp := h.walletService.GetSpendingLimits(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, limits)
+}
+
+func (h *walletHandler) SetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WALLET_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect wallet id")
+		return
+	}
+	var limitsReq models.SpendingLimits
+	if err = json.NewDecoder(r.Body).Decode(&limitsReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if !validateSpendingLimits(limitsReq) {
+		utils.RespondError(w, http.StatusBadRequest, "limits must be more than zero")
+		return
+	}
+	limits, errResp := h.walletService.SetSpendingLimits(r.Context(), id, limitsReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, limits)
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -19,13 +20,13 @@ func TestWalletHandler_FindById(t *testing.T) {
 	mockService := new(services.WalletServiceMock)
 	h := handlers.NewWalletHandler(mockService)
 
-	validID := uuid.New().String()
+	validID := uuid.New()
 
 	t.Run("wallet found", func(t *testing.T) {
 		resp := models.GetBalanceResponse{Balance: 1000}
-		mockService.On("GetWalletByID", mock.Anything, validID).Return(resp, nil)
+		mockService.On("GetWalletByID", mock.Anything, validID).Return(resp, nil).Once()
 
-		req := httptest.NewRequest(http.MethodGet, "/wallets/"+validID, nil)
+		req := httptest.NewRequest(http.MethodGet, "/wallets/"+validID.String(), nil)
 		w := httptest.NewRecorder()
 
 		h.FindById(w, req)
@@ -41,9 +42,9 @@ func TestWalletHandler_FindById(t *testing.T) {
 
 	t.Run("wallet not found", func(t *testing.T) {
 		errResp := &models.ErrorResponse{Code: http.StatusNotFound, Message: "wallet not found"}
-		mockService.On("GetWalletByID", mock.Anything, validID).Return(models.GetBalanceResponse{}, errResp)
+		mockService.On("GetWalletByID", mock.Anything, validID).Return(models.GetBalanceResponse{}, errResp).Once()
 
-		req := httptest.NewRequest(http.MethodGet, "/wallets/"+validID, nil)
+		req := httptest.NewRequest(http.MethodGet, "/wallets/"+validID.String(), nil)
 		w := httptest.NewRecorder()
 
 		h.FindById(w, req)
@@ -61,6 +62,7 @@ func TestWalletHandler_ChangeBalance(t *testing.T) {
 	validReq := models.ChangeBalanceRequest{
 		ID:            walletID,
 		Balance:       1000,
+		Currency:      models.Default_currency,
 		OperationType: models.Operation_type_deposit,
 	}
 
@@ -93,9 +95,33 @@ func TestWalletHandler_ChangeBalance(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 	})
 
+	t.Run("incorrect currency", func(t *testing.T) {
+		reqBody := `{"valletId":"` + walletID.String() + `","amount":1000,"currency":"XYZ","operationType":"DEPOSIT"}`
+		req := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(reqBody))
+		w := httptest.NewRecorder()
+
+		h.ChangeBalance(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("without currency", func(t *testing.T) {
+		withoutCurrency := models.ChangeBalanceRequest{ID: walletID, OperationType: models.Operation_type_deposit, Balance: 1000}
+		mockService.On("ChangeWalletBalance", mock.Anything, withoutCurrency).Return(nil).Once()
+
+		reqBody := `{"valletId":"` + walletID.String() + `","amount":1000,"operationType":"DEPOSIT"}`
+		req := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(reqBody))
+		w := httptest.NewRecorder()
+
+		h.ChangeBalance(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("service returns error", func(t *testing.T) {
 		errResp := &models.ErrorResponse{Code: http.StatusInternalServerError, Message: "internal error"}
-		mockService.On("ChangeWalletBalance", mock.Anything, validReq).Return(errResp)
+		mockService.On("ChangeWalletBalance", mock.Anything, validReq).Return(errResp).Once()
 
 		body, _ := json.Marshal(validReq)
 		req := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(string(body)))
@@ -108,7 +134,7 @@ func TestWalletHandler_ChangeBalance(t *testing.T) {
 	})
 
 	t.Run("success", func(t *testing.T) {
-		mockService.On("ChangeWalletBalance", mock.Anything, validReq).Return(nil)
+		mockService.On("ChangeWalletBalance", mock.Anything, validReq).Return(nil).Once()
 
 		body, _ := json.Marshal(validReq)
 		req := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(string(body)))
@@ -119,3 +145,308 @@ func TestWalletHandler_ChangeBalance(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 }
+
+func TestWalletHandler_Transfer(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	validReq := models.TransferRequest{
+		FromID: uuid.New(),
+		ToID:   uuid.New(),
+		Amount: 700,
+	}
+
+	t.Run("same wallet", func(t *testing.T) {
+		body, _ := json.Marshal(models.TransferRequest{FromID: validReq.FromID, ToID: validReq.FromID, Amount: 700})
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.Transfer(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("amount zero", func(t *testing.T) {
+		body, _ := json.Marshal(models.TransferRequest{FromID: validReq.FromID, ToID: validReq.ToID})
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.Transfer(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("not enough balance", func(t *testing.T) {
+		errResp := &models.ErrorResponse{Code: http.StatusBadRequest, Message: "not enough balance"}
+		mockService.On("Transfer", mock.Anything, validReq).Return(errResp).Once()
+
+		body, _ := json.Marshal(validReq)
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.Transfer(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockService.On("Transfer", mock.Anything, validReq).Return(nil).Once()
+
+		body, _ := json.Marshal(validReq)
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(string(body)))
+		w := httptest.NewRecorder()
+
+		h.Transfer(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_CreateWallet(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	t.Run("success", func(t *testing.T) {
+		resp := models.Wallet{ID: uuid.New(), Currency: "USD", Status: models.Wallet_status_active}
+		mockService.On("CreateWallet", mock.Anything, models.CreateWalletRequest{Currency: "USD"}).Return(resp, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"currency":"USD"}`))
+		w := httptest.NewRecorder()
+
+		h.CreateWallet(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+		var result models.Wallet
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Equal(t, resp.ID, result.ID)
+		assert.Equal(t, "USD", result.Currency)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("without body", func(t *testing.T) {
+		resp := models.Wallet{ID: uuid.New(), Currency: models.Default_currency}
+		mockService.On("CreateWallet", mock.Anything, models.CreateWalletRequest{}).Return(resp, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/wallets", nil)
+		w := httptest.NewRecorder()
+
+		h.CreateWallet(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("incorrect currency", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"currency":"usd"}`))
+		w := httptest.NewRecorder()
+
+		h.CreateWallet(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestWalletHandler_ChangeWalletStatus(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	walletID := uuid.New()
+
+	t.Run("incorrect wallet id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/bad/freeze", nil)
+		req.SetPathValue("WALLET_UUID", "bad")
+		w := httptest.NewRecorder()
+
+		h.FreezeWallet(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("freeze", func(t *testing.T) {
+		resp := models.Wallet{ID: walletID, Status: models.Wallet_status_frozen}
+		mockService.On("ChangeWalletStatus", mock.Anything, walletID, models.Wallet_status_frozen).Return(resp, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/wallets/"+walletID.String()+"/freeze", nil)
+		req.SetPathValue("WALLET_UUID", walletID.String())
+		w := httptest.NewRecorder()
+
+		h.FreezeWallet(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("close not allowed", func(t *testing.T) {
+		errResp := &models.ErrorResponse{Code: http.StatusConflict, Message: "wallet balance must be zero to close it"}
+		mockService.On("ChangeWalletStatus", mock.Anything, walletID, models.Wallet_status_closed).Return(models.Wallet{}, errResp).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/wallets/"+walletID.String()+"/close", nil)
+		req.SetPathValue("WALLET_UUID", walletID.String())
+		w := httptest.NewRecorder()
+
+		h.CloseWallet(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_GetWallets(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	t.Run("incorrect query", func(t *testing.T) {
+		for _, query := range []string{"limit=0", "limit=abc", "sort=name", "order=up", "status=deleted", "min_balance=x"} {
+			req := httptest.NewRequest(http.MethodGet, "/wallets?"+query, nil)
+			w := httptest.NewRecorder()
+
+			h.GetWallets(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, query)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		status := models.Wallet_status_frozen
+		maxBalance := int64(1000)
+		expected := models.GetWalletsRequest{
+			Limit:      10,
+			Cursor:     "abc",
+			Sort:       models.Wallets_sort_updated,
+			Order:      models.Sort_order_desc,
+			Status:     &status,
+			MaxBalance: &maxBalance,
+		}
+		resp := models.GetWalletsResponse{Wallets: []models.Wallet{{ID: uuid.New()}}, NextCursor: "next"}
+		mockService.On("GetWallets", mock.Anything, expected).Return(resp, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets?limit=10&cursor=abc&sort=updated&order=desc&status=frozen&max_balance=1000", nil)
+		w := httptest.NewRecorder()
+
+		h.GetWallets(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var result models.GetWalletsResponse
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Equal(t, "next", result.NextCursor)
+		assert.Len(t, result.Wallets, 1)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_SetSpendingLimits(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	walletID := uuid.New()
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/"+walletID.String()+"/spending-limits", strings.NewReader(body))
+		req.SetPathValue("WALLET_UUID", walletID.String())
+		w := httptest.NewRecorder()
+		h.SetSpendingLimits(w, req)
+		return w
+	}
+
+	t.Run("zero limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`{"maxDailyWithdrawal":0}`).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		daily, hourly := int64(100000), int64(10)
+		limits := models.SpendingLimits{MaxDailyWithdrawal: &daily, MaxHourlyOperations: &hourly}
+		mockService.On("SetSpendingLimits", mock.Anything, walletID, limits).Return(limits, nil).Once()
+
+		w := send(`{"maxDailyWithdrawal":100000,"maxHourlyOperations":10}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_ChangeBalanceBatch(t *testing.T) {
+	mockService := new(services.WalletServiceMock)
+	h := handlers.NewWalletHandler(mockService)
+
+	walletID := uuid.New()
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/wallet/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ChangeBalanceBatch(w, req)
+		return w
+	}
+	item := `{"valletId":"` + walletID.String() + `","operationType":"DEPOSIT","amount":100,"currency":"RUB"}`
+
+	t.Run("incorrect mode", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`{"mode":"all","items":[`+item+`]}`).Code)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`{"mode":"atomic","items":[]}`).Code)
+	})
+
+	t.Run("incorrect item", func(t *testing.T) {
+		w := send(`{"mode":"atomic","items":[` + item + `,{"valletId":"` + walletID.String() + `","operationType":"DEPOSIT","amount":0,"currency":"RUB"}]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "item 1")
+	})
+
+	t.Run("atomic batch rolled back", func(t *testing.T) {
+		resp := models.BatchChangeBalanceResponse{Failed: 1, Results: []models.BatchItemResult{{Index: 0, Status: models.Batch_item_failed}}}
+		mockService.On("ChangeWalletBalanceBatch", mock.Anything, mock.Anything).Return(resp, nil).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, send(`{"mode":"atomic","items":[`+item+`]}`).Code)
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		resp := models.BatchChangeBalanceResponse{Failed: 1, Results: []models.BatchItemResult{{Index: 0, Status: models.Batch_item_failed}}}
+		mockService.On("ChangeWalletBalanceBatch", mock.Anything, mock.Anything).Return(resp, nil).Once()
+
+		assert.Equal(t, http.StatusOK, send(`{"mode":"best_effort","items":[`+item+`]}`).Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRequestWallets(t *testing.T) {
+	first, second := uuid.New().String(), uuid.New().String()
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		wallets []string
+	}{
+		{"change balance", "/wallet", `{"valletId":"` + first + `","operationType":"DEPOSIT","amount":100}`, []string{first}},
+		{"batch", "/wallet/batch", `{"mode":"atomic","items":[{"valletId":"` + first + `"},{"valletId":"` + second + `"},{"valletId":"` + first + `"}]}`, []string{first, second}},
+		{"transfer", "/transfers", `{"fromWalletId":"` + first + `","toWalletId":"` + second + `","amount":100}`, []string{first}},
+		{"path", "/wallets/" + first + "/holds", `{"amount":100}`, []string{first}},
+		{"upper case path", "/wallets/" + strings.ToUpper(first) + "/holds", `{"amount":100}`, []string{first}},
+		{"invalid path", "/wallets/wallet/holds", `{"amount":100}`, nil},
+		{"no wallet", "/wallets", `{"currency":"RUB"}`, nil},
+		{"unreadable body", "/wallet", `{`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			var wallets []string
+			handle := func(w http.ResponseWriter, r *http.Request) {
+				wallets = handlers.RequestWallets(r)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tt.body, string(body), "body is left for the handler")
+			}
+			mux.HandleFunc("POST /wallets/{WALLET_UUID}/holds", handle)
+			mux.HandleFunc("POST /", handle)
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wallets, wallets)
+		})
+	}
+	t.Run("too large body", func(t *testing.T) {
+		body := `{"valletId":"` + first + `","comment":"` + strings.Repeat("a", models.Max_body_bytes) + `"}`
+		r := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(body))
+		assert.Nil(t, handlers.RequestWallets(r))
+		_, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		assert.ErrorAs(t, err, &tooLarge, "the handler refuses the body")
+	})
+}
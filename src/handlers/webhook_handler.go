@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/pkg/httpserver/utils"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/goccy/go-json"
+)
+
+type WebhookHandler interface {
+	Register(s httpserver.Router)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+	FindWebhookById(w http.ResponseWriter, r *http.Request)
+	EnableWebhook(w http.ResponseWriter, r *http.Request)
+	DisableWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookHandler struct {
+	webhookService services.WebhookService
+}
+
+func NewWebhookHandler(webhookService services.WebhookService) WebhookHandler {
+	return &webhookHandler{webhookService: webhookService}
+}
+
+// Register adds the webhook admin routes, all of them require AdminUserHeader.
+func (h *webhookHandler) Register(s httpserver.Router) {
+	s.POST("/webhooks", requireAdmin(h.CreateWebhook)).
+		GET("/webhooks", requireAdmin(h.GetWebhooks)).
+		GET("/webhooks/{WEBHOOK_UUID}", requireAdmin(h.FindWebhookById)).
+		POST("/webhooks/{WEBHOOK_UUID}/enable", requireAdmin(h.EnableWebhook)).
+		POST("/webhooks/{WEBHOOK_UUID}/disable", requireAdmin(h.DisableWebhook)).
+		GET("/webhooks/{WEBHOOK_UUID}/deliveries", requireAdmin(h.GetWebhookDeliveries)).
+		POST("/webhooks/deliveries/{DELIVERY_UUID}/replay", requireAdmin(h.ReplayWebhookDelivery))
+}
+
+func (h *webhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var webhookReq models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookReq); err != nil {
+		utils.RespondError(w, http.StatusUnprocessableEntity, "unable read request body")
+		return
+	}
+	if msg := validateCreateWebhookRequest(webhookReq); msg != "" {
+		utils.RespondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	webhook, errResp := h.webhookService.CreateWebhook(r.Context(), webhookReq)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusCreated, webhook)
+}
+
+func (h *webhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	webhooks, errResp := h.webhookService.GetWebhooks(r.Context())
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *webhookHandler) FindWebhookById(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WEBHOOK_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect webhook id")
+		return
+	}
+	webhook, errResp := h.webhookService.GetWebhookByID(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, webhook)
+}
+
+func (h *webhookHandler) EnableWebhook(w http.ResponseWriter, r *http.Request) {
+	h.setWebhookActive(w, r, true)
+}
+
+func (h *webhookHandler) DisableWebhook(w http.ResponseWriter, r *http.Request) {
+	h.setWebhookActive(w, r, false)
+}
+
+func (h *webhookHandler) setWebhookActive(w http.ResponseWriter, r *http.Request, active bool) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WEBHOOK_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect webhook id")
+		return
+	}
+	webhook, errResp := h.webhookService.SetWebhookActive(r.Context(), id, active)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, webhook)
+}
+
+func (h *webhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "WEBHOOK_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect webhook id")
+		return
+	}
+	req, err := parseGetWebhookDeliveriesRequest(r)
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.WebhookID = id
+	deliveries, errResp := h.webhookService.GetWebhookDeliveries(r.Context(), req)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *webhookHandler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := extractPathValue(r, "DELIVERY_UUID")
+	if err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "incorrect delivery id")
+		return
+	}
+	delivery, errResp := h.webhookService.ReplayWebhookDelivery(r.Context(), id)
+	if errResp != nil {
+		utils.RespondJSON(w, errResp.Code, errResp)
+		return
+	}
+	utils.RespondJSON(w, http.StatusAccepted, delivery)
+}
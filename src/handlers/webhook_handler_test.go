@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/httpserver"
+	"wallet-api/src/handlers"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// muxRouter registers the routes of a handler on mux, so the tests go
+// through the same path patterns as the server.
+func muxRouter(mux *http.ServeMux) httpserver.Router {
+	return httpserver.NewRouter(func(method, path string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+path, handler)
+	})
+}
+
+func TestWebhookHandler(t *testing.T) {
+	mockService := new(services.WebhookServiceMock)
+	mux := http.NewServeMux()
+	handlers.NewWebhookHandler(mockService).Register(muxRouter(mux))
+	id := uuid.New()
+
+	send := func(admin bool, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if admin {
+			req.Header.Set(handlers.AdminUserHeader, "admin")
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("without admin user", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(false, http.MethodGet, "/webhooks", "").Code)
+	})
+
+	t.Run("principal without admin scope", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: "shop"}))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("create incorrect url", func(t *testing.T) {
+		w := send(true, http.MethodPost, "/webhooks", `{"url":"ftp://example.com","eventTypes":["balance.changed"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("create unknown event type", func(t *testing.T) {
+		w := send(true, http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","eventTypes":["wallet.deleted"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "wallet.deleted")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		req := models.CreateWebhookRequest{URL: "https://example.com/hook", EventTypes: []string{"wallet.created", "hold.expired"}}
+		mockService.On("CreateWebhook", mock.Anything, req).Return(models.Webhook{ID: id, Secret: "s3cret"}, nil).Once()
+
+		w := send(true, http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","eventTypes":["wallet.created","hold.expired"]}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "s3cret")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		mockService.On("SetWebhookActive", mock.Anything, id, false).Return(models.Webhook{ID: id}, nil).Once()
+
+		w := send(true, http.MethodPost, "/webhooks/"+id.String()+"/disable", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("deliveries incorrect status", func(t *testing.T) {
+		w := send(true, http.MethodGet, "/webhooks/"+id.String()+"/deliveries?status=lost", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("dead deliveries", func(t *testing.T) {
+		dead := models.Webhook_delivery_dead
+		req := models.GetWebhookDeliveriesRequest{WebhookID: id, Status: &dead, Limit: 10}
+		mockService.On("GetWebhookDeliveries", mock.Anything, req).Return(models.GetWebhookDeliveriesResponse{}, nil).Once()
+
+		w := send(true, http.MethodGet, "/webhooks/"+id.String()+"/deliveries?status=dead&limit=10", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		deliveryID := uuid.New()
+		mockService.On("ReplayWebhookDelivery", mock.Anything, deliveryID).Return(models.WebhookDelivery{ID: deliveryID, Status: models.Webhook_delivery_pending}, nil).Once()
+
+		w := send(true, http.MethodPost, "/webhooks/deliveries/"+deliveryID.String()+"/replay", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("replay incorrect id", func(t *testing.T) {
+		w := send(true, http.MethodPost, "/webhooks/deliveries/123/replay", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateFXQuoteRequest struct {
+	FromID uuid.UUID `json:"fromWalletId"`
+	ToID   uuid.UUID `json:"toWalletId"`
+	Amount int64     `json:"amount"`
+}
+
+type FXQuote struct {
+	ID              uuid.UUID `json:"id"`
+	FromWalletID    uuid.UUID `json:"fromWalletId"`
+	ToWalletID      uuid.UUID `json:"toWalletId"`
+	FromCurrency    string    `json:"fromCurrency"`
+	ToCurrency      string    `json:"toCurrency"`
+	Amount          int64     `json:"amount"`
+	ConvertedAmount int64     `json:"convertedAmount"`
+	Rate            string    `json:"rate"`
+	Rounding        string    `json:"rounding"`
+	Created         time.Time `json:"created"`
+	Expires         time.Time `json:"expires"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Hold_status_active   = "active"
+	Hold_status_captured = "captured"
+	Hold_status_voided   = "voided"
+	Hold_status_expired  = "expired"
+)
+
+// CreateHoldRequest reserves Amount in the wallet currency. TTL is in
+// seconds, the configured default is used when it is zero.
+type CreateHoldRequest struct {
+	Amount int64 `json:"amount"`
+	TTL    int64 `json:"ttlSeconds"`
+}
+
+// CaptureHoldRequest debits Amount of the hold, the whole hold when it is nil.
+type CaptureHoldRequest struct {
+	Amount *int64 `json:"amount,omitempty"`
+}
+
+type Hold struct {
+	ID             uuid.UUID  `json:"id"`
+	WalletID       uuid.UUID  `json:"walletId"`
+	Amount         int64      `json:"amount"`
+	CapturedAmount *int64     `json:"capturedAmount,omitempty"`
+	Status         string     `json:"status"`
+	TransactionID  *uuid.UUID `json:"transactionId,omitempty"`
+	Created        time.Time  `json:"created"`
+	Expires        time.Time  `json:"expires"`
+	Updated        time.Time  `json:"updated"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	Default_currency   = "RUB"
+	Rounding_mode_down = "down"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrAmountOverflow   = errors.New("amount overflow")
+	ErrIncorrectRate    = errors.New("incorrect rate")
+)
+
+// Currency is an ISO 4217 currency, amounts in it are kept in minor units:
+// Exponent is the number of minor unit digits, 2 for kopecks and cents.
+type Currency struct {
+	Code     string
+	Exponent int
+}
+
+var currencies = map[string]Currency{
+	"RUB": {Code: "RUB", Exponent: 2},
+	"USD": {Code: "USD", Exponent: 2},
+	"EUR": {Code: "EUR", Exponent: 2},
+	"GBP": {Code: "GBP", Exponent: 2},
+	"CNY": {Code: "CNY", Exponent: 2},
+	"KZT": {Code: "KZT", Exponent: 2},
+	"BYN": {Code: "BYN", Exponent: 2},
+	"UZS": {Code: "UZS", Exponent: 2},
+	"AMD": {Code: "AMD", Exponent: 2},
+	"GEL": {Code: "GEL", Exponent: 2},
+	"TRY": {Code: "TRY", Exponent: 2},
+	"AED": {Code: "AED", Exponent: 2},
+	"JPY": {Code: "JPY", Exponent: 0},
+	"KRW": {Code: "KRW", Exponent: 0},
+	"KWD": {Code: "KWD", Exponent: 3},
+	"BHD": {Code: "BHD", Exponent: 3},
+}
+
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[code]
+	return currency, ok
+}
+
+// Money is an exact amount of minor units of a single currency. Arithmetic
+// between different currencies is refused instead of silently mixing them.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func NewMoney(amount int64, code string) (Money, error) {
+	currency, ok := LookupCurrency(code)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Convert exchanges m into currency to. Rate is a decimal string with the
+// number of major units of to given for one major unit of m. The result is
+// rounded down to whole minor units of to, see Rounding_mode_down.
+func (m Money) Convert(to Currency, rate string) (Money, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return Money{}, ErrIncorrectRate
+	}
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Mul(value, r)
+	shift := to.Exponent - m.Currency.Exponent
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(shift))), nil))
+	if shift >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: converted.Int64(), Currency: to}, nil
+}
+
+// String formats the amount in major units, e.g. "12.34 USD".
+func (m Money) String() string {
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absInt64(amount), 10)
+	if exp := m.Currency.Exponent; exp > 0 {
+		if len(digits) <= exp {
+			digits = strings.Repeat("0", exp-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+	}
+	return fmt.Sprintf("%s%s %s", sign, digits, m.Currency.Code)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func absInt64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"math"
+	"testing"
+	"wallet-api/src/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Add(t *testing.T) {
+	usd := func(amount int64) models.Money {
+		money, _ := models.NewMoney(amount, "USD")
+		return money
+	}
+
+	t.Run("same currency", func(t *testing.T) {
+		sum, err := usd(150).Add(usd(250))
+		assert.NoError(t, err)
+		assert.Equal(t, usd(400), sum)
+	})
+
+	t.Run("cross currency", func(t *testing.T) {
+		eur, _ := models.NewMoney(100, "EUR")
+		_, err := usd(100).Add(eur)
+		assert.ErrorIs(t, err, models.ErrCurrencyMismatch)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		_, err := usd(math.MaxInt64).Add(usd(1))
+		assert.ErrorIs(t, err, models.ErrAmountOverflow)
+
+		_, err = usd(math.MinInt64).Sub(usd(1))
+		assert.ErrorIs(t, err, models.ErrAmountOverflow)
+	})
+
+	t.Run("sub", func(t *testing.T) {
+		diff, err := usd(100).Sub(usd(250))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-150), diff.Amount)
+	})
+}
+
+func TestMoney_String(t *testing.T) {
+	cases := map[string]struct {
+		amount int64
+		code   string
+	}{
+		"12.34 USD":  {1234, "USD"},
+		"0.05 RUB":   {5, "RUB"},
+		"-1.000 KWD": {-1000, "KWD"},
+		"500 JPY":    {500, "JPY"},
+	}
+	for expected, c := range cases {
+		money, err := models.NewMoney(c.amount, c.code)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, money.String())
+	}
+}
+
+func TestNewMoney_UnknownCurrency(t *testing.T) {
+	_, err := models.NewMoney(100, "XXX")
+	assert.ErrorIs(t, err, models.ErrUnknownCurrency)
+}
+
+func TestMoney_Convert(t *testing.T) {
+	usd, _ := models.LookupCurrency("USD")
+	jpy, _ := models.LookupCurrency("JPY")
+	kwd, _ := models.LookupCurrency("KWD")
+
+	t.Run("rounded down", func(t *testing.T) {
+		amount, _ := models.NewMoney(1001, "RUB")
+		converted, err := amount.Convert(usd, "0.011093")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Money{Amount: 11, Currency: usd}, converted)
+	})
+
+	t.Run("different exponents", func(t *testing.T) {
+		amount, _ := models.NewMoney(1050, "USD")
+		converted, err := amount.Convert(jpy, "150.5")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1580), converted.Amount)
+
+		converted, err = amount.Convert(kwd, "0.307")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3223), converted.Amount)
+	})
+
+	t.Run("incorrect rate", func(t *testing.T) {
+		amount, _ := models.NewMoney(100, "USD")
+		_, err := amount.Convert(jpy, "-1")
+		assert.ErrorIs(t, err, models.ErrIncorrectRate)
+		_, err = amount.Convert(jpy, "abc")
+		assert.ErrorIs(t, err, models.ErrIncorrectRate)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		amount, _ := models.NewMoney(math.MaxInt64, "USD")
+		_, err := amount.Convert(jpy, "150")
+		assert.ErrorIs(t, err, models.ErrAmountOverflow)
+	})
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SpendingLimits restrict withdrawals, outgoing transfers and hold captures.
+// Days and months start at midnight UTC, limits left empty are not applied.
+type SpendingLimits struct {
+	MaxSingleWithdrawal  *int64     `json:"maxSingleWithdrawal"`
+	MaxDailyWithdrawal   *int64     `json:"maxDailyWithdrawal"`
+	MaxMonthlyWithdrawal *int64     `json:"maxMonthlyWithdrawal"`
+	MaxHourlyOperations  *int64     `json:"maxHourlyOperations"`
+	Updated              *time.Time `json:"updated,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Transaction_type_deposit      = "deposit"
+	Transaction_type_withdraw     = "withdraw"
+	Transaction_type_transfer_out = "transfer_out"
+	Transaction_type_transfer_in  = "transfer_in"
+	Transaction_type_capture      = "capture"
+	Transaction_type_reversal     = "reversal"
+)
+
+type Transaction struct {
+	ID                   uuid.UUID  `json:"id"`
+	WalletID             uuid.UUID  `json:"walletId"`
+	Type                 string     `json:"type"`
+	Amount               int64      `json:"amount"`
+	BalanceAfter         int64      `json:"balanceAfter"`
+	CounterpartyWalletID *uuid.UUID `json:"counterpartyWalletId,omitempty"`
+	FXQuoteID            *uuid.UUID `json:"fxQuoteId,omitempty"`
+	FXRate               *string    `json:"fxRate,omitempty"`
+	FXRounding           *string    `json:"fxRounding,omitempty"`
+	ReversalOf           *uuid.UUID `json:"reversalOf,omitempty"`
+	Created              time.Time  `json:"created"`
+}
+
+type GetTransactionsRequest struct {
+	WalletID uuid.UUID
+	Limit    int
+	Cursor   string
+	Order    string
+	From     *time.Time
+	To       *time.Time
+	Types    []string
+}
+
+type GetTransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
+
+// ReverseTransactionRequest reverses Amount of the transaction, all of it
+// when Amount is nil.
+type ReverseTransactionRequest struct {
+	Amount *int64 `json:"amount,omitempty"`
+}
+
+type ReverseTransactionResponse struct {
+	Transactions []Transaction `json:"transactions"`
+}
@@ -1,27 +1,135 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 const (
 	Operation_type_deposit  = "DEPOSIT"
 	Operation_type_withdraw = "WITHDRAW"
 )
 
+const (
+	Wallet_status_active = "active"
+	Wallet_status_frozen = "frozen"
+	Wallet_status_closed = "closed"
+)
+
 type Wallet struct {
-	ID      uuid.UUID `json:"id"`
-	Balance int64     `json:"balance"`
+	ID          uuid.UUID `json:"id"`
+	Balance     int64     `json:"balance"`
+	Held        int64     `json:"held"`
+	CreditLimit int64     `json:"creditLimit"`
+	MinBalance  *int64    `json:"minBalance,omitempty"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	Owner       *string   `json:"owner,omitempty"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type CreateWalletRequest struct {
+	Currency string `json:"currency"`
+}
+
+const (
+	Wallets_sort_created = "created"
+	Wallets_sort_updated = "updated"
+	Wallets_sort_balance = "balance"
+
+	Sort_order_asc  = "asc"
+	Sort_order_desc = "desc"
+
+	Default_page_limit = 50
+	Max_page_limit     = 500
+)
+
+type GetWalletsRequest struct {
+	Limit      int
+	Cursor     string
+	Sort       string
+	Order      string
+	Status     *string
+	MinBalance *int64
+	MaxBalance *int64
 }
 
 type GetWalletsResponse struct {
-	ID uuid.UUID `json:"id"`
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"nextCursor,omitempty"`
 }
 
+// GetBalanceResponse shows the whole balance, the part of it reserved by
+// active holds and the rest that can be spent.
 type GetBalanceResponse struct {
-	Balance int64 `json:"balance"`
+	Balance     int64  `json:"balance"`
+	Held        int64  `json:"held"`
+	Available   int64  `json:"available"`
+	CreditLimit int64  `json:"creditLimit"`
+	MinBalance  *int64 `json:"minBalance,omitempty"`
+	Currency    string `json:"currency"`
+}
+
+// ChangeWalletLimitsRequest sets how far the wallet balance may go down:
+// to MinBalance (zero when it is not set) minus CreditLimit. Reason is kept
+// in the audit record.
+type ChangeWalletLimitsRequest struct {
+	CreditLimit int64  `json:"creditLimit"`
+	MinBalance  *int64 `json:"minBalance"`
+	Reason      string `json:"reason"`
 }
 
 type ChangeBalanceRequest struct {
 	ID            uuid.UUID `json:"valletId"`
 	Balance       int64     `json:"amount"`
+	Currency      string    `json:"currency"`
 	OperationType string    `json:"operationType"`
 }
+
+const (
+	Batch_mode_atomic      = "atomic"
+	Batch_mode_best_effort = "best_effort"
+
+	Batch_item_applied     = "applied"
+	Batch_item_failed      = "failed"
+	Batch_item_rolled_back = "rolled_back"
+	Batch_item_skipped     = "skipped"
+
+	Max_batch_items = 10000
+	// Max_body_bytes fits a batch of Max_batch_items
+	Max_body_bytes = 4 << 20
+)
+
+// BatchChangeBalanceRequest applies Items in order. In atomic mode either all
+// of them are applied or none, in best_effort mode the refused items are
+// skipped and the rest are applied.
+type BatchChangeBalanceRequest struct {
+	Mode  string                 `json:"mode"`
+	Items []ChangeBalanceRequest `json:"items"`
+}
+
+type BatchItemResult struct {
+	Index         int            `json:"index"`
+	Status        string         `json:"status"`
+	TransactionID *uuid.UUID     `json:"transactionId,omitempty"`
+	BalanceAfter  *int64         `json:"balanceAfter,omitempty"`
+	Error         *ErrorResponse `json:"error,omitempty"`
+}
+
+type BatchChangeBalanceResponse struct {
+	Applied int               `json:"applied"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// TransferRequest moves Amount between wallets in the same currency. For
+// wallets in different currencies only QuoteID of a quote created before is
+// needed, the wallets and amounts are taken from the quote.
+type TransferRequest struct {
+	FromID  uuid.UUID  `json:"fromWalletId"`
+	ToID    uuid.UUID  `json:"toWalletId"`
+	Amount  int64      `json:"amount"`
+	QuoteID *uuid.UUID `json:"quoteId,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletEvent is sent to the wallet event stream, ID is the stream position
+// the client resumes from.
+type WalletEvent struct {
+	ID       int64           `json:"id"`
+	Type     string          `json:"type"`
+	WalletID uuid.UUID       `json:"walletId"`
+	Created  time.Time       `json:"created"`
+	Payload  json.RawMessage `json:"payload"`
+}
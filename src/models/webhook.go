@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Webhook_event_wallet_created  = "wallet.created"
+	Webhook_event_balance_changed = "balance.changed"
+	Webhook_event_hold_expired    = "hold.expired"
+
+	Webhook_delivery_pending   = "pending"
+	Webhook_delivery_delivered = "delivered"
+	Webhook_delivery_dead      = "dead"
+)
+
+// CreateWebhookRequest subscribes URL to EventTypes. Secret signs the
+// deliveries, a random one is generated when it is empty.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+// Webhook is a subscription. Secret is shown only in the response to create.
+type Webhook struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
+
+type GetWebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"webhookId"`
+	EventID        int64      `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttempt    time.Time  `json:"nextAttempt"`
+	LastStatus     *int       `json:"lastStatus,omitempty"`
+	LastError      *string    `json:"lastError,omitempty"`
+	Created        time.Time  `json:"created"`
+	Delivered      *time.Time `json:"delivered,omitempty"`
+}
+
+// GetWebhookDeliveriesRequest lists the latest Limit deliveries, only the
+// ones in Status when it is set.
+type GetWebhookDeliveriesRequest struct {
+	WebhookID uuid.UUID
+	Status    *string
+	Limit     int
+}
+
+type GetWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
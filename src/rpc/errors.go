@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"net/http"
+	"wallet-api/src/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError maps the http code of a service error to the gRPC one, the
+// message is kept as it is.
+func statusError(errResp *models.ErrorResponse) error {
+	return status.Error(statusCode(errResp.Code), errResp.Message)
+}
+
+func statusCode(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
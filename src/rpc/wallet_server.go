@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"context"
+	"wallet-api/pkg/api/wallet/v1"
+	"wallet-api/pkg/grpcserver"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WalletServer interface {
+	walletv1.WalletServiceServer
+	Register(s grpc.ServiceRegistrar)
+}
+
+type walletServer struct {
+	walletv1.UnimplementedWalletServiceServer
+	walletService      services.WalletService
+	walletEventService services.WalletEventService
+}
+
+// NewWalletServer serves the same services.WalletService as the REST
+// handlers.
+func NewWalletServer(walletService services.WalletService, walletEventService services.WalletEventService) WalletServer {
+	return &walletServer{walletService: walletService, walletEventService: walletEventService}
+}
+
+func (s *walletServer) Register(r grpc.ServiceRegistrar) {
+	walletv1.RegisterWalletServiceServer(r, s)
+}
+
+func (s *walletServer) GetBalance(ctx context.Context, req *walletv1.GetBalanceRequest) (*walletv1.GetBalanceResponse, error) {
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect wallet id")
+	}
+	balance, errResp := s.walletService.GetWalletByID(ctx, id)
+	if errResp != nil {
+		return nil, statusError(errResp)
+	}
+	return &walletv1.GetBalanceResponse{
+		Balance:     balance.Balance,
+		Held:        balance.Held,
+		Available:   balance.Available,
+		CreditLimit: balance.CreditLimit,
+		MinBalance:  balance.MinBalance,
+		Currency:    balance.Currency,
+	}, nil
+}
+
+func (s *walletServer) ChangeBalance(ctx context.Context, req *walletv1.ChangeBalanceRequest) (*walletv1.ChangeBalanceResponse, error) {
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect wallet id")
+	}
+	changeReq := models.ChangeBalanceRequest{ID: id, Balance: req.GetAmount(), Currency: req.GetCurrency()}
+	switch req.GetOperationType() {
+	case walletv1.OperationType_OPERATION_TYPE_DEPOSIT:
+		changeReq.OperationType = models.Operation_type_deposit
+	case walletv1.OperationType_OPERATION_TYPE_WITHDRAW:
+		changeReq.OperationType = models.Operation_type_withdraw
+	default:
+		return nil, status.Error(codes.InvalidArgument, "incorrect operation type")
+	}
+	if changeReq.Balance <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be more than zero")
+	}
+	if _, ok := models.LookupCurrency(changeReq.Currency); changeReq.Currency != "" && !ok {
+		return nil, status.Error(codes.InvalidArgument, "incorrect currency")
+	}
+	if errResp := s.walletService.ChangeWalletBalance(ctx, changeReq); errResp != nil {
+		return nil, statusError(errResp)
+	}
+	return &walletv1.ChangeBalanceResponse{}, nil
+}
+
+func (s *walletServer) ListWallets(ctx context.Context, req *walletv1.ListWalletsRequest) (*walletv1.ListWalletsResponse, error) {
+	listReq := models.GetWalletsRequest{
+		Limit:      int(req.GetLimit()),
+		Cursor:     req.GetCursor(),
+		Sort:       req.GetSort(),
+		Order:      req.GetOrder(),
+		Status:     req.Status,
+		MinBalance: req.MinBalance,
+		MaxBalance: req.MaxBalance,
+	}
+	if listReq.Limit < 0 || listReq.Limit > models.Max_page_limit {
+		return nil, status.Error(codes.InvalidArgument, "incorrect limit")
+	}
+	switch listReq.Sort {
+	case "", models.Wallets_sort_created, models.Wallets_sort_updated, models.Wallets_sort_balance:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "incorrect sort")
+	}
+	switch listReq.Order {
+	case "", models.Sort_order_asc, models.Sort_order_desc:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "incorrect order")
+	}
+	if listReq.Status != nil {
+		switch *listReq.Status {
+		case models.Wallet_status_active, models.Wallet_status_frozen, models.Wallet_status_closed:
+		default:
+			return nil, status.Error(codes.InvalidArgument, "incorrect status")
+		}
+	}
+	wallets, errResp := s.walletService.GetWallets(ctx, listReq)
+	if errResp != nil {
+		return nil, statusError(errResp)
+	}
+	resp := &walletv1.ListWalletsResponse{
+		Wallets:    make([]*walletv1.Wallet, 0, len(wallets.Wallets)),
+		NextCursor: wallets.NextCursor,
+	}
+	for _, wallet := range wallets.Wallets {
+		resp.Wallets = append(resp.Wallets, &walletv1.Wallet{
+			Id:          wallet.ID.String(),
+			Balance:     wallet.Balance,
+			Held:        wallet.Held,
+			CreditLimit: wallet.CreditLimit,
+			MinBalance:  wallet.MinBalance,
+			Currency:    wallet.Currency,
+			Status:      wallet.Status,
+			Created:     timestamppb.New(wallet.Created),
+			Updated:     timestamppb.New(wallet.Updated),
+		})
+	}
+	return resp, nil
+}
+
+// WatchWallet works as the REST event stream, both of them are on top of
+// services.WalletWatch.
+func (s *walletServer) WatchWallet(req *walletv1.WatchWalletRequest, stream grpc.ServerStreamingServer[walletv1.WalletEvent]) error {
+	ctx := stream.Context()
+	id, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "incorrect wallet id")
+	}
+	if req.AfterEventId != nil && *req.AfterEventId < 0 {
+		return status.Error(codes.InvalidArgument, "incorrect after event id")
+	}
+	if _, errResp := s.walletService.GetWalletByID(ctx, id); errResp != nil {
+		return statusError(errResp)
+	}
+	watch := s.walletEventService.Watch(id)
+	defer watch.Stop()
+	send := func(event models.WalletEvent) error {
+		return stream.Send(toWalletEvent(event))
+	}
+	if req.AfterEventId != nil {
+		errResp, err := watch.Replay(ctx, *req.AfterEventId, send)
+		if errResp != nil {
+			return statusError(errResp)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-grpcserver.ShuttingDown(ctx):
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-watch.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "watch fell behind, resume with after_event_id")
+			}
+			if watch.Replayed(event) {
+				continue
+			}
+			if err = send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toWalletEvent(event models.WalletEvent) *walletv1.WalletEvent {
+	return &walletv1.WalletEvent{
+		Id:       event.ID,
+		Type:     event.Type,
+		WalletId: event.WalletID.String(),
+		Created:  timestamppb.New(event.Created),
+		Payload:  event.Payload,
+	}
+}
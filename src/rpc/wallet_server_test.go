@@ -0,0 +1,177 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"wallet-api/pkg/api/wallet/v1"
+	"wallet-api/src/models"
+	"wallet-api/src/rpc"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, walletService services.WalletService, eventService services.WalletEventService) walletv1.WalletServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	rpc.NewWalletServer(walletService, eventService).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+func TestWalletServer_GetBalance(t *testing.T) {
+	walletService := new(services.WalletServiceMock)
+	client := newClient(t, walletService, new(services.WalletEventServiceMock))
+	id := uuid.New()
+
+	t.Run("incorrect id", func(t *testing.T) {
+		_, err := client.GetBalance(context.Background(), &walletv1.GetBalanceRequest{WalletId: "abc"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		walletService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{}, &models.ErrorResponse{Code: http.StatusNotFound, Message: "wallet not found"}).Once()
+
+		_, err := client.GetBalance(context.Background(), &walletv1.GetBalanceRequest{WalletId: id.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "wallet not found", status.Convert(err).Message())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		walletService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{Balance: 100, Held: 30, Available: 70, Currency: "RUB"}, nil).Once()
+
+		resp, err := client.GetBalance(context.Background(), &walletv1.GetBalanceRequest{WalletId: id.String()})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), resp.GetBalance())
+		assert.Equal(t, int64(70), resp.GetAvailable())
+		assert.Equal(t, "RUB", resp.GetCurrency())
+		assert.Nil(t, resp.MinBalance)
+	})
+	walletService.AssertExpectations(t)
+}
+
+func TestWalletServer_ChangeBalance(t *testing.T) {
+	walletService := new(services.WalletServiceMock)
+	client := newClient(t, walletService, new(services.WalletEventServiceMock))
+	id := uuid.New()
+
+	tests := []struct {
+		name string
+		req  *walletv1.ChangeBalanceRequest
+	}{
+		{"unspecified operation", &walletv1.ChangeBalanceRequest{WalletId: id.String(), Amount: 10}},
+		{"zero amount", &walletv1.ChangeBalanceRequest{WalletId: id.String(), OperationType: walletv1.OperationType_OPERATION_TYPE_DEPOSIT}},
+		{"incorrect currency", &walletv1.ChangeBalanceRequest{WalletId: id.String(), OperationType: walletv1.OperationType_OPERATION_TYPE_DEPOSIT, Amount: 10, Currency: "XXX"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ChangeBalance(context.Background(), tt.req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		walletService.On("ChangeWalletBalance", mock.Anything, models.ChangeBalanceRequest{ID: id, OperationType: models.Operation_type_withdraw, Balance: 10, Currency: "RUB"}).
+			Return(&models.ErrorResponse{Code: http.StatusConflict, Message: "insufficient funds"}).Once()
+
+		_, err := client.ChangeBalance(context.Background(), &walletv1.ChangeBalanceRequest{WalletId: id.String(), OperationType: walletv1.OperationType_OPERATION_TYPE_WITHDRAW, Amount: 10, Currency: "RUB"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		walletService.On("ChangeWalletBalance", mock.Anything, models.ChangeBalanceRequest{ID: id, OperationType: models.Operation_type_deposit, Balance: 10, Currency: "USD"}).
+			Return(nil).Once()
+
+		_, err := client.ChangeBalance(context.Background(), &walletv1.ChangeBalanceRequest{WalletId: id.String(), OperationType: walletv1.OperationType_OPERATION_TYPE_DEPOSIT, Amount: 10, Currency: "USD"})
+		assert.NoError(t, err)
+	})
+	walletService.AssertExpectations(t)
+}
+
+func TestWalletServer_ListWallets(t *testing.T) {
+	walletService := new(services.WalletServiceMock)
+	client := newClient(t, walletService, new(services.WalletEventServiceMock))
+
+	t.Run("incorrect sort", func(t *testing.T) {
+		_, err := client.ListWallets(context.Background(), &walletv1.ListWalletsRequest{Sort: "name"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		id := uuid.New()
+		walletService.On("GetWallets", mock.Anything, models.GetWalletsRequest{Limit: 1}).Return(models.GetWalletsResponse{
+			Wallets:    []models.Wallet{{ID: id, Balance: 5, Currency: "RUB", Status: models.Wallet_status_active}},
+			NextCursor: "next",
+		}, nil).Once()
+
+		resp, err := client.ListWallets(context.Background(), &walletv1.ListWalletsRequest{Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, "next", resp.GetNextCursor())
+		if assert.Len(t, resp.GetWallets(), 1) {
+			assert.Equal(t, id.String(), resp.GetWallets()[0].GetId())
+			assert.Equal(t, int64(5), resp.GetWallets()[0].GetBalance())
+		}
+	})
+	walletService.AssertExpectations(t)
+}
+
+func TestWalletServer_WatchWallet(t *testing.T) {
+	walletService := new(services.WalletServiceMock)
+	eventService := new(services.WalletEventServiceMock)
+	client := newClient(t, walletService, eventService)
+	id := uuid.New()
+
+	live := make(chan models.WalletEvent, 2)
+	unsubscribed := make(chan struct{})
+	walletService.On("GetWalletByID", mock.Anything, id).Return(models.GetBalanceResponse{}, nil).Once()
+	eventService.On("Subscribe", id).Return((<-chan models.WalletEvent)(live), func() { close(unsubscribed) }).Once()
+	eventService.On("GetEventsAfter", mock.Anything, id, int64(5), mock.Anything).Return([]models.WalletEvent{
+		{ID: 6, Type: "balance.changed", WalletID: id},
+	}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	after := int64(5)
+	stream, err := client.WatchWallet(ctx, &walletv1.WatchWalletRequest{WalletId: id.String(), AfterEventId: &after})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 6 is replayed and also arrives live, it must be sent once
+	live <- models.WalletEvent{ID: 6, Type: "balance.changed", WalletID: id}
+	live <- models.WalletEvent{ID: 7, Type: "hold.expired", WalletID: id}
+	for _, want := range []int64{6, 7} {
+		event, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, want, event.GetId())
+		assert.Equal(t, id.String(), event.GetWalletId())
+	}
+
+	cancel()
+	<-unsubscribed
+	walletService.AssertExpectations(t)
+	eventService.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"wallet-api/pkg/auth"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+)
+
+// restricted tells whether the call is made by a principal limited to its
+// own wallets. Admins and the calls without a principal, made with the
+// authentication disabled or by the background workers, are not.
+func restricted(ctx context.Context) (auth.Principal, bool) {
+	principal, ok := auth.FromContext(ctx)
+	return principal, ok && !principal.HasScope(auth.ScopeAdmin)
+}
+
+// canAccess tells whether the call may touch a wallet of the owner, the
+// wallets without an owner are left to admins.
+func canAccess(ctx context.Context, owner *string) bool {
+	principal, ok := restricted(ctx)
+	return !ok || owner != nil && *owner == principal.ID
+}
+
+// authorizeWallets checks the owners of the wallets the call is about to
+// change. Unknown wallets are let through for the operation to report them.
+func authorizeWallets(ctx context.Context, walletRepo repositories.WalletRepo, ids ...uuid.UUID) *models.ErrorResponse {
+	if _, ok := restricted(ctx); !ok {
+		return nil
+	}
+	owners, err := walletRepo.FindOwners(ctx, ids)
+	if err != nil {
+		return internalError()
+	}
+	for _, id := range ids {
+		if owner, found := owners[id]; found && !canAccess(ctx, owner) {
+			return accessDenied()
+		}
+	}
+	return nil
+}
+
+func requireAdmin(ctx context.Context) *models.ErrorResponse {
+	if _, ok := restricted(ctx); ok {
+		return &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "admin scope is required",
+		}
+	}
+	return nil
+}
+
+func accessDenied() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusForbidden,
+		Message: "wallet access denied",
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+	"wallet-api/src/models"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+// cursorTimeLayout keeps microseconds, the precision of postgres timestamps.
+const cursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+var ErrIncorrectCursor = errors.New("incorrect cursor")
+
+// cursor points at the last item of a page: the value of the column the page
+// is sorted by and the id that breaks ties between equal values. Sort and
+// Order are the ones of the page, the cursor does not fit another listing.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor refuses a cursor given for another sort or order, and one
+// with a value that validValue does not accept, before it gets to the query.
+func decodeCursor(s, sort, order string, validValue func(value string) bool) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrIncorrectCursor
+	}
+	var c cursor
+	if err = json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil {
+		return cursor{}, ErrIncorrectCursor
+	}
+	if c.Sort != sort || c.Order != sortOrder(order) || !validValue(c.Value) {
+		return cursor{}, ErrIncorrectCursor
+	}
+	return c, nil
+}
+
+func sortOrder(order string) string {
+	if order == models.Sort_order_desc {
+		return models.Sort_order_desc
+	}
+	return models.Sort_order_asc
+}
+
+func validCursorTime(value string) bool {
+	_, err := time.Parse(cursorTimeLayout, value)
+	return err == nil
+}
+
+func pageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return models.Default_page_limit
+	case limit > models.Max_page_limit:
+		return models.Max_page_limit
+	default:
+		return limit
+	}
+}
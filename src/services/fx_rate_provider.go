@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrFXRateNotFound = errors.New("fx rate not found")
+
+// FXRateProvider returns the decimal rate: how many units of to are given for
+// one unit of from.
+type FXRateProvider interface {
+	Rate(ctx context.Context, from, to string) (string, error)
+}
+
+type staticRateProvider struct {
+	rates map[string]string
+}
+
+// NewStaticRateProvider serves fixed rates keyed by pair, e.g. "USD/RUB". The
+// reverse pair is derived from the direct one when it is not listed.
+func NewStaticRateProvider(rates map[string]string) FXRateProvider {
+	return &staticRateProvider{rates: rates}
+}
+
+// NewFileRateProvider reads the rates for NewStaticRateProvider from a yaml
+// file with a "rates" map.
+func NewFileRateProvider(path string) (FXRateProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fx rates file: %w", err)
+	}
+	var file struct {
+		Rates map[string]string `yaml:"rates"`
+	}
+	if err = yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode fx rates file: %w", err)
+	}
+	return NewStaticRateProvider(file.Rates), nil
+}
+
+func (p *staticRateProvider) Rate(_ context.Context, from, to string) (string, error) {
+	if rate, ok := p.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[to+"/"+from]; ok {
+		direct, ok := new(big.Rat).SetString(rate)
+		if !ok || direct.Sign() <= 0 {
+			return "", ErrFXRateNotFound
+		}
+		return new(big.Rat).Inv(direct).FloatString(fxInverseRatePrecision), nil
+	}
+	return "", ErrFXRateNotFound
+}
+
+// fxInverseRatePrecision is the number of decimal places kept for a derived
+// reverse rate.
+const fxInverseRatePrecision = 10
@@ -0,0 +1,43 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"wallet-api/src/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticRateProvider_Rate(t *testing.T) {
+	provider := services.NewStaticRateProvider(map[string]string{"USD/RUB": "80"})
+	ctx := context.Background()
+
+	rate, err := provider.Rate(ctx, "USD", "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "80", rate)
+
+	rate, err = provider.Rate(ctx, "RUB", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0125000000", rate)
+
+	_, err = provider.Rate(ctx, "EUR", "RUB")
+	assert.ErrorIs(t, err, services.ErrFXRateNotFound)
+}
+
+func TestFileRateProvider_Rate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	err := os.WriteFile(path, []byte("rates:\n  EUR/USD: \"1.1\"\n"), 0o600)
+	assert.NoError(t, err)
+
+	provider, err := services.NewFileRateProvider(path)
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "EUR", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1", rate)
+
+	_, err = services.NewFileRateProvider(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+const defaultFXQuoteTTL = 30 * time.Second
+
+type FXService interface {
+	CreateQuote(ctx context.Context, quoteReq models.CreateFXQuoteRequest) (models.FXQuote, *models.ErrorResponse)
+}
+
+type fxService struct {
+	walletRepo  repositories.WalletRepo
+	fxQuoteRepo repositories.FXQuoteRepo
+	rates       FXRateProvider
+	quoteTTL    time.Duration
+}
+
+func NewFXService(walletRepo repositories.WalletRepo, fxQuoteRepo repositories.FXQuoteRepo, rates FXRateProvider, quoteTTL time.Duration) FXService {
+	if quoteTTL <= 0 {
+		quoteTTL = defaultFXQuoteTTL
+	}
+	return &fxService{walletRepo: walletRepo, fxQuoteRepo: fxQuoteRepo, rates: rates, quoteTTL: quoteTTL}
+}
+
+// CreateQuote converts the amount at the current rate and stores the result,
+// so the transfer made with the quote id gets exactly the quoted amounts.
+func (s *fxService) CreateQuote(ctx context.Context, quoteReq models.CreateFXQuoteRequest) (models.FXQuote, *models.ErrorResponse) {
+	from, errResp := s.findWallet(ctx, quoteReq.FromID)
+	if errResp != nil {
+		return models.FXQuote{}, errResp
+	}
+	if !canAccess(ctx, from.Owner) {
+		return models.FXQuote{}, accessDenied()
+	}
+	to, errResp := s.findWallet(ctx, quoteReq.ToID)
+	if errResp != nil {
+		return models.FXQuote{}, errResp
+	}
+	if from.Currency == to.Currency {
+		return models.FXQuote{}, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "wallets have the same currency, transfer without quote",
+		}
+	}
+	amount, err := models.NewMoney(quoteReq.Amount, from.Currency)
+	if err != nil {
+		return models.FXQuote{}, internalError()
+	}
+	toCurrency, ok := models.LookupCurrency(to.Currency)
+	if !ok {
+		return models.FXQuote{}, internalError()
+	}
+	rate, err := s.rates.Rate(ctx, from.Currency, to.Currency)
+	if err != nil {
+		if errors.Is(err, ErrFXRateNotFound) {
+			return models.FXQuote{}, &models.ErrorResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "rate " + from.Currency + "/" + to.Currency + " is not available",
+			}
+		}
+		return models.FXQuote{}, internalError()
+	}
+	converted, err := amount.Convert(toCurrency, rate)
+	if err != nil {
+		if errors.Is(err, models.ErrAmountOverflow) {
+			return models.FXQuote{}, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "amount overflow",
+			}
+		}
+		return models.FXQuote{}, internalError()
+	}
+	if converted.Amount <= 0 {
+		return models.FXQuote{}, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "amount is too small to convert",
+		}
+	}
+	quoteEntity, err := s.fxQuoteRepo.Create(ctx, entities.FXQuote{
+		FromWalletID:    from.ID,
+		ToWalletID:      to.ID,
+		FromCurrency:    from.Currency,
+		ToCurrency:      to.Currency,
+		Amount:          amount.Amount,
+		ConvertedAmount: converted.Amount,
+		Rate:            rate,
+		Rounding:        models.Rounding_mode_down,
+	}, s.quoteTTL)
+	if err != nil {
+		return models.FXQuote{}, internalError()
+	}
+	var quote models.FXQuote
+	if err = copier.Copy(&quote, &quoteEntity); err != nil {
+		return models.FXQuote{}, internalError()
+	}
+	return quote, nil
+}
+
+func (s *fxService) findWallet(ctx context.Context, id uuid.UUID) (entities.Wallet, *models.ErrorResponse) {
+	wallet, err := s.walletRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWalletNotFound) {
+			return entities.Wallet{}, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "wallet not found",
+			}
+		}
+		return entities.Wallet{}, internalError()
+	}
+	return wallet, nil
+}
+
+func internalError() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "internal server error",
+	}
+}
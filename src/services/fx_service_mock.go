@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"wallet-api/src/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type FXServiceMock struct {
+	mock.Mock
+}
+
+func (m *FXServiceMock) CreateQuote(ctx context.Context, req models.CreateFXQuoteRequest) (models.FXQuote, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.FXQuote), nil
+	}
+	return args.Get(0).(models.FXQuote), args.Get(1).(*models.ErrorResponse)
+}
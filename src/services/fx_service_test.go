@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFXService_CreateQuote(t *testing.T) {
+	mockWalletRepo := new(repositories.WalletRepoMock)
+	mockQuoteRepo := new(repositories.FXQuoteRepoMock)
+	rates := services.NewStaticRateProvider(map[string]string{"USD/RUB": "90.15"})
+	svc := services.NewFXService(mockWalletRepo, mockQuoteRepo, rates, time.Minute)
+	ctx := context.Background()
+
+	usdWallet := entities.Wallet{ID: uuid.New(), Currency: "USD"}
+	rubWallet := entities.Wallet{ID: uuid.New(), Currency: "RUB"}
+	eurWallet := entities.Wallet{ID: uuid.New(), Currency: "EUR"}
+	mockWalletRepo.On("FindByID", ctx, usdWallet.ID).Return(usdWallet, nil)
+	mockWalletRepo.On("FindByID", ctx, rubWallet.ID).Return(rubWallet, nil)
+	mockWalletRepo.On("FindByID", ctx, eurWallet.ID).Return(eurWallet, nil)
+
+	t.Run("success", func(t *testing.T) {
+		expected := entities.FXQuote{
+			FromWalletID:    rubWallet.ID,
+			ToWalletID:      usdWallet.ID,
+			FromCurrency:    "RUB",
+			ToCurrency:      "USD",
+			Amount:          100000,
+			ConvertedAmount: 1109,
+			Rate:            "0.0110926234",
+			Rounding:        models.Rounding_mode_down,
+		}
+		stored := expected
+		stored.ID = uuid.New()
+		mockQuoteRepo.On("Create", ctx, expected, time.Minute).Return(stored, nil).Once()
+
+		quote, errResp := svc.CreateQuote(ctx, models.CreateFXQuoteRequest{FromID: rubWallet.ID, ToID: usdWallet.ID, Amount: 100000})
+		assert.Nil(t, errResp)
+		assert.Equal(t, stored.ID, quote.ID)
+		assert.Equal(t, int64(1109), quote.ConvertedAmount)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("same currency", func(t *testing.T) {
+		_, errResp := svc.CreateQuote(ctx, models.CreateFXQuoteRequest{FromID: usdWallet.ID, ToID: usdWallet.ID, Amount: 100})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+
+	t.Run("rate not available", func(t *testing.T) {
+		_, errResp := svc.CreateQuote(ctx, models.CreateFXQuoteRequest{FromID: eurWallet.ID, ToID: usdWallet.ID, Amount: 100})
+		assert.Equal(t, http.StatusUnprocessableEntity, errResp.Code)
+	})
+
+	t.Run("amount too small", func(t *testing.T) {
+		_, errResp := svc.CreateQuote(ctx, models.CreateFXQuoteRequest{FromID: rubWallet.ID, ToID: usdWallet.ID, Amount: 50})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		missing := uuid.New()
+		mockWalletRepo.On("FindByID", ctx, missing).Return(entities.Wallet{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.CreateQuote(ctx, models.CreateFXQuoteRequest{FromID: missing, ToID: usdWallet.ID, Amount: 100})
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+		mockQuoteRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+}
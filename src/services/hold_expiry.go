@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"time"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/repositories"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultHoldExpiryPeriod = time.Minute
+	holdExpiryBatch         = 100
+)
+
+// HoldExpiryNotifier raises hold.expired events. Expiry itself needs no job,
+// an active hold past its expiry simply stops reserving the balance.
+type HoldExpiryNotifier struct {
+	logger   zerolog.Logger
+	holdRepo repositories.HoldRepo
+	period   time.Duration
+}
+
+func NewHoldExpiryNotifier(log zerolog.Logger, holdRepo repositories.HoldRepo, period time.Duration) *HoldExpiryNotifier {
+	if period <= 0 {
+		period = defaultHoldExpiryPeriod
+	}
+	return &HoldExpiryNotifier{logger: logger.WithModule(log, "hold_expiry"), holdRepo: holdRepo, period: period}
+}
+
+// Run checks for expired holds every period until ctx is done.
+func (n *HoldExpiryNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				notified, err := n.holdRepo.NotifyExpired(ctx, holdExpiryBatch)
+				if err != nil {
+					n.logger.Error().Err(err).Msg("notify expired holds")
+					break
+				}
+				if notified < holdExpiryBatch || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
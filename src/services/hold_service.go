@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+const (
+	defaultHoldTTL = 7 * 24 * time.Hour
+	defaultMaxHold = 30 * 24 * time.Hour
+)
+
+type HoldService interface {
+	CreateHold(ctx context.Context, walletID uuid.UUID, holdReq models.CreateHoldRequest) (models.Hold, *models.ErrorResponse)
+	GetHoldByID(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse)
+	CaptureHold(ctx context.Context, id uuid.UUID, captureReq models.CaptureHoldRequest) (models.Hold, *models.ErrorResponse)
+	VoidHold(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse)
+}
+
+type holdService struct {
+	holdRepo   repositories.HoldRepo
+	walletRepo repositories.WalletRepo
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewHoldService(holdRepo repositories.HoldRepo, walletRepo repositories.WalletRepo, defaultTTL, maxTTL time.Duration) HoldService {
+	if defaultTTL <= 0 {
+		defaultTTL = defaultHoldTTL
+	}
+	if maxTTL <= 0 {
+		maxTTL = defaultMaxHold
+	}
+	return &holdService{holdRepo: holdRepo, walletRepo: walletRepo, defaultTTL: defaultTTL, maxTTL: maxTTL}
+}
+
+func (s *holdService) CreateHold(ctx context.Context, walletID uuid.UUID, holdReq models.CreateHoldRequest) (models.Hold, *models.ErrorResponse) {
+	logger.AddFields(ctx, "wallet_id", walletID, "amount", holdReq.Amount)
+	ttl := s.defaultTTL
+	if holdReq.TTL != 0 {
+		ttl = time.Duration(holdReq.TTL) * time.Second
+	}
+	if ttl <= 0 || ttl > s.maxTTL {
+		return models.Hold{}, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "hold ttl must be positive and not more than " + s.maxTTL.String(),
+		}
+	}
+	if errResp := authorizeWallets(ctx, s.walletRepo, walletID); errResp != nil {
+		return models.Hold{}, errResp
+	}
+	holdEntity, err := s.holdRepo.Create(ctx, walletID, holdReq.Amount, ttl)
+	if err != nil {
+		return models.Hold{}, holdErrorResponse(err)
+	}
+	return toHoldModel(holdEntity)
+}
+
+func (s *holdService) GetHoldByID(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse) {
+	holdEntity, err := s.holdRepo.FindByID(ctx, id)
+	if err != nil {
+		return models.Hold{}, holdErrorResponse(err)
+	}
+	if errResp := authorizeWallets(ctx, s.walletRepo, holdEntity.WalletID); errResp != nil {
+		return models.Hold{}, errResp
+	}
+	return toHoldModel(holdEntity)
+}
+
+func (s *holdService) CaptureHold(ctx context.Context, id uuid.UUID, captureReq models.CaptureHoldRequest) (models.Hold, *models.ErrorResponse) {
+	logger.AddFields(ctx, "hold_id", id, "amount", captureReq.Amount)
+	if errResp := s.authorizeHold(ctx, id); errResp != nil {
+		return models.Hold{}, errResp
+	}
+	holdEntity, err := s.holdRepo.Capture(ctx, id, captureReq.Amount)
+	if err != nil {
+		return models.Hold{}, holdErrorResponse(err)
+	}
+	return toHoldModel(holdEntity)
+}
+
+func (s *holdService) VoidHold(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse) {
+	logger.AddFields(ctx, "hold_id", id)
+	if errResp := s.authorizeHold(ctx, id); errResp != nil {
+		return models.Hold{}, errResp
+	}
+	holdEntity, err := s.holdRepo.Void(ctx, id)
+	if err != nil {
+		return models.Hold{}, holdErrorResponse(err)
+	}
+	return toHoldModel(holdEntity)
+}
+
+// authorizeHold checks the owner of the wallet the hold is on, unknown holds
+// are let through for the operation to report them.
+func (s *holdService) authorizeHold(ctx context.Context, id uuid.UUID) *models.ErrorResponse {
+	if _, ok := restricted(ctx); !ok {
+		return nil
+	}
+	holdEntity, err := s.holdRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrHoldNotFound) {
+			return nil
+		}
+		return internalError()
+	}
+	return authorizeWallets(ctx, s.walletRepo, holdEntity.WalletID)
+}
+
+func toHoldModel(holdEntity entities.Hold) (models.Hold, *models.ErrorResponse) {
+	var hold models.Hold
+	if err := copier.Copy(&hold, &holdEntity); err != nil {
+		return models.Hold{}, internalError()
+	}
+	return hold, nil
+}
+
+func holdErrorResponse(err error) *models.ErrorResponse {
+	switch {
+	case errors.Is(err, repositories.ErrHoldNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "hold not found",
+		}
+	case errors.Is(err, repositories.ErrHoldExpired):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "hold expired",
+		}
+	case errors.Is(err, repositories.ErrHoldNotActive):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "hold is already captured or voided",
+		}
+	case errors.Is(err, repositories.ErrHoldCaptureExceedsHold):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "capture amount exceeds hold amount",
+		}
+	case errors.Is(err, repositories.ErrWalletNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "wallet not found",
+		}
+	default:
+		return balanceErrorResponse(err)
+	}
+}
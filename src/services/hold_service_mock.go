@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldServiceMock struct {
+	mock.Mock
+}
+
+func (m *HoldServiceMock) CreateHold(ctx context.Context, walletID uuid.UUID, req models.CreateHoldRequest) (models.Hold, *models.ErrorResponse) {
+	args := m.Called(ctx, walletID, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Hold), nil
+	}
+	return args.Get(0).(models.Hold), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *HoldServiceMock) GetHoldByID(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Hold), nil
+	}
+	return args.Get(0).(models.Hold), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *HoldServiceMock) CaptureHold(ctx context.Context, id uuid.UUID, req models.CaptureHoldRequest) (models.Hold, *models.ErrorResponse) {
+	args := m.Called(ctx, id, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Hold), nil
+	}
+	return args.Get(0).(models.Hold), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *HoldServiceMock) VoidHold(ctx context.Context, id uuid.UUID) (models.Hold, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Hold), nil
+	}
+	return args.Get(0).(models.Hold), args.Get(1).(*models.ErrorResponse)
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldService_CreateHold(t *testing.T) {
+	mockRepo := new(repositories.HoldRepoMock)
+	svc := services.NewHoldService(mockRepo, new(repositories.WalletRepoMock), time.Hour, 24*time.Hour)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	t.Run("default ttl", func(t *testing.T) {
+		entity := entities.Hold{ID: uuid.New(), WalletID: walletID, Amount: 500, Status: entities.Hold_status_active}
+		mockRepo.On("Create", ctx, walletID, int64(500), time.Hour).Return(entity, nil).Once()
+
+		hold, errResp := svc.CreateHold(ctx, walletID, models.CreateHoldRequest{Amount: 500})
+		assert.Nil(t, errResp)
+		assert.Equal(t, entity.ID, hold.ID)
+		assert.Equal(t, models.Hold_status_active, hold.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("request ttl", func(t *testing.T) {
+		mockRepo.On("Create", ctx, walletID, int64(500), 90*time.Second).Return(entities.Hold{}, nil).Once()
+
+		_, errResp := svc.CreateHold(ctx, walletID, models.CreateHoldRequest{Amount: 500, TTL: 90})
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ttl above max", func(t *testing.T) {
+		_, errResp := svc.CreateHold(ctx, walletID, models.CreateHoldRequest{Amount: 500, TTL: 2 * 24 * 3600})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+
+	t.Run("not enough available balance", func(t *testing.T) {
+		mockRepo.On("Create", ctx, walletID, int64(500), time.Hour).Return(entities.Hold{}, repositories.ErrWalletNotEnoughBalance).Once()
+
+		_, errResp := svc.CreateHold(ctx, walletID, models.CreateHoldRequest{Amount: 500})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mockRepo.On("Create", ctx, walletID, int64(500), time.Hour).Return(entities.Hold{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.CreateHold(ctx, walletID, models.CreateHoldRequest{Amount: 500})
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+}
+
+func TestHoldService_CaptureHold(t *testing.T) {
+	mockRepo := new(repositories.HoldRepoMock)
+	svc := services.NewHoldService(mockRepo, new(repositories.WalletRepoMock), 0, 0)
+	ctx := context.Background()
+	holdID := uuid.New()
+	partial := int64(200)
+
+	t.Run("partial capture", func(t *testing.T) {
+		entity := entities.Hold{ID: holdID, Amount: 500, CapturedAmount: &partial, Status: entities.Hold_status_captured}
+		mockRepo.On("Capture", ctx, holdID, &partial).Return(entity, nil).Once()
+
+		hold, errResp := svc.CaptureHold(ctx, holdID, models.CaptureHoldRequest{Amount: &partial})
+		assert.Nil(t, errResp)
+		assert.Equal(t, partial, *hold.CapturedAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	errCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", repositories.ErrHoldNotFound, http.StatusNotFound},
+		{"expired", repositories.ErrHoldExpired, http.StatusConflict},
+		{"already captured", repositories.ErrHoldNotActive, http.StatusConflict},
+		{"exceeds hold", repositories.ErrHoldCaptureExceedsHold, http.StatusBadRequest},
+		{"wallet frozen", repositories.ErrWalletFrozen, http.StatusConflict},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.On("Capture", ctx, holdID, (*int64)(nil)).Return(entities.Hold{}, tc.err).Once()
+
+			_, errResp := svc.CaptureHold(ctx, holdID, models.CaptureHoldRequest{})
+			assert.Equal(t, tc.code, errResp.Code)
+		})
+	}
+}
+
+func TestHoldService_VoidHold(t *testing.T) {
+	mockRepo := new(repositories.HoldRepoMock)
+	svc := services.NewHoldService(mockRepo, new(repositories.WalletRepoMock), 0, 0)
+	ctx := context.Background()
+	holdID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("Void", ctx, holdID).Return(entities.Hold{ID: holdID, Status: entities.Hold_status_voided}, nil).Once()
+
+		hold, errResp := svc.VoidHold(ctx, holdID)
+		assert.Nil(t, errResp)
+		assert.Equal(t, models.Hold_status_voided, hold.Status)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockRepo.On("Void", ctx, holdID).Return(entities.Hold{}, repositories.ErrHoldExpired).Once()
+
+		_, errResp := svc.VoidHold(ctx, holdID)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+	})
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+type LedgerService interface {
+	GetTransactionByID(ctx context.Context, id uuid.UUID) (models.Transaction, *models.ErrorResponse)
+	GetWalletTransactions(ctx context.Context, req models.GetTransactionsRequest) (models.GetTransactionsResponse, *models.ErrorResponse)
+	ReverseTransaction(ctx context.Context, id uuid.UUID, reverseReq models.ReverseTransactionRequest) (models.ReverseTransactionResponse, *models.ErrorResponse)
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepo
+	walletRepo repositories.WalletRepo
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepo, walletRepo repositories.WalletRepo) LedgerService {
+	return &ledgerService{ledgerRepo: ledgerRepo, walletRepo: walletRepo}
+}
+
+func (s *ledgerService) GetTransactionByID(ctx context.Context, id uuid.UUID) (models.Transaction, *models.ErrorResponse) {
+	var transaction models.Transaction
+	transactionEntity, err := s.ledgerRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTransactionNotFound) {
+			return models.Transaction{}, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "transaction not found",
+			}
+		}
+		return models.Transaction{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	if errResp := authorizeWallets(ctx, s.walletRepo, transactionEntity.WalletID); errResp != nil {
+		return models.Transaction{}, errResp
+	}
+	if err = copier.Copy(&transaction, &transactionEntity); err != nil {
+		return models.Transaction{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return transaction, nil
+}
+
+// transactionsSort is the only column the transactions are listed by.
+const transactionsSort = "created"
+
+func (s *ledgerService) GetWalletTransactions(ctx context.Context, req models.GetTransactionsRequest) (models.GetTransactionsResponse, *models.ErrorResponse) {
+	walletEntity, err := s.walletRepo.FindByID(ctx, req.WalletID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWalletNotFound) {
+			return models.GetTransactionsResponse{}, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "wallet not found",
+			}
+		}
+		return models.GetTransactionsResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	if !canAccess(ctx, walletEntity.Owner) {
+		return models.GetTransactionsResponse{}, accessDenied()
+	}
+	limit := pageLimit(req.Limit)
+	filter := repositories.TransactionsFilter{
+		WalletID:   req.WalletID,
+		From:       req.From,
+		To:         req.To,
+		Types:      req.Types,
+		Descending: req.Order == models.Sort_order_desc,
+		Limit:      limit + 1,
+	}
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor, transactionsSort, req.Order, validCursorTime)
+		if err != nil {
+			return models.GetTransactionsResponse{}, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "incorrect cursor",
+			}
+		}
+		filter.AfterValue, filter.AfterID = &c.Value, &c.ID
+	}
+	transactionEntities, err := s.ledgerRepo.FindByWalletID(ctx, filter)
+	if err != nil {
+		return models.GetTransactionsResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	response := models.GetTransactionsResponse{Transactions: []models.Transaction{}}
+	if len(transactionEntities) > limit {
+		transactionEntities = transactionEntities[:limit]
+		last := transactionEntities[limit-1]
+		response.NextCursor = encodeCursor(cursor{
+			Sort:  transactionsSort,
+			Order: sortOrder(req.Order),
+			Value: last.Created.Format(cursorTimeLayout),
+			ID:    last.ID,
+		})
+	}
+	if err = copier.Copy(&response.Transactions, &transactionEntities); err != nil {
+		return models.GetTransactionsResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return response, nil
+}
+
+func (s *ledgerService) ReverseTransaction(ctx context.Context, id uuid.UUID, reverseReq models.ReverseTransactionRequest) (models.ReverseTransactionResponse, *models.ErrorResponse) {
+	logger.AddFields(ctx, "transaction_id", id)
+	if errResp := requireAdmin(ctx); errResp != nil {
+		return models.ReverseTransactionResponse{}, errResp
+	}
+	transactionEntities, err := s.ledgerRepo.Reverse(ctx, id, reverseReq.Amount)
+	if err != nil {
+		return models.ReverseTransactionResponse{}, reversalErrorResponse(err)
+	}
+	response := models.ReverseTransactionResponse{Transactions: []models.Transaction{}}
+	if err = copier.Copy(&response.Transactions, &transactionEntities); err != nil {
+		return models.ReverseTransactionResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return response, nil
+}
+
+func reversalErrorResponse(err error) *models.ErrorResponse {
+	switch {
+	case errors.Is(err, repositories.ErrTransactionNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "transaction not found",
+		}
+	case errors.Is(err, repositories.ErrTransactionAlreadyReversed):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "transaction is already reversed",
+		}
+	case errors.Is(err, repositories.ErrTransactionNotReversible):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "transaction can not be reversed",
+		}
+	case errors.Is(err, repositories.ErrReversalExceedsAmount):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "reversal amount exceeds transaction amount",
+		}
+	case errors.Is(err, repositories.ErrPartialFXReversal):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "fx transfer can be reversed only in full",
+		}
+	default:
+		return balanceErrorResponse(err)
+	}
+}
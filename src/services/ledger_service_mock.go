@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerServiceMock struct {
+	mock.Mock
+}
+
+func (m *LedgerServiceMock) GetTransactionByID(ctx context.Context, id uuid.UUID) (models.Transaction, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Transaction), nil
+	}
+	return args.Get(0).(models.Transaction), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *LedgerServiceMock) GetWalletTransactions(ctx context.Context, req models.GetTransactionsRequest) (models.GetTransactionsResponse, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.GetTransactionsResponse), nil
+	}
+	return args.Get(0).(models.GetTransactionsResponse), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *LedgerServiceMock) ReverseTransaction(ctx context.Context, id uuid.UUID, req models.ReverseTransactionRequest) (models.ReverseTransactionResponse, *models.ErrorResponse) {
+	args := m.Called(ctx, id, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.ReverseTransactionResponse), nil
+	}
+	return args.Get(0).(models.ReverseTransactionResponse), args.Get(1).(*models.ErrorResponse)
+}
@@ -0,0 +1,168 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedgerService_GetTransactionByID(t *testing.T) {
+	mockRepo := new(repositories.LedgerRepoMock)
+	svc := services.NewLedgerService(mockRepo, new(repositories.WalletRepoMock))
+
+	ctx := context.Background()
+	transactionID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		entity := entities.Transaction{
+			ID:           transactionID,
+			WalletID:     uuid.New(),
+			Type:         entities.Transaction_type_deposit,
+			Amount:       500,
+			BalanceAfter: 1500,
+			Created:      time.Now(),
+		}
+		mockRepo.On("FindByID", ctx, transactionID).Return(entity, nil).Once()
+
+		transaction, errResp := svc.GetTransactionByID(ctx, transactionID)
+		assert.Nil(t, errResp)
+		assert.Equal(t, entity.WalletID, transaction.WalletID)
+		assert.Equal(t, entity.Amount, transaction.Amount)
+		assert.Equal(t, entity.BalanceAfter, transaction.BalanceAfter)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, transactionID).Return(entities.Transaction{}, repositories.ErrTransactionNotFound).Once()
+
+		_, errResp := svc.GetTransactionByID(ctx, transactionID)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, transactionID).Return(entities.Transaction{}, errors.New("db error")).Once()
+
+		_, errResp := svc.GetTransactionByID(ctx, transactionID)
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestLedgerService_GetWalletTransactions(t *testing.T) {
+	mockRepo := new(repositories.LedgerRepoMock)
+	mockWalletRepo := new(repositories.WalletRepoMock)
+	svc := services.NewLedgerService(mockRepo, mockWalletRepo)
+
+	ctx := context.Background()
+	walletID := uuid.New()
+	created := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success with next cursor", func(t *testing.T) {
+		entityList := []entities.Transaction{
+			{ID: uuid.New(), WalletID: walletID, Type: entities.Transaction_type_deposit, Amount: 1000, BalanceAfter: 1000, Created: created},
+			{ID: uuid.New(), WalletID: walletID, Type: entities.Transaction_type_withdraw, Amount: 300, BalanceAfter: 700, Created: created.Add(time.Minute)},
+		}
+		filter := repositories.TransactionsFilter{
+			WalletID: walletID,
+			Types:    []string{models.Transaction_type_withdraw, models.Transaction_type_deposit},
+			Limit:    2,
+		}
+		mockWalletRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID}, nil).Once()
+		mockRepo.On("FindByWalletID", ctx, filter).Return(entityList, nil).Once()
+
+		resp, errResp := svc.GetWalletTransactions(ctx, models.GetTransactionsRequest{
+			WalletID: walletID,
+			Limit:    1,
+			Types:    filter.Types,
+		})
+		assert.Nil(t, errResp)
+		assert.Len(t, resp.Transactions, 1)
+		assert.Equal(t, int64(1000), resp.Transactions[0].BalanceAfter)
+		assert.NotEmpty(t, resp.NextCursor)
+
+		mockRepo.AssertExpectations(t)
+		mockWalletRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		mockWalletRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID}, nil).Once()
+		mockRepo.On("FindByWalletID", ctx, mock.Anything).Return([]entities.Transaction(nil), nil).Once()
+
+		resp, errResp := svc.GetWalletTransactions(ctx, models.GetTransactionsRequest{WalletID: walletID})
+		assert.Nil(t, errResp)
+		assert.NotNil(t, resp.Transactions)
+		assert.Empty(t, resp.Transactions)
+		assert.Empty(t, resp.NextCursor)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mockWalletRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.GetWalletTransactions(ctx, models.GetTransactionsRequest{WalletID: walletID})
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+
+		mockWalletRepo.AssertExpectations(t)
+	})
+
+	t.Run("incorrect cursor", func(t *testing.T) {
+		mockWalletRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID}, nil).Once()
+
+		_, errResp := svc.GetWalletTransactions(ctx, models.GetTransactionsRequest{WalletID: walletID, Cursor: "%%%"})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+}
+
+func TestLedgerService_ReverseTransaction(t *testing.T) {
+	mockLedgerRepo := new(repositories.LedgerRepoMock)
+	svc := services.NewLedgerService(mockLedgerRepo, new(repositories.WalletRepoMock))
+	ctx := context.Background()
+	id := uuid.New()
+	partial := int64(300)
+
+	t.Run("partial reversal", func(t *testing.T) {
+		entry := entities.Transaction{ID: uuid.New(), Type: entities.Transaction_type_reversal, Amount: partial, ReversalOf: &id}
+		mockLedgerRepo.On("Reverse", ctx, id, &partial).Return([]entities.Transaction{entry}, nil).Once()
+
+		resp, errResp := svc.ReverseTransaction(ctx, id, models.ReverseTransactionRequest{Amount: &partial})
+		assert.Nil(t, errResp)
+		assert.Len(t, resp.Transactions, 1)
+		assert.Equal(t, &id, resp.Transactions[0].ReversalOf)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	errCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", repositories.ErrTransactionNotFound, http.StatusNotFound},
+		{"already reversed", repositories.ErrTransactionAlreadyReversed, http.StatusConflict},
+		{"reversal of reversal", repositories.ErrTransactionNotReversible, http.StatusConflict},
+		{"exceeds amount", repositories.ErrReversalExceedsAmount, http.StatusBadRequest},
+		{"would overdraw", repositories.ErrWalletNotEnoughBalance, http.StatusBadRequest},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLedgerRepo.On("Reverse", ctx, id, (*int64)(nil)).Return([]entities.Transaction(nil), tc.err).Once()
+
+			_, errResp := svc.ReverseTransaction(ctx, id, models.ReverseTransactionRequest{})
+			assert.Equal(t, tc.code, errResp.Code)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	walletEventBuffer     = 64
+	walletEventReplayPage = 500
+)
+
+type WalletEventService interface {
+	// Subscribe returns the events of the wallet as they are dispatched and
+	// the function to stop receiving them. The channel is closed when the
+	// subscriber falls behind, it has to resume with GetEventsAfter.
+	Subscribe(walletID uuid.UUID) (<-chan models.WalletEvent, func())
+	// Watch subscribes to the events of the wallet for a stream that may
+	// resume after a known event, see WalletWatch.
+	Watch(walletID uuid.UUID) *WalletWatch
+	GetEventsAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.WalletEvent, *models.ErrorResponse)
+	// Dispatch passes a wallet_events notification to the subscribers.
+	Dispatch(payload string) error
+}
+
+type walletEventService struct {
+	walletEventRepo repositories.WalletEventRepo
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan models.WalletEvent]struct{}
+}
+
+func NewWalletEventService(walletEventRepo repositories.WalletEventRepo) WalletEventService {
+	return &walletEventService{
+		walletEventRepo: walletEventRepo,
+		subscribers:     map[uuid.UUID]map[chan models.WalletEvent]struct{}{},
+	}
+}
+
+func (s *walletEventService) Subscribe(walletID uuid.UUID) (<-chan models.WalletEvent, func()) {
+	events := make(chan models.WalletEvent, walletEventBuffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[walletID] == nil {
+		s.subscribers[walletID] = map[chan models.WalletEvent]struct{}{}
+	}
+	s.subscribers[walletID][events] = struct{}{}
+	return events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.remove(walletID, events)
+	}
+}
+
+func (s *walletEventService) Watch(walletID uuid.UUID) *WalletWatch {
+	return NewWalletWatch(s, walletID)
+}
+
+// GetEventsAfter returns the events with an outbox id above afterID. The ids
+// are given in insert order, not commit order, so an event of a transaction
+// that committed after the one of afterID with a smaller id is not returned.
+func (s *walletEventService) GetEventsAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.WalletEvent, *models.ErrorResponse) {
+	eventEntities, err := s.walletEventRepo.FindAfter(ctx, walletID, afterID, limit)
+	if err != nil {
+		return nil, internalError()
+	}
+	events := make([]models.WalletEvent, 0, len(eventEntities))
+	for _, entity := range eventEntities {
+		events = append(events, models.WalletEvent{
+			ID:       entity.ID,
+			Type:     entity.Type,
+			WalletID: entity.WalletID,
+			Created:  entity.Created,
+			Payload:  entity.Payload,
+		})
+	}
+	return events, nil
+}
+
+func (s *walletEventService) Dispatch(payload string) error {
+	var event models.WalletEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for events := range s.subscribers[event.WalletID] {
+		select {
+		case events <- event:
+		default:
+			// a slow client must not hold up the others, it catches up from
+			// the database after reconnecting
+			s.remove(event.WalletID, events)
+		}
+	}
+	return nil
+}
+
+// remove closes the channel once, s.mu must be held.
+func (s *walletEventService) remove(walletID uuid.UUID, events chan models.WalletEvent) {
+	subscribers := s.subscribers[walletID]
+	if _, ok := subscribers[events]; !ok {
+		return
+	}
+	delete(subscribers, events)
+	close(events)
+	if len(subscribers) == 0 {
+		delete(s.subscribers, walletID)
+	}
+}
+
+// WalletWatch is what the REST and the gRPC event streams share. It
+// subscribes before the missed events are read, so nothing committed in
+// between is lost, and drops the subscribed events that were replayed.
+type WalletWatch struct {
+	service  WalletEventService
+	walletID uuid.UUID
+	events   <-chan models.WalletEvent
+	stop     func()
+	replayed map[int64]struct{}
+}
+
+// NewWalletWatch subscribes to the events of the wallet, Stop releases the
+// subscription.
+func NewWalletWatch(service WalletEventService, walletID uuid.UUID) *WalletWatch {
+	events, stop := service.Subscribe(walletID)
+	return &WalletWatch{service: service, walletID: walletID, events: events, stop: stop, replayed: map[int64]struct{}{}}
+}
+
+// Replay sends the events after afterID, it has to be called before Events
+// is read. It stops at the first error of send and returns it, errResp is
+// the failure to read the events.
+func (w *WalletWatch) Replay(ctx context.Context, afterID int64, send func(models.WalletEvent) error) (errResp *models.ErrorResponse, err error) {
+	for {
+		events, errResp := w.service.GetEventsAfter(ctx, w.walletID, afterID, walletEventReplayPage)
+		if errResp != nil {
+			return errResp, nil
+		}
+		for _, event := range events {
+			if err = send(event); err != nil {
+				return nil, err
+			}
+			w.replayed[event.ID] = struct{}{}
+			afterID = event.ID
+		}
+		if len(events) < walletEventReplayPage {
+			return nil, nil
+		}
+	}
+}
+
+// Events passes the events as they are dispatched. It is closed when the
+// watch falls behind, the stream has to resume from the last event it sent.
+func (w *WalletWatch) Events() <-chan models.WalletEvent {
+	return w.events
+}
+
+// Replayed reports whether the event from Events was already sent by
+// Replay and must be skipped.
+func (w *WalletWatch) Replayed(event models.WalletEvent) bool {
+	if _, ok := w.replayed[event.ID]; !ok {
+		return false
+	}
+	delete(w.replayed, event.ID)
+	return true
+}
+
+func (w *WalletWatch) Stop() {
+	w.stop()
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletEventServiceMock struct {
+	mock.Mock
+}
+
+func (m *WalletEventServiceMock) Subscribe(walletID uuid.UUID) (<-chan models.WalletEvent, func()) {
+	args := m.Called(walletID)
+	return args.Get(0).(<-chan models.WalletEvent), args.Get(1).(func())
+}
+
+// Watch is the real one on top of the mocked Subscribe and GetEventsAfter.
+func (m *WalletEventServiceMock) Watch(walletID uuid.UUID) *WalletWatch {
+	return NewWalletWatch(m, walletID)
+}
+
+func (m *WalletEventServiceMock) GetEventsAfter(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.WalletEvent, *models.ErrorResponse) {
+	args := m.Called(ctx, walletID, afterID, limit)
+	if args.Get(1) == nil {
+		return args.Get(0).([]models.WalletEvent), nil
+	}
+	return args.Get(0).([]models.WalletEvent), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletEventServiceMock) Dispatch(payload string) error {
+	args := m.Called(payload)
+	return args.Error(0)
+}
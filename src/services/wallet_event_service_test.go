@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func walletEventPayload(id int64, walletID uuid.UUID) string {
+	return fmt.Sprintf(`{"id":%d,"type":"balance.changed","walletId":"%s","created":"2025-07-01T12:00:00+00:00","payload":{"amount":100}}`, id, walletID)
+}
+
+func TestWalletEventService_Dispatch(t *testing.T) {
+	svc := services.NewWalletEventService(new(repositories.WalletEventRepoMock))
+	walletID, otherID := uuid.New(), uuid.New()
+	events, unsubscribe := svc.Subscribe(walletID)
+	others, unsubscribeOthers := svc.Subscribe(otherID)
+	defer unsubscribeOthers()
+
+	assert.NoError(t, svc.Dispatch(walletEventPayload(1, walletID)))
+
+	event := <-events
+	assert.Equal(t, int64(1), event.ID)
+	assert.Equal(t, "balance.changed", event.Type)
+	assert.JSONEq(t, `{"amount":100}`, string(event.Payload))
+	assert.Empty(t, others)
+
+	assert.Error(t, svc.Dispatch("not json"))
+
+	unsubscribe()
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.NoError(t, svc.Dispatch(walletEventPayload(2, walletID)))
+}
+
+func TestWalletEventService_SlowSubscriberIsClosed(t *testing.T) {
+	svc := services.NewWalletEventService(new(repositories.WalletEventRepoMock))
+	walletID := uuid.New()
+	events, unsubscribe := svc.Subscribe(walletID)
+	defer unsubscribe()
+
+	var id int64
+	for {
+		id++
+		assert.NoError(t, svc.Dispatch(walletEventPayload(id, walletID)))
+		if len(events) < cap(events) {
+			continue
+		}
+		assert.NoError(t, svc.Dispatch(walletEventPayload(id+1, walletID)))
+		break
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, int(id), received)
+}
+
+func TestWalletEventService_GetEventsAfter(t *testing.T) {
+	mockRepo := new(repositories.WalletEventRepoMock)
+	svc := services.NewWalletEventService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		entity := entities.WalletEvent{ID: 8, Type: entities.Event_type_hold_expired, WalletID: walletID, Payload: []byte(`{}`)}
+		mockRepo.On("FindAfter", ctx, walletID, int64(7), 100).Return([]entities.WalletEvent{entity}, nil).Once()
+
+		events, errResp := svc.GetEventsAfter(ctx, walletID, 7, 100)
+		assert.Nil(t, errResp)
+		assert.Len(t, events, 1)
+		assert.Equal(t, int64(8), events[0].ID)
+		assert.Equal(t, entities.Event_type_hold_expired, events[0].Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo.On("FindAfter", ctx, walletID, int64(7), 100).Return([]entities.WalletEvent(nil), assert.AnError).Once()
+
+		_, errResp := svc.GetEventsAfter(ctx, walletID, 7, 100)
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+	})
+}
+
+func TestWalletWatch(t *testing.T) {
+	mockRepo := new(repositories.WalletEventRepoMock)
+	svc := services.NewWalletEventService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	watch := svc.Watch(walletID)
+	// committed while the missed events are read, it comes from both
+	assert.NoError(t, svc.Dispatch(walletEventPayload(7, walletID)))
+	mockRepo.On("FindAfter", ctx, walletID, int64(5), mock.Anything).Return([]entities.WalletEvent{
+		{ID: 6, WalletID: walletID}, {ID: 7, WalletID: walletID},
+	}, nil).Once()
+
+	var sent []int64
+	errResp, err := watch.Replay(ctx, 5, func(event models.WalletEvent) error {
+		sent = append(sent, event.ID)
+		return nil
+	})
+	assert.Nil(t, errResp)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6, 7}, sent)
+
+	assert.NoError(t, svc.Dispatch(walletEventPayload(8, walletID)))
+	assert.True(t, watch.Replayed(<-watch.Events()))
+	assert.False(t, watch.Replayed(<-watch.Events()))
+
+	watch.Stop()
+	_, ok := <-watch.Events()
+	assert.False(t, ok)
+
+	t.Run("repository error", func(t *testing.T) {
+		watch := svc.Watch(walletID)
+		defer watch.Stop()
+		mockRepo.On("FindAfter", ctx, walletID, int64(5), mock.Anything).Return([]entities.WalletEvent(nil), assert.AnError).Once()
+
+		errResp, err := watch.Replay(ctx, 5, func(models.WalletEvent) error { return nil })
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+		assert.NoError(t, err)
+	})
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"wallet-api/pkg/auth"
+	"wallet-api/pkg/logger"
+	"wallet-api/src/database/entities"
 	"wallet-api/src/database/repositories"
 	"wallet-api/src/models"
 
@@ -13,8 +17,15 @@ import (
 
 type WalletService interface {
 	GetWalletByID(ctx context.Context, id uuid.UUID) (models.GetBalanceResponse, *models.ErrorResponse)
+	CreateWallet(ctx context.Context, createReq models.CreateWalletRequest) (models.Wallet, *models.ErrorResponse)
+	ChangeWalletStatus(ctx context.Context, id uuid.UUID, status string) (models.Wallet, *models.ErrorResponse)
+	ChangeWalletLimits(ctx context.Context, id uuid.UUID, actor string, limitsReq models.ChangeWalletLimitsRequest) (models.Wallet, *models.ErrorResponse)
 	ChangeWalletBalance(ctx context.Context, changeBalanceReq models.ChangeBalanceRequest) *models.ErrorResponse
-	GetWallets(ctx context.Context) ([]models.GetWalletsResponse, *models.ErrorResponse)
+	ChangeWalletBalanceBatch(ctx context.Context, batchReq models.BatchChangeBalanceRequest) (models.BatchChangeBalanceResponse, *models.ErrorResponse)
+	GetWallets(ctx context.Context, req models.GetWalletsRequest) (models.GetWalletsResponse, *models.ErrorResponse)
+	Transfer(ctx context.Context, transferReq models.TransferRequest) *models.ErrorResponse
+	GetSpendingLimits(ctx context.Context, id uuid.UUID) (models.SpendingLimits, *models.ErrorResponse)
+	SetSpendingLimits(ctx context.Context, id uuid.UUID, limitsReq models.SpendingLimits) (models.SpendingLimits, *models.ErrorResponse)
 }
 
 type walletService struct {
@@ -40,17 +51,148 @@ func (s *walletService) GetWalletByID(ctx context.Context, id uuid.UUID) (models
 			Message: "internal server error",
 		}
 	}
+	if !canAccess(ctx, walletEntity.Owner) {
+		return models.GetBalanceResponse{}, accessDenied()
+	}
 	if err = copier.Copy(&wallet, &walletEntity); err != nil {
 		return models.GetBalanceResponse{}, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "internal server error",
 		}
 	}
+	wallet.Available = walletEntity.Available()
+	return wallet, nil
+}
+
+func (s *walletService) CreateWallet(ctx context.Context, createReq models.CreateWalletRequest) (models.Wallet, *models.ErrorResponse) {
+	var wallet models.Wallet
+	if createReq.Currency == "" {
+		createReq.Currency = models.Default_currency
+	}
+	if _, ok := models.LookupCurrency(createReq.Currency); !ok {
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "unknown currency",
+		}
+	}
+	var owner *string
+	if principal, ok := auth.FromContext(ctx); ok {
+		owner = &principal.ID
+	}
+	walletEntity, err := s.walletRepo.Create(ctx, createReq.Currency, owner)
+	if err != nil {
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	if err = copier.Copy(&wallet, &walletEntity); err != nil {
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return wallet, nil
+}
+
+func (s *walletService) ChangeWalletStatus(ctx context.Context, id uuid.UUID, status string) (models.Wallet, *models.ErrorResponse) {
+	logger.AddFields(ctx, "wallet_id", id, "status", status)
+	// an owner may freeze its wallet, lifting a freeze or closing is for admins
+	if status != models.Wallet_status_frozen {
+		if errResp := requireAdmin(ctx); errResp != nil {
+			return models.Wallet{}, errResp
+		}
+	}
+	if errResp := authorizeWallets(ctx, s.walletRepo, id); errResp != nil {
+		return models.Wallet{}, errResp
+	}
+	var wallet models.Wallet
+	walletEntity, err := s.walletRepo.ChangeStatus(ctx, id, status)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWalletNotFound) {
+			return models.Wallet{}, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "wallet not found",
+			}
+		}
+		if errors.Is(err, repositories.ErrWalletStatusTransition) {
+			return models.Wallet{}, &models.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "wallet status can not be changed to " + status,
+			}
+		}
+		if errors.Is(err, repositories.ErrWalletBalanceNotZero) {
+			return models.Wallet{}, &models.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "wallet balance must be zero to close it",
+			}
+		}
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	if err = copier.Copy(&wallet, &walletEntity); err != nil {
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return wallet, nil
+}
+
+func (s *walletService) ChangeWalletLimits(ctx context.Context, id uuid.UUID, actor string, limitsReq models.ChangeWalletLimitsRequest) (models.Wallet, *models.ErrorResponse) {
+	logger.AddFields(ctx, "wallet_id", id)
+	if errResp := requireAdmin(ctx); errResp != nil {
+		return models.Wallet{}, errResp
+	}
+	var wallet models.Wallet
+	walletEntity, err := s.walletRepo.ChangeLimits(ctx, id,
+		entities.WalletLimits{CreditLimit: limitsReq.CreditLimit, MinBalance: limitsReq.MinBalance},
+		entities.WalletAudit{Actor: actor, Reason: limitsReq.Reason},
+	)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWalletNotFound) {
+			return models.Wallet{}, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "wallet not found",
+			}
+		}
+		return models.Wallet{}, balanceErrorResponse(err)
+	}
+	if err = copier.Copy(&wallet, &walletEntity); err != nil {
+		return models.Wallet{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
 	return wallet, nil
 }
 
 func (s *walletService) ChangeWalletBalance(ctx context.Context, changeBalanceReq models.ChangeBalanceRequest) *models.ErrorResponse {
-	var err error
+	logger.AddFields(ctx, "wallet_id", changeBalanceReq.ID, "operation", changeBalanceReq.OperationType, "amount", changeBalanceReq.Balance)
+	walletEntity, err := s.walletRepo.FindByID(ctx, changeBalanceReq.ID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWalletNotFound) {
+			return &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "wallet not found",
+			}
+		}
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	if !canAccess(ctx, walletEntity.Owner) {
+		return accessDenied()
+	}
+	if changeBalanceReq.Currency == "" {
+		changeBalanceReq.Currency = walletEntity.Currency
+	}
+	if errResp := checkBalanceChange(walletEntity, changeBalanceReq); errResp != nil {
+		return errResp
+	}
 	switch changeBalanceReq.OperationType {
 	case models.Operation_type_deposit:
 		err = s.walletRepo.DepositUpdate(ctx, changeBalanceReq.ID, changeBalanceReq.Balance)
@@ -58,40 +200,349 @@ func (s *walletService) ChangeWalletBalance(ctx context.Context, changeBalanceRe
 		err = s.walletRepo.WithdrawUpdate(ctx, changeBalanceReq.ID, changeBalanceReq.Balance)
 	}
 	if err != nil {
-		if errors.Is(err, repositories.ErrWalletNotEnoughBalance) {
-			return &models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "not enough balance",
-			}
+		return balanceErrorResponse(err)
+	}
+	return nil
+}
+
+func (s *walletService) ChangeWalletBalanceBatch(ctx context.Context, batchReq models.BatchChangeBalanceRequest) (models.BatchChangeBalanceResponse, *models.ErrorResponse) {
+	ids := make([]uuid.UUID, 0, len(batchReq.Items))
+	for _, item := range batchReq.Items {
+		ids = append(ids, item.ID)
+	}
+	if errResp := authorizeWallets(ctx, s.walletRepo, ids...); errResp != nil {
+		return models.BatchChangeBalanceResponse{}, errResp
+	}
+	changes := make([]repositories.BalanceChange, 0, len(batchReq.Items))
+	for _, item := range batchReq.Items {
+		txType := entities.Transaction_type_deposit
+		if item.OperationType == models.Operation_type_withdraw {
+			txType = entities.Transaction_type_withdraw
 		}
-		if errors.Is(err, repositories.ErrNoRowsForUpdate) {
-			return &models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: "wallet not found",
-			}
+		changes = append(changes, repositories.BalanceChange{
+			WalletID: item.ID,
+			Type:     txType,
+			Amount:   item.Balance,
+			Currency: item.Currency,
+		})
+	}
+	atomic := batchReq.Mode == models.Batch_mode_atomic
+	results, err := s.walletRepo.ApplyBatch(ctx, changes, atomic)
+	if err != nil {
+		return models.BatchChangeBalanceResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+
+	response := models.BatchChangeBalanceResponse{Results: make([]models.BatchItemResult, len(changes))}
+	rolledBack := atomic && len(results) > 0 && results[len(results)-1].Err != nil
+	for i := range response.Results {
+		item := &response.Results[i]
+		item.Index = i
+		switch {
+		case i >= len(results):
+			item.Status = models.Batch_item_skipped
+		case results[i].Err != nil:
+			item.Status = models.Batch_item_failed
+			item.Error = batchItemErrorResponse(results[i].Err)
+			response.Failed++
+		case rolledBack:
+			item.Status = models.Batch_item_rolled_back
+		default:
+			item.Status = models.Batch_item_applied
+			item.TransactionID = &results[i].Transaction.ID
+			item.BalanceAfter = &results[i].Transaction.BalanceAfter
+			response.Applied++
+		}
+	}
+	return response, nil
+}
+
+func batchItemErrorResponse(err error) *models.ErrorResponse {
+	if errors.Is(err, repositories.ErrCurrencyMismatch) {
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "currency does not match wallet currency",
 		}
+	}
+	return balanceErrorResponse(err)
+}
+
+// checkBalanceChange refuses operations in a currency other than the wallet
+// one and deposits that would overflow the balance. The balance itself is
+// checked again by the database when it is updated.
+func checkBalanceChange(walletEntity entities.Wallet, changeBalanceReq models.ChangeBalanceRequest) *models.ErrorResponse {
+	balance, err := models.NewMoney(walletEntity.Balance, walletEntity.Currency)
+	if err != nil {
 		return &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "internal server error",
 		}
 	}
+	amount, err := models.NewMoney(changeBalanceReq.Balance, changeBalanceReq.Currency)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "unknown currency",
+		}
+	}
+	if changeBalanceReq.OperationType == models.Operation_type_deposit {
+		_, err = balance.Add(amount)
+	} else {
+		_, err = balance.Sub(amount)
+	}
+	switch {
+	case errors.Is(err, models.ErrCurrencyMismatch):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "currency does not match wallet currency " + walletEntity.Currency,
+		}
+	case errors.Is(err, models.ErrAmountOverflow):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "balance overflow",
+		}
+	}
+	return nil
+}
+
+func (s *walletService) Transfer(ctx context.Context, transferReq models.TransferRequest) *models.ErrorResponse {
+	if transferReq.QuoteID != nil {
+		logger.AddFields(ctx, "quote_id", *transferReq.QuoteID)
+	} else {
+		logger.AddFields(ctx, "from_wallet_id", transferReq.FromID, "to_wallet_id", transferReq.ToID, "amount", transferReq.Amount)
+	}
+	if errResp := s.authorizeTransfer(ctx, transferReq); errResp != nil {
+		return errResp
+	}
+	var err error
+	if transferReq.QuoteID != nil {
+		err = s.walletRepo.ExecuteFXQuote(ctx, *transferReq.QuoteID)
+	} else {
+		err = s.walletRepo.Transfer(ctx, transferReq.FromID, transferReq.ToID, transferReq.Amount)
+	}
+	if err != nil {
+		return balanceErrorResponse(err)
+	}
 	return nil
 }
 
-func (s *walletService) GetWallets(ctx context.Context) ([]models.GetWalletsResponse, *models.ErrorResponse) {
-	var wallets []models.GetWalletsResponse
-	walletEntities, err := s.walletRepo.GetWallets(ctx)
+// authorizeTransfer checks the owner of the wallet the money is taken from,
+// for a quote it is the wallet the quote was created for.
+func (s *walletService) authorizeTransfer(ctx context.Context, transferReq models.TransferRequest) *models.ErrorResponse {
+	if transferReq.QuoteID == nil {
+		return authorizeWallets(ctx, s.walletRepo, transferReq.FromID)
+	}
+	if _, ok := restricted(ctx); !ok {
+		return nil
+	}
+	walletID, err := s.walletRepo.FindFXQuoteWallet(ctx, *transferReq.QuoteID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrFXQuoteNotFound) {
+			return nil
+		}
+		return internalError()
+	}
+	return authorizeWallets(ctx, s.walletRepo, walletID)
+}
+
+func (s *walletService) GetSpendingLimits(ctx context.Context, id uuid.UUID) (models.SpendingLimits, *models.ErrorResponse) {
+	if errResp := authorizeWallets(ctx, s.walletRepo, id); errResp != nil {
+		return models.SpendingLimits{}, errResp
+	}
+	limitsEntity, err := s.walletRepo.FindSpendingLimits(ctx, id)
+	if err != nil {
+		return models.SpendingLimits{}, spendingLimitsErrorResponse(err)
+	}
+	return toSpendingLimitsModel(limitsEntity)
+}
+
+func (s *walletService) SetSpendingLimits(ctx context.Context, id uuid.UUID, limitsReq models.SpendingLimits) (models.SpendingLimits, *models.ErrorResponse) {
+	logger.AddFields(ctx, "wallet_id", id)
+	if errResp := requireAdmin(ctx); errResp != nil {
+		return models.SpendingLimits{}, errResp
+	}
+	limitsEntity, err := s.walletRepo.SetSpendingLimits(ctx, entities.SpendingLimits{
+		WalletID:             id,
+		MaxSingleWithdrawal:  limitsReq.MaxSingleWithdrawal,
+		MaxDailyWithdrawal:   limitsReq.MaxDailyWithdrawal,
+		MaxMonthlyWithdrawal: limitsReq.MaxMonthlyWithdrawal,
+		MaxHourlyOperations:  limitsReq.MaxHourlyOperations,
+	})
+	if err != nil {
+		return models.SpendingLimits{}, spendingLimitsErrorResponse(err)
+	}
+	return toSpendingLimitsModel(limitsEntity)
+}
+
+func toSpendingLimitsModel(limitsEntity entities.SpendingLimits) (models.SpendingLimits, *models.ErrorResponse) {
+	var limits models.SpendingLimits
+	if err := copier.Copy(&limits, &limitsEntity); err != nil {
+		return models.SpendingLimits{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	return limits, nil
+}
+
+func spendingLimitsErrorResponse(err error) *models.ErrorResponse {
+	if errors.Is(err, repositories.ErrWalletNotFound) {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "wallet not found",
+		}
+	}
+	return &models.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "internal server error",
+	}
+}
+
+func (s *walletService) GetWallets(ctx context.Context, req models.GetWalletsRequest) (models.GetWalletsResponse, *models.ErrorResponse) {
+	if errResp := requireAdmin(ctx); errResp != nil {
+		return models.GetWalletsResponse{}, errResp
+	}
+	limit := pageLimit(req.Limit)
+	filter := repositories.WalletsFilter{
+		Status:     req.Status,
+		MinBalance: req.MinBalance,
+		MaxBalance: req.MaxBalance,
+		SortBy:     req.Sort,
+		Descending: req.Order == models.Sort_order_desc,
+		Limit:      limit + 1,
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = models.Wallets_sort_created
+	}
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor, filter.SortBy, req.Order, func(value string) bool {
+			return validWalletSortValue(filter.SortBy, value)
+		})
+		if err != nil {
+			return models.GetWalletsResponse{}, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "incorrect cursor",
+			}
+		}
+		filter.AfterValue, filter.AfterID = &c.Value, &c.ID
+	}
+	walletEntities, err := s.walletRepo.GetWallets(ctx, filter)
 	if err != nil {
-		return nil, &models.ErrorResponse{
+		if errors.Is(err, repositories.ErrUnknownSortColumn) {
+			return models.GetWalletsResponse{}, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "incorrect sort",
+			}
+		}
+		return models.GetWalletsResponse{}, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+		}
+	}
+	response := models.GetWalletsResponse{Wallets: []models.Wallet{}}
+	if len(walletEntities) > limit {
+		walletEntities = walletEntities[:limit]
+		last := walletEntities[limit-1]
+		response.NextCursor = encodeCursor(cursor{
+			Sort:  filter.SortBy,
+			Order: sortOrder(req.Order),
+			Value: walletSortValue(last, filter.SortBy),
+			ID:    last.ID,
+		})
+	}
+	if err = copier.Copy(&response.Wallets, &walletEntities); err != nil {
+		return models.GetWalletsResponse{}, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "internal server error",
 		}
 	}
-	if err = copier.Copy(&wallets, &walletEntities); err != nil {
-		return nil, &models.ErrorResponse{
+	return response, nil
+}
+
+func walletSortValue(wallet entities.Wallet, sortBy string) string {
+	switch sortBy {
+	case models.Wallets_sort_updated:
+		return wallet.Updated.Format(cursorTimeLayout)
+	case models.Wallets_sort_balance:
+		return strconv.FormatInt(wallet.Balance, 10)
+	default:
+		return wallet.Created.Format(cursorTimeLayout)
+	}
+}
+
+// validWalletSortValue checks that the cursor value casts to the type of the
+// sort column.
+func validWalletSortValue(sortBy, value string) bool {
+	switch sortBy {
+	case models.Wallets_sort_created, models.Wallets_sort_updated:
+		return validCursorTime(value)
+	case models.Wallets_sort_balance:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func balanceErrorResponse(err error) *models.ErrorResponse {
+	switch {
+	case errors.Is(err, repositories.ErrSpendingLimitExceeded):
+		// a distinct code, so clients can tell a limit from a lack of money
+		return &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		}
+	case errors.Is(err, repositories.ErrWalletNotEnoughBalance):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "not enough balance",
+		}
+	case errors.Is(err, repositories.ErrNoRowsForUpdate):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "wallet not found",
+		}
+	case errors.Is(err, repositories.ErrWalletClosed):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "wallet is closed",
+		}
+	case errors.Is(err, repositories.ErrWalletFrozen):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "wallet is frozen",
+		}
+	case errors.Is(err, repositories.ErrCurrencyMismatch):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "wallet currencies do not match, transfer with an fx quote",
+		}
+	case errors.Is(err, repositories.ErrFXQuoteNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "fx quote not found",
+		}
+	case errors.Is(err, repositories.ErrFXQuoteExpired):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "fx quote expired",
+		}
+	case errors.Is(err, repositories.ErrFXQuoteExecuted):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "fx quote already executed",
+		}
+	case errors.Is(err, repositories.ErrBalanceOverflow):
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "balance overflow",
+		}
+	default:
+		return &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "internal server error",
 		}
 	}
-	return wallets, nil
 }
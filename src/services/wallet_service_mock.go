@@ -14,6 +14,9 @@ type WalletServiceMock struct {
 
 func (m *WalletServiceMock) GetWalletByID(ctx context.Context, id uuid.UUID) (models.GetBalanceResponse, *models.ErrorResponse) {
 	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.GetBalanceResponse), nil
+	}
 	return args.Get(0).(models.GetBalanceResponse), args.Get(1).(*models.ErrorResponse)
 }
 
@@ -25,7 +28,66 @@ func (m *WalletServiceMock) ChangeWalletBalance(ctx context.Context, req models.
 	return args.Get(0).(*models.ErrorResponse)
 }
 
-func (m *WalletServiceMock) GetWallets(ctx context.Context) ([]models.GetWalletsResponse, *models.ErrorResponse) {
-	args := m.Called(ctx)
-	return args.Get(0).([]models.GetWalletsResponse), args.Get(1).(*models.ErrorResponse)
+func (m *WalletServiceMock) GetWallets(ctx context.Context, req models.GetWalletsRequest) (models.GetWalletsResponse, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.GetWalletsResponse), nil
+	}
+	return args.Get(0).(models.GetWalletsResponse), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) Transfer(ctx context.Context, req models.TransferRequest) *models.ErrorResponse {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) CreateWallet(ctx context.Context, req models.CreateWalletRequest) (models.Wallet, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Wallet), nil
+	}
+	return args.Get(0).(models.Wallet), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) ChangeWalletStatus(ctx context.Context, id uuid.UUID, status string) (models.Wallet, *models.ErrorResponse) {
+	args := m.Called(ctx, id, status)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Wallet), nil
+	}
+	return args.Get(0).(models.Wallet), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) ChangeWalletLimits(ctx context.Context, id uuid.UUID, actor string, req models.ChangeWalletLimitsRequest) (models.Wallet, *models.ErrorResponse) {
+	args := m.Called(ctx, id, actor, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Wallet), nil
+	}
+	return args.Get(0).(models.Wallet), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) GetSpendingLimits(ctx context.Context, id uuid.UUID) (models.SpendingLimits, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.SpendingLimits), nil
+	}
+	return args.Get(0).(models.SpendingLimits), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) SetSpendingLimits(ctx context.Context, id uuid.UUID, req models.SpendingLimits) (models.SpendingLimits, *models.ErrorResponse) {
+	args := m.Called(ctx, id, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.SpendingLimits), nil
+	}
+	return args.Get(0).(models.SpendingLimits), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WalletServiceMock) ChangeWalletBalanceBatch(ctx context.Context, req models.BatchChangeBalanceRequest) (models.BatchChangeBalanceResponse, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.BatchChangeBalanceResponse), nil
+	}
+	return args.Get(0).(models.BatchChangeBalanceResponse), args.Get(1).(*models.ErrorResponse)
 }
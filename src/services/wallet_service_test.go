@@ -2,9 +2,14 @@ package services_test
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"testing"
+	"time"
+	"wallet-api/pkg/auth"
 	"wallet-api/src/database/entities"
 	"wallet-api/src/database/repositories"
 	"wallet-api/src/models"
@@ -12,6 +17,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestWalletService_GetWalletByID_Success(t *testing.T) {
@@ -24,12 +30,15 @@ func TestWalletService_GetWalletByID_Success(t *testing.T) {
 		entity := entities.Wallet{
 			ID:      validID,
 			Balance: 1000,
+			Held:    300,
 		}
 		mockRepo.On("FindByID", ctx, validID).Return(entity, nil)
 
 		wallet, errResp := svc.GetWalletByID(ctx, validID)
 		assert.Nil(t, errResp)
 		assert.Equal(t, entity.Balance, wallet.Balance)
+		assert.Equal(t, int64(300), wallet.Held)
+		assert.Equal(t, int64(700), wallet.Available)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -68,11 +77,13 @@ func TestWalletService_ChangeWalletBalance(t *testing.T) {
 	svc := services.NewWalletService(mockRepo)
 	ctx := context.Background()
 	walletID := uuid.New()
+	mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
 
 	t.Run("deposit success", func(t *testing.T) {
 		req := models.ChangeBalanceRequest{
 			ID:            walletID,
 			Balance:       500,
+			Currency:      models.Default_currency,
 			OperationType: models.Operation_type_deposit,
 		}
 		mockRepo.On("DepositUpdate", ctx, walletID, req.Balance).Return(nil)
@@ -86,6 +97,7 @@ func TestWalletService_ChangeWalletBalance(t *testing.T) {
 		req := models.ChangeBalanceRequest{
 			ID:            walletID,
 			Balance:       1000,
+			Currency:      models.Default_currency,
 			OperationType: models.Operation_type_withdraw,
 		}
 		mockRepo.On("WithdrawUpdate", ctx, walletID, req.Balance).Return(repositories.ErrWalletNotEnoughBalance)
@@ -94,6 +106,24 @@ func TestWalletService_ChangeWalletBalance(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, errResp.Code)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("without currency the wallet one is used", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{
+			ID:            walletID,
+			Balance:       300,
+			OperationType: models.Operation_type_deposit,
+		}
+		mockRepo.On("DepositUpdate", ctx, walletID, req.Balance).Return(nil).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("currency of another wallet", func(t *testing.T) {
+		errResp := svc.ChangeWalletBalance(ctx, models.ChangeBalanceRequest{ID: walletID, Balance: 300, Currency: "USD", OperationType: models.Operation_type_deposit})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
 }
 
 func TestWalletService_ChangeWalletBalance_Withdraw_NotFound(t *testing.T) {
@@ -101,11 +131,13 @@ func TestWalletService_ChangeWalletBalance_Withdraw_NotFound(t *testing.T) {
 	svc := services.NewWalletService(mockRepo)
 	ctx := context.Background()
 	walletID := uuid.New()
+	mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
 
 	t.Run("withdraw wallet not found", func(t *testing.T) {
 		req := models.ChangeBalanceRequest{
 			ID:            walletID,
 			Balance:       1000,
+			Currency:      models.Default_currency,
 			OperationType: models.Operation_type_withdraw,
 		}
 		mockRepo.On("WithdrawUpdate", ctx, walletID, req.Balance).Return(repositories.ErrNoRowsForUpdate)
@@ -121,11 +153,13 @@ func TestWalletService_ChangeWalletBalance_Withdraw_InternalError(t *testing.T)
 	svc := services.NewWalletService(mockRepo)
 	ctx := context.Background()
 	walletID := uuid.New()
+	mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
 
 	t.Run("withdraw internal error", func(t *testing.T) {
 		req := models.ChangeBalanceRequest{
 			ID:            walletID,
 			Balance:       1000,
+			Currency:      models.Default_currency,
 			OperationType: models.Operation_type_withdraw,
 		}
 		mockRepo.On("WithdrawUpdate", ctx, walletID, req.Balance).Return(errors.New("db error"))
@@ -135,3 +169,580 @@ func TestWalletService_ChangeWalletBalance_Withdraw_InternalError(t *testing.T)
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestWalletService_Transfer(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	req := models.TransferRequest{
+		FromID: uuid.New(),
+		ToID:   uuid.New(),
+		Amount: 700,
+	}
+
+	t.Run("transfer success", func(t *testing.T) {
+		mockRepo.On("Transfer", ctx, req.FromID, req.ToID, req.Amount).Return(nil).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer insufficient balance", func(t *testing.T) {
+		mockRepo.On("Transfer", ctx, req.FromID, req.ToID, req.Amount).Return(repositories.ErrWalletNotEnoughBalance).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer wallet not found", func(t *testing.T) {
+		mockRepo.On("Transfer", ctx, req.FromID, req.ToID, req.Amount).Return(repositories.ErrNoRowsForUpdate).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer internal error", func(t *testing.T) {
+		mockRepo.On("Transfer", ctx, req.FromID, req.ToID, req.Amount).Return(errors.New("db error")).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_CreateWallet(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		entity := entities.Wallet{ID: uuid.New(), Status: entities.Wallet_status_active}
+		mockRepo.On("Create", ctx, models.Default_currency, (*string)(nil)).Return(entity, nil).Once()
+
+		wallet, errResp := svc.CreateWallet(ctx, models.CreateWalletRequest{})
+		assert.Nil(t, errResp)
+		assert.Equal(t, entity.ID, wallet.ID)
+		assert.Equal(t, models.Wallet_status_active, wallet.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockRepo.On("Create", ctx, "USD", (*string)(nil)).Return(entities.Wallet{}, errors.New("db error")).Once()
+
+		_, errResp := svc.CreateWallet(ctx, models.CreateWalletRequest{Currency: "USD"})
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_ChangeWalletStatus(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	t.Run("freeze success", func(t *testing.T) {
+		entity := entities.Wallet{ID: walletID, Status: entities.Wallet_status_frozen}
+		mockRepo.On("ChangeStatus", ctx, walletID, models.Wallet_status_frozen).Return(entity, nil).Once()
+
+		wallet, errResp := svc.ChangeWalletStatus(ctx, walletID, models.Wallet_status_frozen)
+		assert.Nil(t, errResp)
+		assert.Equal(t, models.Wallet_status_frozen, wallet.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mockRepo.On("ChangeStatus", ctx, walletID, models.Wallet_status_frozen).Return(entities.Wallet{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.ChangeWalletStatus(ctx, walletID, models.Wallet_status_frozen)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transition not allowed", func(t *testing.T) {
+		mockRepo.On("ChangeStatus", ctx, walletID, models.Wallet_status_active).Return(entities.Wallet{}, repositories.ErrWalletStatusTransition).Once()
+
+		_, errResp := svc.ChangeWalletStatus(ctx, walletID, models.Wallet_status_active)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("close with balance", func(t *testing.T) {
+		mockRepo.On("ChangeStatus", ctx, walletID, models.Wallet_status_closed).Return(entities.Wallet{}, repositories.ErrWalletBalanceNotZero).Once()
+
+		_, errResp := svc.ChangeWalletStatus(ctx, walletID, models.Wallet_status_closed)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_ChangeWalletBalance_WalletStatus(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+	mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
+
+	t.Run("withdraw from frozen wallet", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{ID: walletID, Balance: 100, Currency: models.Default_currency, OperationType: models.Operation_type_withdraw}
+		mockRepo.On("WithdrawUpdate", ctx, walletID, req.Balance).Return(repositories.ErrWalletFrozen).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+		assert.Equal(t, "wallet is frozen", errResp.Message)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deposit to closed wallet", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{ID: walletID, Balance: 100, Currency: models.Default_currency, OperationType: models.Operation_type_deposit}
+		mockRepo.On("DepositUpdate", ctx, walletID, req.Balance).Return(repositories.ErrWalletClosed).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+		assert.Equal(t, "wallet is closed", errResp.Message)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_GetWallets(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+
+	created := time.Date(2025, 7, 1, 12, 0, 0, 123456000, time.UTC)
+	entityList := []entities.Wallet{
+		{ID: uuid.New(), Balance: 100, Created: created},
+		{ID: uuid.New(), Balance: 200, Created: created.Add(time.Second)},
+		{ID: uuid.New(), Balance: 300, Created: created.Add(2 * time.Second)},
+	}
+
+	t.Run("first page with next cursor", func(t *testing.T) {
+		filter := repositories.WalletsFilter{SortBy: models.Wallets_sort_created, Limit: 3}
+		mockRepo.On("GetWallets", ctx, filter).Return(entityList, nil).Once()
+
+		resp, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{Limit: 2})
+		assert.Nil(t, errResp)
+		assert.Len(t, resp.Wallets, 2)
+		assert.NotEmpty(t, resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+
+		t.Run("next page starts after cursor", func(t *testing.T) {
+			afterValue := "2025-07-01 12:00:01.123456"
+			afterID := entityList[1].ID
+			filter := repositories.WalletsFilter{
+				SortBy:     models.Wallets_sort_created,
+				AfterValue: &afterValue,
+				AfterID:    &afterID,
+				Limit:      3,
+			}
+			mockRepo.On("GetWallets", ctx, filter).Return(entityList[2:], nil).Once()
+
+			next, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{Limit: 2, Cursor: resp.NextCursor})
+			assert.Nil(t, errResp)
+			assert.Len(t, next.Wallets, 1)
+			assert.Empty(t, next.NextCursor)
+			mockRepo.AssertExpectations(t)
+		})
+
+		t.Run("cursor of another listing", func(t *testing.T) {
+			for _, req := range []models.GetWalletsRequest{
+				{Limit: 2, Cursor: resp.NextCursor, Sort: models.Wallets_sort_balance},
+				{Limit: 2, Cursor: resp.NextCursor, Order: models.Sort_order_desc},
+			} {
+				_, errResp := svc.GetWallets(ctx, req)
+				if assert.NotNil(t, errResp) {
+					assert.Equal(t, http.StatusBadRequest, errResp.Code)
+				}
+			}
+		})
+	})
+
+	t.Run("tampered cursor value", func(t *testing.T) {
+		raw := fmt.Sprintf(`{"s":"balance","o":"asc","v":"1; drop table wallet","id":%q}`, uuid.New())
+		_, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{
+			Sort:   models.Wallets_sort_balance,
+			Cursor: base64.RawURLEncoding.EncodeToString([]byte(raw)),
+		})
+		if assert.NotNil(t, errResp) {
+			assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		}
+	})
+
+	t.Run("filters and descending balance sort", func(t *testing.T) {
+		status := models.Wallet_status_active
+		minBalance := int64(150)
+		filter := repositories.WalletsFilter{
+			Status:     &status,
+			MinBalance: &minBalance,
+			SortBy:     models.Wallets_sort_balance,
+			Descending: true,
+			Limit:      models.Default_page_limit + 1,
+		}
+		mockRepo.On("GetWallets", ctx, filter).Return(entityList[1:], nil).Once()
+
+		resp, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{
+			Sort:       models.Wallets_sort_balance,
+			Order:      models.Sort_order_desc,
+			Status:     &status,
+			MinBalance: &minBalance,
+		})
+		assert.Nil(t, errResp)
+		assert.Len(t, resp.Wallets, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("incorrect cursor", func(t *testing.T) {
+		_, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{Cursor: "not a cursor"})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockRepo.On("GetWallets", ctx, mock.Anything).Return([]entities.Wallet(nil), errors.New("db error")).Once()
+
+		_, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{})
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_ChangeWalletBalance_Currency(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	t.Run("wallet not found", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{ID: walletID, Balance: 100, Currency: "USD", OperationType: models.Operation_type_deposit}
+		mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{}, repositories.ErrWalletNotFound).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("currency mismatch", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{ID: walletID, Balance: 100, Currency: "EUR", OperationType: models.Operation_type_deposit}
+		mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		mockRepo.AssertNotCalled(t, "DepositUpdate", ctx, walletID, req.Balance)
+	})
+
+	t.Run("deposit overflow", func(t *testing.T) {
+		req := models.ChangeBalanceRequest{ID: walletID, Balance: 2, Currency: "USD", OperationType: models.Operation_type_deposit}
+		mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: math.MaxInt64 - 1, Currency: "USD"}, nil).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, req)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		assert.Equal(t, "balance overflow", errResp.Message)
+		mockRepo.AssertNotCalled(t, "DepositUpdate", ctx, walletID, req.Balance)
+	})
+
+	t.Run("create wallet with unknown currency", func(t *testing.T) {
+		_, errResp := svc.CreateWallet(ctx, models.CreateWalletRequest{Currency: "XXX"})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	})
+}
+
+func TestWalletService_Transfer_FXQuote(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	quoteID := uuid.New()
+	req := models.TransferRequest{QuoteID: &quoteID}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("ExecuteFXQuote", ctx, quoteID).Return(nil).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Transfer", ctx, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("quote not found", func(t *testing.T) {
+		mockRepo.On("ExecuteFXQuote", ctx, quoteID).Return(repositories.ErrFXQuoteNotFound).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+
+	t.Run("quote expired", func(t *testing.T) {
+		mockRepo.On("ExecuteFXQuote", ctx, quoteID).Return(repositories.ErrFXQuoteExpired).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+	})
+
+	t.Run("quote executed", func(t *testing.T) {
+		mockRepo.On("ExecuteFXQuote", ctx, quoteID).Return(repositories.ErrFXQuoteExecuted).Once()
+
+		errResp := svc.Transfer(ctx, req)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+	})
+}
+
+func TestWalletService_GetWalletByID_CreditLimit(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	id := uuid.New()
+	minBalance := int64(1000)
+
+	mockRepo.On("FindByID", ctx, id).Return(entities.Wallet{ID: id, Balance: 3000, Held: 500, CreditLimit: 5000, MinBalance: &minBalance}, nil).Once()
+
+	wallet, errResp := svc.GetWalletByID(ctx, id)
+	assert.Nil(t, errResp)
+	assert.Equal(t, int64(6500), wallet.Available)
+	assert.Equal(t, int64(5000), wallet.CreditLimit)
+	assert.Equal(t, &minBalance, wallet.MinBalance)
+}
+
+func TestWalletService_ChangeWalletLimits(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	id := uuid.New()
+	req := models.ChangeWalletLimitsRequest{CreditLimit: 100000, Reason: "postpaid contract"}
+	limits := entities.WalletLimits{CreditLimit: 100000}
+	audit := entities.WalletAudit{Actor: "admin", Reason: "postpaid contract"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("ChangeLimits", ctx, id, limits, audit).Return(entities.Wallet{ID: id, CreditLimit: 100000}, nil).Once()
+
+		wallet, errResp := svc.ChangeWalletLimits(ctx, id, "admin", req)
+		assert.Nil(t, errResp)
+		assert.Equal(t, int64(100000), wallet.CreditLimit)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mockRepo.On("ChangeLimits", ctx, id, limits, audit).Return(entities.Wallet{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.ChangeWalletLimits(ctx, id, "admin", req)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+
+	t.Run("wallet closed", func(t *testing.T) {
+		mockRepo.On("ChangeLimits", ctx, id, limits, audit).Return(entities.Wallet{}, repositories.ErrWalletClosed).Once()
+
+		_, errResp := svc.ChangeWalletLimits(ctx, id, "admin", req)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+	})
+}
+
+func TestWalletService_ChangeWalletBalance_SpendingLimit(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+	mockRepo.On("FindByID", ctx, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
+
+	req := models.ChangeBalanceRequest{
+		ID:            walletID,
+		Balance:       500,
+		Currency:      models.Default_currency,
+		OperationType: models.Operation_type_withdraw,
+	}
+	limitErr := fmt.Errorf("%w: %s", repositories.ErrSpendingLimitExceeded, "daily")
+	mockRepo.On("WithdrawUpdate", ctx, walletID, req.Balance).Return(limitErr).Once()
+
+	errResp := svc.ChangeWalletBalance(ctx, req)
+	assert.Equal(t, http.StatusForbidden, errResp.Code)
+	assert.Equal(t, "spending limit exceeded: daily", errResp.Message)
+}
+
+func TestWalletService_SpendingLimits(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	walletID := uuid.New()
+	single := int64(5000)
+
+	t.Run("set", func(t *testing.T) {
+		entity := entities.SpendingLimits{WalletID: walletID, MaxSingleWithdrawal: &single}
+		mockRepo.On("SetSpendingLimits", ctx, entity).Return(entity, nil).Once()
+
+		limits, errResp := svc.SetSpendingLimits(ctx, walletID, models.SpendingLimits{MaxSingleWithdrawal: &single})
+		assert.Nil(t, errResp)
+		assert.Equal(t, &single, limits.MaxSingleWithdrawal)
+		assert.Nil(t, limits.MaxDailyWithdrawal)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mockRepo.On("FindSpendingLimits", ctx, walletID).Return(entities.SpendingLimits{}, repositories.ErrWalletNotFound).Once()
+
+		_, errResp := svc.GetSpendingLimits(ctx, walletID)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+}
+
+func TestWalletService_ChangeWalletBalanceBatch(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	ctx := context.Background()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	items := []models.ChangeBalanceRequest{
+		{ID: first, Balance: 100, Currency: "RUB", OperationType: models.Operation_type_deposit},
+		{ID: second, Balance: 200, Currency: "RUB", OperationType: models.Operation_type_withdraw},
+		{ID: third, Balance: 300, Currency: "RUB", OperationType: models.Operation_type_deposit},
+	}
+	changes := []repositories.BalanceChange{
+		{WalletID: first, Type: entities.Transaction_type_deposit, Amount: 100, Currency: "RUB"},
+		{WalletID: second, Type: entities.Transaction_type_withdraw, Amount: 200, Currency: "RUB"},
+		{WalletID: third, Type: entities.Transaction_type_deposit, Amount: 300, Currency: "RUB"},
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		results := []repositories.BalanceChangeResult{
+			{Transaction: entities.Transaction{ID: uuid.New(), BalanceAfter: 100}},
+			{Err: repositories.ErrWalletNotEnoughBalance},
+			{Transaction: entities.Transaction{ID: uuid.New(), BalanceAfter: 300}},
+		}
+		mockRepo.On("ApplyBatch", ctx, changes, false).Return(results, nil).Once()
+
+		resp, errResp := svc.ChangeWalletBalanceBatch(ctx, models.BatchChangeBalanceRequest{Mode: models.Batch_mode_best_effort, Items: items})
+		assert.Nil(t, errResp)
+		assert.Equal(t, 2, resp.Applied)
+		assert.Equal(t, 1, resp.Failed)
+		assert.Equal(t, models.Batch_item_applied, resp.Results[0].Status)
+		assert.Equal(t, &results[0].Transaction.ID, resp.Results[0].TransactionID)
+		assert.Equal(t, models.Batch_item_failed, resp.Results[1].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[1].Error.Code)
+		assert.Equal(t, models.Batch_item_applied, resp.Results[2].Status)
+	})
+
+	t.Run("atomic rolled back", func(t *testing.T) {
+		results := []repositories.BalanceChangeResult{
+			{Transaction: entities.Transaction{ID: uuid.New()}},
+			{Err: repositories.ErrWalletFrozen},
+		}
+		mockRepo.On("ApplyBatch", ctx, changes, true).Return(results, nil).Once()
+
+		resp, errResp := svc.ChangeWalletBalanceBatch(ctx, models.BatchChangeBalanceRequest{Mode: models.Batch_mode_atomic, Items: items})
+		assert.Nil(t, errResp)
+		assert.Equal(t, 0, resp.Applied)
+		assert.Equal(t, 1, resp.Failed)
+		assert.Equal(t, models.Batch_item_rolled_back, resp.Results[0].Status)
+		assert.Nil(t, resp.Results[0].TransactionID)
+		assert.Equal(t, models.Batch_item_failed, resp.Results[1].Status)
+		assert.Equal(t, http.StatusConflict, resp.Results[1].Error.Code)
+		assert.Equal(t, models.Batch_item_skipped, resp.Results[2].Status)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockRepo.On("ApplyBatch", ctx, changes, true).Return([]repositories.BalanceChangeResult(nil), errors.New("db error")).Once()
+
+		_, errResp := svc.ChangeWalletBalanceBatch(ctx, models.BatchChangeBalanceRequest{Mode: models.Batch_mode_atomic, Items: items})
+		assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+	})
+}
+
+func TestWalletService_Ownership(t *testing.T) {
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.NewWalletService(mockRepo)
+	owner, other := "shop", "other"
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: owner})
+	adminCtx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "ops", Scopes: []string{auth.ScopeAdmin}})
+	own := entities.Wallet{ID: uuid.New(), Currency: "RUB", Owner: &owner}
+	foreign := entities.Wallet{ID: uuid.New(), Currency: "RUB", Owner: &other}
+
+	t.Run("own wallet", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, own.ID).Return(own, nil).Once()
+
+		_, errResp := svc.GetWalletByID(ctx, own.ID)
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("foreign wallet", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, foreign.ID).Return(foreign, nil).Once()
+
+		errResp := svc.ChangeWalletBalance(ctx, models.ChangeBalanceRequest{ID: foreign.ID, OperationType: models.Operation_type_withdraw, Balance: 10, Currency: "RUB"})
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("admin touches foreign wallet", func(t *testing.T) {
+		mockRepo.On("FindByID", adminCtx, foreign.ID).Return(foreign, nil).Once()
+
+		_, errResp := svc.GetWalletByID(adminCtx, foreign.ID)
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("batch with a foreign wallet", func(t *testing.T) {
+		mockRepo.On("FindOwners", ctx, []uuid.UUID{own.ID, foreign.ID}).Return(map[uuid.UUID]*string{own.ID: &owner, foreign.ID: &other}, nil).Once()
+
+		_, errResp := svc.ChangeWalletBalanceBatch(ctx, models.BatchChangeBalanceRequest{Mode: models.Batch_mode_atomic, Items: []models.ChangeBalanceRequest{{ID: own.ID}, {ID: foreign.ID}}})
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("transfer by a foreign quote", func(t *testing.T) {
+		quoteID := uuid.New()
+		mockRepo.On("FindFXQuoteWallet", ctx, quoteID).Return(foreign.ID, nil).Once()
+		mockRepo.On("FindOwners", ctx, []uuid.UUID{foreign.ID}).Return(map[uuid.UUID]*string{foreign.ID: &other}, nil).Once()
+
+		errResp := svc.Transfer(ctx, models.TransferRequest{QuoteID: &quoteID})
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wallet without owner", func(t *testing.T) {
+		id := uuid.New()
+		mockRepo.On("FindOwners", ctx, []uuid.UUID{id}).Return(map[uuid.UUID]*string{id: nil}, nil).Once()
+
+		_, errResp := svc.ChangeWalletStatus(ctx, id, models.Wallet_status_frozen)
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("owner freezes but does not unfreeze or close", func(t *testing.T) {
+		mockRepo.On("FindOwners", ctx, []uuid.UUID{own.ID}).Return(map[uuid.UUID]*string{own.ID: &owner}, nil).Once()
+		mockRepo.On("ChangeStatus", ctx, own.ID, models.Wallet_status_frozen).Return(own, nil).Once()
+
+		_, errResp := svc.ChangeWalletStatus(ctx, own.ID, models.Wallet_status_frozen)
+		assert.Nil(t, errResp)
+		_, errResp = svc.ChangeWalletStatus(ctx, own.ID, models.Wallet_status_active)
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		_, errResp = svc.ChangeWalletStatus(ctx, own.ID, models.Wallet_status_closed)
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("spending limits are set by admins", func(t *testing.T) {
+		_, errResp := svc.SetSpendingLimits(ctx, own.ID, models.SpendingLimits{})
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+
+		entity := entities.SpendingLimits{WalletID: own.ID}
+		mockRepo.On("SetSpendingLimits", adminCtx, entity).Return(entity, nil).Once()
+		_, errResp = svc.SetSpendingLimits(adminCtx, own.ID, models.SpendingLimits{})
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("created wallet is owned", func(t *testing.T) {
+		mockRepo.On("Create", ctx, models.Default_currency, &owner).Return(own, nil).Once()
+
+		wallet, errResp := svc.CreateWallet(ctx, models.CreateWalletRequest{})
+		assert.Nil(t, errResp)
+		assert.Equal(t, &owner, wallet.Owner)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("list needs admin", func(t *testing.T) {
+		_, errResp := svc.GetWallets(ctx, models.GetWalletsRequest{})
+		assert.Equal(t, http.StatusForbidden, errResp.Code)
+
+		mockRepo.On("GetWallets", adminCtx, mock.Anything).Return([]entities.Wallet{}, nil).Once()
+		_, errResp = svc.GetWallets(adminCtx, models.GetWalletsRequest{})
+		assert.Nil(t, errResp)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"wallet-api/pkg/tracing"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	walletIDAttribute     = attribute.Key("wallet.id")
+	errorCodeAttribute    = attribute.Key("wallet.error.code")
+	errorMessageAttribute = attribute.Key("wallet.error.message")
+)
+
+type tracedWalletService struct {
+	next WalletService
+}
+
+// TraceWalletService makes a span of every call of next. Refused requests
+// keep their code on the span, only internal errors fail it.
+func TraceWalletService(next WalletService) WalletService {
+	return &tracedWalletService{next: next}
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "WalletService."+name, trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, errResp *models.ErrorResponse) {
+	if errResp != nil {
+		span.SetAttributes(errorCodeAttribute.Int(errResp.Code), errorMessageAttribute.String(errResp.Message))
+		if errResp.Code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, errResp.Message)
+		}
+	}
+	span.End()
+}
+
+func (s *tracedWalletService) GetWalletByID(ctx context.Context, id uuid.UUID) (models.GetBalanceResponse, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "GetWalletByID", walletIDAttribute.String(id.String()))
+	resp, errResp := s.next.GetWalletByID(ctx, id)
+	endSpan(span, errResp)
+	return resp, errResp
+}
+
+func (s *tracedWalletService) CreateWallet(ctx context.Context, createReq models.CreateWalletRequest) (models.Wallet, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "CreateWallet")
+	wallet, errResp := s.next.CreateWallet(ctx, createReq)
+	endSpan(span, errResp)
+	return wallet, errResp
+}
+
+func (s *tracedWalletService) ChangeWalletStatus(ctx context.Context, id uuid.UUID, status string) (models.Wallet, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "ChangeWalletStatus", walletIDAttribute.String(id.String()))
+	wallet, errResp := s.next.ChangeWalletStatus(ctx, id, status)
+	endSpan(span, errResp)
+	return wallet, errResp
+}
+
+func (s *tracedWalletService) ChangeWalletLimits(ctx context.Context, id uuid.UUID, actor string, limitsReq models.ChangeWalletLimitsRequest) (models.Wallet, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "ChangeWalletLimits", walletIDAttribute.String(id.String()))
+	wallet, errResp := s.next.ChangeWalletLimits(ctx, id, actor, limitsReq)
+	endSpan(span, errResp)
+	return wallet, errResp
+}
+
+func (s *tracedWalletService) ChangeWalletBalance(ctx context.Context, changeBalanceReq models.ChangeBalanceRequest) *models.ErrorResponse {
+	ctx, span := startSpan(ctx, "ChangeWalletBalance",
+		walletIDAttribute.String(changeBalanceReq.ID.String()),
+		attribute.String("wallet.operation", changeBalanceReq.OperationType),
+	)
+	errResp := s.next.ChangeWalletBalance(ctx, changeBalanceReq)
+	endSpan(span, errResp)
+	return errResp
+}
+
+func (s *tracedWalletService) ChangeWalletBalanceBatch(ctx context.Context, batchReq models.BatchChangeBalanceRequest) (models.BatchChangeBalanceResponse, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "ChangeWalletBalanceBatch",
+		attribute.String("wallet.batch.mode", batchReq.Mode),
+		attribute.Int("wallet.batch.items", len(batchReq.Items)),
+	)
+	resp, errResp := s.next.ChangeWalletBalanceBatch(ctx, batchReq)
+	endSpan(span, errResp)
+	return resp, errResp
+}
+
+func (s *tracedWalletService) GetWallets(ctx context.Context, req models.GetWalletsRequest) (models.GetWalletsResponse, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "GetWallets")
+	resp, errResp := s.next.GetWallets(ctx, req)
+	endSpan(span, errResp)
+	return resp, errResp
+}
+
+func (s *tracedWalletService) Transfer(ctx context.Context, transferReq models.TransferRequest) *models.ErrorResponse {
+	ctx, span := startSpan(ctx, "Transfer",
+		attribute.String("wallet.from_id", transferReq.FromID.String()),
+		attribute.String("wallet.to_id", transferReq.ToID.String()),
+	)
+	errResp := s.next.Transfer(ctx, transferReq)
+	endSpan(span, errResp)
+	return errResp
+}
+
+func (s *tracedWalletService) GetSpendingLimits(ctx context.Context, id uuid.UUID) (models.SpendingLimits, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "GetSpendingLimits", walletIDAttribute.String(id.String()))
+	limits, errResp := s.next.GetSpendingLimits(ctx, id)
+	endSpan(span, errResp)
+	return limits, errResp
+}
+
+func (s *tracedWalletService) SetSpendingLimits(ctx context.Context, id uuid.UUID, limitsReq models.SpendingLimits) (models.SpendingLimits, *models.ErrorResponse) {
+	ctx, span := startSpan(ctx, "SetSpendingLimits", walletIDAttribute.String(id.String()))
+	limits, errResp := s.next.SetSpendingLimits(ctx, id, limitsReq)
+	endSpan(span, errResp)
+	return limits, errResp
+}
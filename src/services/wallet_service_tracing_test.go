@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"wallet-api/pkg/tracing"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceWalletService(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(tracing.Config{}, exporter)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(nil)
+
+	mockRepo := new(repositories.WalletRepoMock)
+	svc := services.TraceWalletService(services.NewWalletService(repositories.TraceWalletRepo(mockRepo)))
+	walletID := uuid.New()
+	mockRepo.On("FindByID", mock.Anything, walletID).Return(entities.Wallet{ID: walletID, Balance: 1000, Currency: models.Default_currency}, nil)
+	mockRepo.On("WithdrawUpdate", mock.Anything, walletID, int64(5000)).Return(repositories.ErrWalletNotEnoughBalance).Once()
+	mockRepo.On("WithdrawUpdate", mock.Anything, walletID, int64(100)).Return(errors.New("db error")).Once()
+
+	spans := func(req models.ChangeBalanceRequest) tracetest.SpanStubs {
+		exporter.Reset()
+		svc.ChangeWalletBalance(context.Background(), req)
+		assert.NoError(t, provider.ForceFlush(context.Background()))
+		return exporter.GetSpans()
+	}
+	req := models.ChangeBalanceRequest{ID: walletID, Balance: 5000, Currency: models.Default_currency, OperationType: models.Operation_type_withdraw}
+
+	t.Run("refused", func(t *testing.T) {
+		got := spans(req)
+		if !assert.Len(t, got, 3) {
+			return
+		}
+		// spans are exported as they end, the service one is the last
+		find, withdraw, service := got[0], got[1], got[2]
+		assert.Equal(t, "WalletRepo.FindByID", find.Name)
+		assert.Equal(t, "WalletRepo.WithdrawUpdate", withdraw.Name)
+		assert.Equal(t, "WalletService.ChangeWalletBalance", service.Name)
+		assert.Equal(t, service.SpanContext.SpanID(), find.Parent.SpanID())
+		assert.Equal(t, service.SpanContext.SpanID(), withdraw.Parent.SpanID())
+		assert.Equal(t, codes.Error, withdraw.Status.Code)
+		assert.Equal(t, codes.Unset, service.Status.Code, "refused requests do not fail the service span")
+		assert.Contains(t, service.Attributes, attribute.Int("wallet.error.code", http.StatusBadRequest))
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		req := req
+		req.Balance = 100
+		got := spans(req)
+		if !assert.Len(t, got, 3) {
+			return
+		}
+		assert.Equal(t, codes.Error, got[2].Status.Code)
+	})
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"wallet-api/pkg/webhook"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+const webhookSecretBytes = 32
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, createReq models.CreateWebhookRequest) (models.Webhook, *models.ErrorResponse)
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (models.Webhook, *models.ErrorResponse)
+	GetWebhooks(ctx context.Context) (models.GetWebhooksResponse, *models.ErrorResponse)
+	SetWebhookActive(ctx context.Context, id uuid.UUID, active bool) (models.Webhook, *models.ErrorResponse)
+	GetWebhookDeliveries(ctx context.Context, req models.GetWebhookDeliveriesRequest) (models.GetWebhookDeliveriesResponse, *models.ErrorResponse)
+	ReplayWebhookDelivery(ctx context.Context, id uuid.UUID) (models.WebhookDelivery, *models.ErrorResponse)
+}
+
+type webhookService struct {
+	webhookRepo repositories.WebhookRepo
+	checkURL    func(ctx context.Context, rawURL string) error
+}
+
+// NewWebhookService refuses the urls checkURL fails for, see
+// webhook.Config.CheckURL.
+func NewWebhookService(webhookRepo repositories.WebhookRepo, checkURL func(ctx context.Context, rawURL string) error) WebhookService {
+	return &webhookService{webhookRepo: webhookRepo, checkURL: checkURL}
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, createReq models.CreateWebhookRequest) (models.Webhook, *models.ErrorResponse) {
+	if err := s.checkURL(ctx, createReq.URL); err != nil {
+		return models.Webhook{}, webhookURLErrorResponse(err)
+	}
+	secret := createReq.Secret
+	if secret == "" {
+		key := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(key); err != nil {
+			return models.Webhook{}, internalError()
+		}
+		secret = hex.EncodeToString(key)
+	}
+	subscription, err := s.webhookRepo.CreateSubscription(ctx, createReq.URL, createReq.EventTypes, secret)
+	if err != nil {
+		return models.Webhook{}, webhookErrorResponse(err)
+	}
+	webhook, errResp := toWebhookModel(subscription)
+	if errResp != nil {
+		return models.Webhook{}, errResp
+	}
+	webhook.Secret = subscription.Secret
+	return webhook, nil
+}
+
+func (s *webhookService) GetWebhookByID(ctx context.Context, id uuid.UUID) (models.Webhook, *models.ErrorResponse) {
+	subscription, err := s.webhookRepo.FindSubscription(ctx, id)
+	if err != nil {
+		return models.Webhook{}, webhookErrorResponse(err)
+	}
+	return toWebhookModel(subscription)
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context) (models.GetWebhooksResponse, *models.ErrorResponse) {
+	subscriptions, err := s.webhookRepo.FindSubscriptions(ctx)
+	if err != nil {
+		return models.GetWebhooksResponse{}, webhookErrorResponse(err)
+	}
+	resp := models.GetWebhooksResponse{Webhooks: make([]models.Webhook, 0, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		webhook, errResp := toWebhookModel(subscription)
+		if errResp != nil {
+			return models.GetWebhooksResponse{}, errResp
+		}
+		resp.Webhooks = append(resp.Webhooks, webhook)
+	}
+	return resp, nil
+}
+
+// SetWebhookActive pauses or resumes the subscription. Events raised while it
+// is paused are not delivered to it, pending deliveries wait for it.
+func (s *webhookService) SetWebhookActive(ctx context.Context, id uuid.UUID, active bool) (models.Webhook, *models.ErrorResponse) {
+	subscription, err := s.webhookRepo.SetSubscriptionActive(ctx, id, active)
+	if err != nil {
+		return models.Webhook{}, webhookErrorResponse(err)
+	}
+	return toWebhookModel(subscription)
+}
+
+func (s *webhookService) GetWebhookDeliveries(ctx context.Context, req models.GetWebhookDeliveriesRequest) (models.GetWebhookDeliveriesResponse, *models.ErrorResponse) {
+	if _, err := s.webhookRepo.FindSubscription(ctx, req.WebhookID); err != nil {
+		return models.GetWebhookDeliveriesResponse{}, webhookErrorResponse(err)
+	}
+	deliveryEntities, err := s.webhookRepo.FindDeliveries(ctx, req.WebhookID, req.Status, req.Limit)
+	if err != nil {
+		return models.GetWebhookDeliveriesResponse{}, webhookErrorResponse(err)
+	}
+	resp := models.GetWebhookDeliveriesResponse{Deliveries: make([]models.WebhookDelivery, 0, len(deliveryEntities))}
+	if err = copier.Copy(&resp.Deliveries, &deliveryEntities); err != nil {
+		return models.GetWebhookDeliveriesResponse{}, internalError()
+	}
+	return resp, nil
+}
+
+func (s *webhookService) ReplayWebhookDelivery(ctx context.Context, id uuid.UUID) (models.WebhookDelivery, *models.ErrorResponse) {
+	deliveryEntity, err := s.webhookRepo.Replay(ctx, id)
+	if err != nil {
+		return models.WebhookDelivery{}, webhookErrorResponse(err)
+	}
+	var delivery models.WebhookDelivery
+	if err = copier.Copy(&delivery, &deliveryEntity); err != nil {
+		return models.WebhookDelivery{}, internalError()
+	}
+	return delivery, nil
+}
+
+// toWebhookModel never copies the secret, it is returned only on create.
+func toWebhookModel(subscription entities.WebhookSubscription) (models.Webhook, *models.ErrorResponse) {
+	var webhook models.Webhook
+	if err := copier.Copy(&webhook, &subscription); err != nil {
+		return models.Webhook{}, internalError()
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func webhookURLErrorResponse(err error) *models.ErrorResponse {
+	if errors.Is(err, webhook.ErrForbiddenAddress) {
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "url points to a loopback, private or link-local address",
+		}
+	}
+	return &models.ErrorResponse{
+		Code:    http.StatusBadRequest,
+		Message: "url host can not be resolved",
+	}
+}
+
+func webhookErrorResponse(err error) *models.ErrorResponse {
+	switch {
+	case errors.Is(err, repositories.ErrWebhookNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "webhook not found",
+		}
+	case errors.Is(err, repositories.ErrWebhookDeliveryNotFound):
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "webhook delivery not found",
+		}
+	case errors.Is(err, repositories.ErrWebhookDeliveryPending):
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "webhook delivery is already pending",
+		}
+	default:
+		return internalError()
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"wallet-api/src/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) CreateWebhook(ctx context.Context, req models.CreateWebhookRequest) (models.Webhook, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Webhook), nil
+	}
+	return args.Get(0).(models.Webhook), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WebhookServiceMock) GetWebhookByID(ctx context.Context, id uuid.UUID) (models.Webhook, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Webhook), nil
+	}
+	return args.Get(0).(models.Webhook), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WebhookServiceMock) GetWebhooks(ctx context.Context) (models.GetWebhooksResponse, *models.ErrorResponse) {
+	args := m.Called(ctx)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.GetWebhooksResponse), nil
+	}
+	return args.Get(0).(models.GetWebhooksResponse), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WebhookServiceMock) SetWebhookActive(ctx context.Context, id uuid.UUID, active bool) (models.Webhook, *models.ErrorResponse) {
+	args := m.Called(ctx, id, active)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.Webhook), nil
+	}
+	return args.Get(0).(models.Webhook), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WebhookServiceMock) GetWebhookDeliveries(ctx context.Context, req models.GetWebhookDeliveriesRequest) (models.GetWebhookDeliveriesResponse, *models.ErrorResponse) {
+	args := m.Called(ctx, req)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.GetWebhookDeliveriesResponse), nil
+	}
+	return args.Get(0).(models.GetWebhookDeliveriesResponse), args.Get(1).(*models.ErrorResponse)
+}
+
+func (m *WebhookServiceMock) ReplayWebhookDelivery(ctx context.Context, id uuid.UUID) (models.WebhookDelivery, *models.ErrorResponse) {
+	args := m.Called(ctx, id)
+	if args.Get(1) == nil {
+		return args.Get(0).(models.WebhookDelivery), nil
+	}
+	return args.Get(0).(models.WebhookDelivery), args.Get(1).(*models.ErrorResponse)
+}
@@ -0,0 +1,137 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"wallet-api/pkg/webhook"
+	"wallet-api/src/database/entities"
+	"wallet-api/src/database/repositories"
+	"wallet-api/src/models"
+	"wallet-api/src/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func allowURL(context.Context, string) error { return nil }
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	mockRepo := new(repositories.WebhookRepoMock)
+	svc := services.NewWebhookService(mockRepo, allowURL)
+	ctx := context.Background()
+	eventTypes := []string{models.Webhook_event_balance_changed}
+
+	t.Run("given secret", func(t *testing.T) {
+		entity := entities.WebhookSubscription{ID: uuid.New(), URL: "https://example.com/hook", EventTypes: eventTypes, Secret: "s3cret", Active: true}
+		mockRepo.On("CreateSubscription", ctx, entity.URL, eventTypes, "s3cret").Return(entity, nil).Once()
+
+		webhook, errResp := svc.CreateWebhook(ctx, models.CreateWebhookRequest{URL: entity.URL, EventTypes: eventTypes, Secret: "s3cret"})
+		assert.Nil(t, errResp)
+		assert.Equal(t, entity.ID, webhook.ID)
+		assert.Equal(t, "s3cret", webhook.Secret)
+		assert.True(t, webhook.Active)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("generated secret", func(t *testing.T) {
+		var secret string
+		mockRepo.On("CreateSubscription", ctx, "https://example.com/hook", eventTypes, mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { secret = args.String(3) }).
+			Return(entities.WebhookSubscription{Secret: "generated"}, nil).Once()
+
+		webhook, errResp := svc.CreateWebhook(ctx, models.CreateWebhookRequest{URL: "https://example.com/hook", EventTypes: eventTypes})
+		assert.Nil(t, errResp)
+		assert.Len(t, secret, 64)
+		assert.Equal(t, "generated", webhook.Secret)
+	})
+
+	t.Run("forbidden address", func(t *testing.T) {
+		svc := services.NewWebhookService(mockRepo, webhook.Config{}.CheckURL)
+
+		_, errResp := svc.CreateWebhook(ctx, models.CreateWebhookRequest{URL: "http://169.254.169.254/latest", EventTypes: eventTypes})
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		mockRepo.AssertNotCalled(t, "CreateSubscription", ctx, "http://169.254.169.254/latest", eventTypes, mock.Anything)
+	})
+}
+
+func TestWebhookService_GetWebhookByID(t *testing.T) {
+	mockRepo := new(repositories.WebhookRepoMock)
+	svc := services.NewWebhookService(mockRepo, allowURL)
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("secret is hidden", func(t *testing.T) {
+		mockRepo.On("FindSubscription", ctx, id).Return(entities.WebhookSubscription{ID: id, Secret: "s3cret"}, nil).Once()
+
+		webhook, errResp := svc.GetWebhookByID(ctx, id)
+		assert.Nil(t, errResp)
+		assert.Equal(t, id, webhook.ID)
+		assert.Empty(t, webhook.Secret)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.On("FindSubscription", ctx, id).Return(entities.WebhookSubscription{}, repositories.ErrWebhookNotFound).Once()
+
+		_, errResp := svc.GetWebhookByID(ctx, id)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+}
+
+func TestWebhookService_GetWebhookDeliveries(t *testing.T) {
+	mockRepo := new(repositories.WebhookRepoMock)
+	svc := services.NewWebhookService(mockRepo, allowURL)
+	ctx := context.Background()
+	id := uuid.New()
+	dead := models.Webhook_delivery_dead
+
+	t.Run("success", func(t *testing.T) {
+		delivery := entities.WebhookDelivery{ID: uuid.New(), SubscriptionID: id, EventID: 7, Status: entities.Webhook_delivery_dead, Attempts: 10}
+		mockRepo.On("FindSubscription", ctx, id).Return(entities.WebhookSubscription{ID: id}, nil).Once()
+		mockRepo.On("FindDeliveries", ctx, id, &dead, 50).Return([]entities.WebhookDelivery{delivery}, nil).Once()
+
+		resp, errResp := svc.GetWebhookDeliveries(ctx, models.GetWebhookDeliveriesRequest{WebhookID: id, Status: &dead, Limit: 50})
+		assert.Nil(t, errResp)
+		assert.Len(t, resp.Deliveries, 1)
+		assert.Equal(t, delivery.ID, resp.Deliveries[0].ID)
+		assert.Equal(t, models.Webhook_delivery_dead, resp.Deliveries[0].Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("webhook not found", func(t *testing.T) {
+		mockRepo.On("FindSubscription", ctx, id).Return(entities.WebhookSubscription{}, repositories.ErrWebhookNotFound).Once()
+
+		_, errResp := svc.GetWebhookDeliveries(ctx, models.GetWebhookDeliveriesRequest{WebhookID: id, Limit: 50})
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+}
+
+func TestWebhookService_ReplayWebhookDelivery(t *testing.T) {
+	mockRepo := new(repositories.WebhookRepoMock)
+	svc := services.NewWebhookService(mockRepo, allowURL)
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("Replay", ctx, id).Return(entities.WebhookDelivery{ID: id, Status: entities.Webhook_delivery_pending}, nil).Once()
+
+		delivery, errResp := svc.ReplayWebhookDelivery(ctx, id)
+		assert.Nil(t, errResp)
+		assert.Equal(t, models.Webhook_delivery_pending, delivery.Status)
+	})
+
+	t.Run("already pending", func(t *testing.T) {
+		mockRepo.On("Replay", ctx, id).Return(entities.WebhookDelivery{}, repositories.ErrWebhookDeliveryPending).Once()
+
+		_, errResp := svc.ReplayWebhookDelivery(ctx, id)
+		assert.Equal(t, http.StatusConflict, errResp.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.On("Replay", ctx, id).Return(entities.WebhookDelivery{}, repositories.ErrWebhookDeliveryNotFound).Once()
+
+		_, errResp := svc.ReplayWebhookDelivery(ctx, id)
+		assert.Equal(t, http.StatusNotFound, errResp.Code)
+	})
+}